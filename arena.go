@@ -0,0 +1,142 @@
+package pools
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// arenaBlockSize is the size of the blocks an Arena carves byte slices from.
+// Larger requests get a slice of their own from GetBytes.
+const arenaBlockSize = 64 << 10
+
+type arenaBlock [arenaBlockSize]byte
+
+var arenaBlockPool = sync.Pool{
+	New: func() interface{} {
+		return new(arenaBlock)
+	},
+}
+
+var arenaPool = sync.Pool{
+	New: func() interface{} {
+		return new(Arena)
+	},
+}
+
+// Arena hands out memory from large pooled blocks and frees all of it at once
+// when it's released, for request-scoped data such as parse trees, where
+// pooling each object separately is too fine-grained. Byte slices come from
+// Alloc and typed values from ArenaNew. An Arena is not safe for concurrent
+// use.
+type Arena struct {
+	blocks []*arenaBlock
+	off    int      // offset of the free space in the last block.
+	large  [][]byte // from GetBytes.
+	slabs  map[reflect.Type]arenaSlabs
+}
+
+func GetArena() *Arena {
+	return arenaPool.Get().(*Arena)
+}
+
+// PutArena releases a and returns it to the pool. Nothing allocated from a
+// may be used afterward.
+func PutArena(a *Arena) {
+	a.Release()
+	arenaPool.Put(a)
+}
+
+// Alloc returns a zeroed slice of n bytes. Its capacity is n, so appending to
+// it never overwrites other allocations.
+func (a *Arena) Alloc(n int) []byte {
+	if n > arenaBlockSize/4 {
+		p := GetBytes(n)
+		clear(p)
+		a.large = append(a.large, p)
+		return p[:n:n]
+	}
+	if len(a.blocks) == 0 || a.off+n > arenaBlockSize {
+		a.blocks = append(a.blocks, arenaBlockPool.Get().(*arenaBlock))
+		a.off = 0
+	}
+	b := a.blocks[len(a.blocks)-1]
+	p := b[a.off : a.off+n : a.off+n]
+	a.off += n
+	return p
+}
+
+// Release frees everything allocated from a, returning its blocks to their
+// pools. a can be reused afterward.
+func (a *Arena) Release() {
+	for i, b := range a.blocks {
+		// Blocks are handed out zeroed, so Alloc needn't zero them.
+		clear(b[:])
+		arenaBlockPool.Put(b)
+		a.blocks[i] = nil
+	}
+	a.blocks = a.blocks[:0]
+	a.off = 0
+	for i, p := range a.large {
+		PutBytes(p)
+		a.large[i] = nil
+	}
+	a.large = a.large[:0]
+	for t, s := range a.slabs {
+		s.release()
+		delete(a.slabs, t)
+	}
+}
+
+// arenaSlabs holds an Arena's slabs of one type.
+type arenaSlabs interface {
+	release()
+}
+
+// slabPools holds a *sync.Pool of slabs for each type passed to ArenaNew.
+var slabPools sync.Map // map[reflect.Type]*sync.Pool
+
+// typedSlabs are the slabs of T in use by an Arena. The last one has free
+// space.
+type typedSlabs[T any] struct {
+	pool  *sync.Pool
+	slabs []*[]T
+	n     int // number of values used in the last slab.
+}
+
+func (s *typedSlabs[T]) release() {
+	for _, p := range s.slabs {
+		clear(*p)
+		s.pool.Put(p)
+	}
+}
+
+// ArenaNew returns a pointer to a new zero value of type T allocated from a.
+// Values are carved from pooled slabs of T rather than from a's byte blocks,
+// so T may contain pointers.
+func ArenaNew[T any](a *Arena) *T {
+	t := reflect.TypeFor[T]()
+	s, ok := a.slabs[t].(*typedSlabs[T])
+	if !ok {
+		p, _ := slabPools.LoadOrStore(t, new(sync.Pool))
+		s = &typedSlabs[T]{pool: p.(*sync.Pool)}
+		if a.slabs == nil {
+			a.slabs = make(map[reflect.Type]arenaSlabs)
+		}
+		a.slabs[t] = s
+	}
+	if len(s.slabs) == 0 || s.n == len(*s.slabs[len(s.slabs)-1]) {
+		slab, _ := s.pool.Get().(*[]T)
+		if slab == nil {
+			var zero T
+			n := arenaBlockSize / max(int(unsafe.Sizeof(zero)), 1)
+			p := make([]T, min(max(n, 1), 1024))
+			slab = &p
+		}
+		s.slabs = append(s.slabs, slab)
+		s.n = 0
+	}
+	v := &(*s.slabs[len(s.slabs)-1])[s.n]
+	s.n++
+	return v
+}
@@ -0,0 +1,39 @@
+package pools
+
+import "testing"
+
+type node struct {
+	name     string
+	children []*node
+}
+
+func TestArena(t *testing.T) {
+	a := GetArena()
+	p := a.Alloc(10)
+	copy(p, "0123456789")
+	q := a.Alloc(5)
+	expect(t, 10, cap(p))
+	expect(t, "\x00\x00\x00\x00\x00", string(q))
+	expect(t, "0123456789", string(p))
+	big := a.Alloc(arenaBlockSize)
+	expect(t, arenaBlockSize, len(big))
+
+	root := ArenaNew[node](a)
+	for i := 0; i < 3000; i++ {
+		root.children = append(root.children, ArenaNew[node](a))
+	}
+	root.children[2999].name = "last"
+	expect(t, "last", root.children[2999].name)
+	PutArena(a)
+
+	a = GetArena()
+	defer PutArena(a)
+	n := ArenaNew[node](a)
+	expect(t, "", n.name)
+	expect(t, 0, len(n.children))
+	for _, c := range a.Alloc(arenaBlockSize / 4) {
+		if c != 0 {
+			t.Fatal("Alloc returned dirty memory")
+		}
+	}
+}
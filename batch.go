@@ -0,0 +1,65 @@
+package pools
+
+// GetBuffers returns n Buffers from the pool, for fan-out work that needs
+// many at once. The bookkeeping shared by the Buffers, such as the
+// SetMaxOutstanding check, is done once for the batch. Return them with
+// PutBuffers or one at a time with PutBuffer.
+func GetBuffers(n int) []*Buffer {
+	checkOutN(int64(n))
+	bufferStats.gets.Add(uint64(n))
+	bs := make([]*Buffer, n)
+	for i := range bs {
+		getHooks.call(KindBuffer)
+		b := getIdle()
+		trackGet(b)
+		traceEvent("get", KindBuffer, b.Cap())
+		bs[i] = b
+	}
+	return bs
+}
+
+// PutBuffers returns every Buffer in bs to the pool, like calling PutBuffer
+// on each, and clears bs.
+func PutBuffers(bs []*Buffer) {
+	var size int64
+	for _, b := range bs {
+		checkUnsafe(b)
+		putHooks.call(KindBuffer)
+		trackPut(b)
+		traceEvent("put", KindBuffer, b.Cap())
+		size = max(size, int64(b.Cap()-b.Available()))
+	}
+	checkInN(int64(len(bs)), size)
+	for _, b := range bs {
+		release(b, false)
+	}
+	clear(bs)
+}
+
+// GetSlices returns n slices of length size from the slice pool, like
+// calling GetBytes n times. For the small size classes the slab's lock is
+// taken once for the batch. Return them with PutSlices or one at a time
+// with PutBytes.
+func GetSlices(n, size int) [][]byte {
+	ps := make([][]byte, n)
+	if c := bytesClass(size); c >= 0 && c < numSlabClasses && !noPooling.Load() {
+		slabs[c].getN(c, ps)
+		for i := range ps {
+			ps[i] = ps[i][:size]
+		}
+		return ps
+	}
+	for i := range ps {
+		ps[i] = GetBytes(size)
+	}
+	return ps
+}
+
+// PutSlices returns every slice in ps to the slice pool, like calling
+// PutBytes on each, and clears ps.
+func PutSlices(ps [][]byte) {
+	for _, p := range ps {
+		PutBytes(p)
+	}
+	clear(ps)
+}
@@ -0,0 +1,37 @@
+package pools
+
+import "testing"
+
+func TestBatch(t *testing.T) {
+	var before, after Stats
+	ReadStats(&before)
+	bs := GetBuffers(3)
+	expect(t, 3, len(bs))
+	bs[0].WriteString("hello")
+	PutBuffers(bs)
+	ReadStats(&after)
+	expect(t, before.Gets+3, after.Gets)
+	expect(t, before.Puts+3, after.Puts)
+	expect(t, (*Buffer)(nil), bs[0])
+
+	for _, size := range []int{100, 1 << 16, 1 << 21} {
+		ps := GetSlices(4, size)
+		for _, p := range ps {
+			expect(t, size, len(p))
+		}
+		PutSlices(ps)
+	}
+}
+
+func TestBatchOutstanding(t *testing.T) {
+	defer SetMaxOutstanding(0)
+	base := bufferStats.outstanding.Load()
+	SetMaxOutstanding(int(base) + 2)
+	defer func() {
+		if recover() != ErrOutstanding {
+			t.Fatal("want ErrOutstanding")
+		}
+		expect(t, base, bufferStats.outstanding.Load())
+	}()
+	GetBuffers(3)
+}
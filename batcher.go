@@ -0,0 +1,137 @@
+package pools
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Batcher accumulates items and passes them to a callback in batches, when
+// a batch is full or has waited long enough, for example to turn single
+// rows into bulk INSERTs or log lines into shipments. The storage behind
+// each batch is recycled once the callback returns, so a steady stream of
+// items doesn't allocate.
+//
+// Batches are passed to the callback one at a time, in the order they were
+// filled. A Batcher is safe for concurrent use.
+type Batcher[T any] struct {
+	size     int
+	interval time.Duration
+	flush    func([]T)
+
+	mu      sync.Mutex
+	items   []T
+	free    [][]T       // recycled batches.
+	timer   *time.Timer // fires interval after started.
+	started time.Time   // when the current batch got its first item.
+	closed  bool
+
+	flushMu sync.Mutex // held while calling flush.
+}
+
+// NewBatcher returns a Batcher that calls flush with up to size items at a
+// time: when size items have been added, or, if interval > 0, when interval
+// has passed since the first item of the batch was added. flush must not
+// retain the slice. NewBatcher panics if size <= 0.
+func NewBatcher[T any](size int, interval time.Duration, flush func([]T)) *Batcher[T] {
+	if size <= 0 {
+		panic("pools: non-positive size for NewBatcher")
+	}
+	return &Batcher[T]{size: size, interval: interval, flush: flush}
+}
+
+// Add adds v to the current batch, calling the callback on the calling
+// goroutine if that fills it.
+func (b *Batcher[T]) Add(v T) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errors.New("pools: Add called after Close")
+	}
+	if b.items == nil {
+		b.start()
+	}
+	b.items = append(b.items, v)
+	if len(b.items) < b.size {
+		b.mu.Unlock()
+		return nil
+	}
+	b.run() // unlocks b.mu.
+	return nil
+}
+
+// start begins a new batch. b.mu must be held.
+func (b *Batcher[T]) start() {
+	if n := len(b.free); n > 0 {
+		b.items = b.free[n-1]
+		b.free[n-1] = nil
+		b.free = b.free[:n-1]
+	} else {
+		b.items = make([]T, 0, b.size)
+	}
+	if b.interval <= 0 {
+		return
+	}
+	b.started = time.Now()
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.interval, b.expire)
+	} else {
+		b.timer.Reset(b.interval)
+	}
+}
+
+// expire flushes the current batch if it has waited for interval. The timer
+// may fire late, after its batch was flushed and another started, so that's
+// checked rather than assumed.
+func (b *Batcher[T]) expire() {
+	b.mu.Lock()
+	if b.items == nil {
+		b.mu.Unlock()
+		return
+	}
+	if d := b.interval - time.Since(b.started); d > 0 {
+		b.timer.Reset(d)
+		b.mu.Unlock()
+		return
+	}
+	b.run()
+}
+
+// run passes the current batch, if any, to the callback and recycles it.
+// b.mu must be held; run unlocks it. Taking flushMu before releasing b.mu
+// keeps batches in order.
+func (b *Batcher[T]) run() {
+	items := b.items
+	b.items = nil
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	if items == nil {
+		b.mu.Unlock()
+		return
+	}
+	b.flushMu.Lock()
+	b.mu.Unlock()
+	b.flush(items)
+	b.flushMu.Unlock()
+
+	clear(items)
+	b.mu.Lock()
+	b.free = append(b.free, items[:0])
+	b.mu.Unlock()
+}
+
+// Flush passes the current batch, if it has any items, to the callback
+// without waiting for it to fill, and returns once the callback has.
+func (b *Batcher[T]) Flush() {
+	b.mu.Lock()
+	b.run()
+}
+
+// Close flushes the current batch. Add returns an error after Close. It's
+// safe to call Close more than once.
+func (b *Batcher[T]) Close() {
+	b.mu.Lock()
+	b.closed = true
+	b.run()
+}
@@ -0,0 +1,53 @@
+package pools
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcher(t *testing.T) {
+	var got []string
+	b := NewBatcher(3, 0, func(items []string) {
+		got = append(got, strings.Join(items, ""))
+	})
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		expect(t, nil, b.Add(s))
+	}
+	expect(t, "abc", strings.Join(got, ","))
+	b.Flush()
+	b.Flush()
+	expect(t, "abc,de", strings.Join(got, ","))
+
+	if n := testing.AllocsPerRun(100, func() { b.Add("x") }); n != 0 {
+		t.Fatalf("want no allocations, got %v", n)
+	}
+
+	b.Close()
+	if b.Add("z") == nil {
+		t.Fatal("want an error from Add after Close")
+	}
+}
+
+func TestBatcherInterval(t *testing.T) {
+	var mu sync.Mutex
+	var got []int
+	done := make(chan struct{}, 1)
+	b := NewBatcher(100, time.Millisecond, func(items []int) {
+		mu.Lock()
+		got = append(got, len(items))
+		mu.Unlock()
+		done <- struct{}{}
+	})
+	for i := 1; i <= 2; i++ {
+		b.Add(1)
+		b.Add(2)
+		<-done
+		mu.Lock()
+		expect(t, i, len(got))
+		expect(t, 2, got[i-1])
+		mu.Unlock()
+	}
+	b.Close()
+}
@@ -0,0 +1,22 @@
+package pools
+
+import "encoding"
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns a copy of
+// w's unread contents.
+func (w *Buffer) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), w.Bytes()...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing w's
+// contents with a copy of data.
+func (w *Buffer) UnmarshalBinary(data []byte) error {
+	w.Reset()
+	w.Write(data)
+	return w.Err()
+}
+
+var (
+	_ encoding.BinaryMarshaler   = (*Buffer)(nil)
+	_ encoding.BinaryUnmarshaler = (*Buffer)(nil)
+)
@@ -0,0 +1,146 @@
+package pools
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structFields maps a struct type to its columns.
+var structFields sync.Map // map[reflect.Type]*columns
+
+type columns struct {
+	names []string
+	index map[string][]int
+}
+
+// fieldsOf returns the columns of struct type t. A field's column is the name
+// in its `db` tag or, if it has none, its name in lower case. Fields tagged
+// `db:"-"` and unexported fields are skipped. Fields of embedded structs are
+// included as if they were declared in t.
+func fieldsOf(t reflect.Type) *columns {
+	if c, ok := structFields.Load(t); ok {
+		return c.(*columns)
+	}
+	c := &columns{index: make(map[string][]int)}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || (f.Anonymous && f.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("db"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = strings.ToLower(f.Name)
+		}
+		if _, ok := c.index[name]; ok {
+			continue
+		}
+		c.names = append(c.names, name)
+		c.index[name] = f.Index
+	}
+	v, _ := structFields.LoadOrStore(t, c)
+	return v.(*columns)
+}
+
+// rowType returns the struct type of the elements of the slice rows.
+func rowType(rows interface{}) (reflect.Value, reflect.Type, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return v, nil, fmt.Errorf("pools: BindRows requires a slice, got %T", rows)
+	}
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return v, nil, fmt.Errorf("pools: BindRows requires a slice of structs, got %T", rows)
+	}
+	return v, t, nil
+}
+
+// Columns returns the column names BindRows uses when called without any,
+// in field order. rows must be a slice of structs or struct pointers.
+func Columns(rows interface{}) ([]string, error) {
+	_, t, err := rowType(rows)
+	if err != nil {
+		return nil, err
+	}
+	return fieldsOf(t).names, nil
+}
+
+// BindRows returns a pooled Fragment containing one group of placeholders
+// per element of rows, which must be a slice of structs or struct pointers,
+// bound to the struct fields named by cols. Columns are matched using the
+// fields' `db` tags as described by Columns; if cols is empty, every column
+// is bound in field order.
+//
+//	cols := []string{"id", "name"}
+//	rows, err := pools.BindRows(users, cols...)
+//	if err != nil {
+//		return err
+//	}
+//	defer pools.PutFragment(rows)
+//
+//	stmt := pools.GetFragment()
+//	defer pools.PutFragment(stmt)
+//	stmt.Append("INSERT INTO users (id, name) VALUES")
+//	stmt.AppendFragment(rows)
+//
+// An error is returned if rows is empty, contains a nil pointer, or a column
+// doesn't exist.
+func BindRows(rows interface{}, cols ...string) (*Fragment, error) {
+	v, t, err := rowType(rows)
+	if err != nil {
+		return nil, err
+	}
+	if v.Len() == 0 {
+		return nil, errors.New("pools: BindRows called without rows")
+	}
+
+	c := fieldsOf(t)
+	if len(cols) == 0 {
+		cols = c.names
+	}
+	index := make([][]int, len(cols))
+	for i, name := range cols {
+		x, ok := c.index[name]
+		if !ok {
+			return nil, fmt.Errorf("pools: %s has no column %q", t, name)
+		}
+		index[i] = x
+	}
+
+	f := GetFragment()
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		if row.Kind() == reflect.Pointer {
+			if row.IsNil() {
+				PutFragment(f)
+				return nil, fmt.Errorf("pools: row %d is nil", i)
+			}
+			row = row.Elem()
+		}
+		if i > 0 {
+			f.sql.WriteByte(',')
+		}
+		f.sql.WriteString(" (")
+		for j, x := range index {
+			fv, err := row.FieldByIndexErr(x)
+			if err != nil {
+				PutFragment(f)
+				return nil, fmt.Errorf("pools: row %d: %w", i, err)
+			}
+			if j > 0 {
+				f.sql.WriteString(", ")
+			}
+			f.marks = append(f.marks, f.sql.Len())
+			f.args = append(f.args, fv.Interface())
+		}
+		f.sql.WriteByte(')')
+	}
+	return f, nil
+}
@@ -0,0 +1,59 @@
+package pools
+
+import (
+	"reflect"
+	"testing"
+)
+
+type bindBase struct {
+	ID int `db:"id"`
+}
+
+type bindUser struct {
+	bindBase
+	Name    string `db:"name"`
+	Email   string
+	Ignored string `db:"-"`
+	secret  string
+}
+
+func TestBindRows(t *testing.T) {
+	users := []*bindUser{
+		{bindBase{1}, "a", "a@x", "", ""},
+		{bindBase{2}, "b", "b@x", "", ""},
+	}
+
+	cols, err := Columns(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"id", "name", "email"}; !reflect.DeepEqual(want, cols) {
+		t.Fatalf("want %q, got %q", want, cols)
+	}
+
+	f, err := BindRows(users, "name", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer PutFragment(f)
+
+	b := GetBuffer()
+	defer PutBuffer(b)
+	args := f.Build(b)
+	expect(t, " ($1, $2), ($3, $4)", b.String())
+	if want := []interface{}{"a", 1, "b", 2}; !reflect.DeepEqual(want, args) {
+		t.Fatalf("want %v, got %v", want, args)
+	}
+}
+
+func TestBindRowsErrors(t *testing.T) {
+	if _, err := BindRows([]bindUser{{}}, "nope"); err == nil {
+		t.Fatal("expected an error for an unknown column")
+	}
+	if _, err := BindRows([]*bindUser{nil}); err == nil {
+		t.Fatal("expected an error for a nil row")
+	}
+	if _, err := BindRows([]int{1}); err == nil {
+		t.Fatal("expected an error for a non-struct slice")
+	}
+}
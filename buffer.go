@@ -2,22 +2,67 @@ package pools
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
 	"runtime"
 	"strconv"
-	"sync"
 	"sync/atomic"
+	"time"
+	"unsafe"
+)
 
-	"github.com/sermodigital/errors"
+// Errors returned by WriteGroups, WriteInterval, and WriteIntervalStep for
+// invalid arguments. The returned errors wrap these, adding the arguments, so
+// test for them with errors.Is.
+var (
+	ErrInvalidOffset   = errors.New("pools: negative offset")
+	ErrZeroGroups      = errors.New("pools: zero groups")
+	ErrInvalidInterval = errors.New("pools: invalid interval")
 )
 
-var bufferPool = sync.Pool{
-	New: func() interface{} {
-		return new(Buffer)
-	},
-}
+var bufferPool = newDrainPool(func() interface{} {
+	bufferStats.news.Add(1)
+	return newBuffer()
+})
 
 func GetBuffer() *Buffer {
-	return bufferPool.Get().(*Buffer)
+	checkOut()
+	b := takeBuffer()
+	trackGet(b)
+	traceEvent("get", KindBuffer, b.Cap())
+	return b
+}
+
+// TryGetBuffer is like GetBuffer but returns ErrOutstanding instead of
+// panicking if the limit set by SetMaxOutstanding has been reached.
+func TryGetBuffer() (*Buffer, error) {
+	if err := tryCheckOutN(1); err != nil {
+		return nil, err
+	}
+	b := takeBuffer()
+	trackGet(b)
+	traceEvent("get", KindBuffer, b.Cap())
+	return b, nil
+}
+
+// takeBuffer returns an idle Buffer, or a new one, that has been checked
+// out.
+func takeBuffer() *Buffer {
+	bufferStats.gets.Add(1)
+	getHooks.call(KindBuffer)
+	return getIdle()
+}
+
+// GetBufferSize is like GetBuffer but the returned Buffer can hold at least n
+// bytes without reallocating.
+func GetBufferSize(n int) *Buffer {
+	b := GetBuffer()
+	if b.Cap() < n {
+		b.Grow(n)
+	}
+	return b
 }
 
 // UnsafeBytes returns a slice of bytes that will automatically add the Buffer
@@ -85,56 +130,224 @@ func (b *Buffer) UnsafeBytes() []byte {
 	return buf
 }
 
+// UnsafeString is like UnsafeBytes but returns the Buffer's contents as a
+// string. The same rules apply: do not call pools.PutBuffer on the Buffer
+// afterward.
+func (b *Buffer) UnsafeString() string {
+	buf := b.UnsafeBytes()
+	if len(buf) == 0 {
+		return ""
+	}
+	return unsafe.String(&buf[0], len(buf))
+}
+
+// AppendTo appends the unread portion of the Buffer to dst and returns the
+// extended slice, like the standard library's Append functions. Unlike Bytes
+// the result doesn't alias the Buffer, so it's safe to keep after the Buffer
+// is put back into the pool.
+func (b *Buffer) AppendTo(dst []byte) []byte {
+	return append(dst, b.Bytes()...)
+}
+
 func PutBuffer(b *Buffer) {
+	putBuffer(b, false)
+}
+
+// PutBufferZeroed is like PutBuffer but first overwrites all of b's memory
+// with zeros. Use it for Buffers that held tokens, passwords, or other
+// sensitive data, so the data can't leak into later users of the Buffer
+// through aliasing bugs.
+func PutBufferZeroed(b *Buffer) {
+	putBuffer(b, true)
+}
+
+func putBuffer(b *Buffer, zero bool) {
+	checkUnsafe(b)
+	putHooks.call(KindBuffer)
+	trackPut(b)
+	traceEvent("put", KindBuffer, b.Cap())
+	checkIn(b)
+	release(b, zero)
+}
+
+// release recycles b and returns it to the pool, or drops it, once it has
+// been checked in.
+func release(b *Buffer, zero bool) {
+	if b.epoch != bufferEpoch.Load() {
+		evictBuffer(b, EvictInvalidated)
+		bufferStats.discards.Add(1)
+		return
+	}
+	keep := retain(b)
+	if !keep {
+		evictBuffer(b, EvictOversize)
+	}
+	recycle(b, zero)
+	if !keep || noPooling.Load() {
+		bufferStats.discards.Add(1)
+		return
+	}
+	markIdle(b)
+	raceRecycle(unsafe.Pointer(b), unsafe.Sizeof(*b), b.AvailableBuffer(), func() {
+		bufferPool.Put(b)
+	})
+}
+
+// checkUnsafe panics if b can't be returned to a pool.
+func checkUnsafe(b *Buffer) {
 	// If everything else holds true b.unsafe will be zero. Anything else is
 	// invalid.
 	if atomic.LoadUint32(&b.unsafe) != 0 {
 		panic("pools: PutBuffer called after UnsafeBytes without finalizer running")
 	}
+}
+
+// recycle resets b so it can be reused, optionally zeroing its memory.
+func recycle(b *Buffer, zero bool) {
 	b.Reset()
-	bufferPool.Put(b)
+	if zero {
+		p := b.AvailableBuffer()
+		clear(p[:cap(p)])
+	}
+	poison(b.AvailableBuffer())
+	b.dialect = Postgres
+	b.literals = nil
+	b.limit = 0
+	b.rawJSON = false
 }
 
 type Buffer struct {
-	unsafe uint32 // 1 if UnsafeBytes was called.
+	unsafe  uint32  // 1 if UnsafeBytes was called.
+	dialect Dialect // placeholder syntax used by the group writers.
+
+	literals []interface{} // see SetLiterals.
+
+	limit    int    // see SetLimit.
+	exceeded bool   // a write was rejected because of limit.
+	rejects  uint32 // writes rejected because of limit; never reset.
+	frozen   bool   // see Freeze.
+	rawJSON  bool   // see SetRawJSON.
+
+	created   time.Time // when the Buffer was allocated.
+	idleSince time.Time // when the Buffer was last returned to the pool.
+	next      *Buffer   // the next idle Buffer; see NewIntrusivePool.
+	epoch     uint32    // the epoch the Buffer was allocated in; see Invalidate.
+	class     int       // the Pool class the Buffer was allocated for.
 	bytes.Buffer
 }
 
-// WriteInt64 is a wrapper that writes i to w.
+// SetDialect sets the placeholder syntax used by WriteGroups and
+// WriteInterval. Buffers start out, and are returned to the pool, with the
+// Postgres dialect.
+func (w *Buffer) SetDialect(d Dialect) {
+	w.dialect = d
+}
+
+// Dialect returns the Buffer's placeholder syntax.
+func (w *Buffer) Dialect() Dialect {
+	return w.dialect
+}
+
+// maxIntWidth is the longest base 10 int64, including its sign.
+const maxIntWidth = 20
+
+// WriteInt64 is a wrapper that writes i to w. Small non-negative values are
+// copied from a table; others are formatted directly into w's free capacity,
+// so it doesn't allocate unless w has to grow.
 func (w *Buffer) WriteInt64(i int64) {
-	w.WriteString(strconv.FormatInt(i, 10))
+	if uint64(i) < numSmallInts {
+		w.WriteString(smallInt(int(i)))
+		return
+	}
+	if w.Available() < maxIntWidth {
+		w.Grow(maxIntWidth)
+	}
+	w.Write(strconv.AppendInt(w.AvailableBuffer(), i, 10))
 }
 
-// WriteInt is a wrapper that writes i to w.
+// WriteInt is a wrapper that writes i to w. See WriteInt64.
 func (w *Buffer) WriteInt(i int) {
-	w.WriteString(strconv.Itoa(i))
+	w.WriteInt64(int64(i))
 }
 
-func (w *Buffer) grow(start, end, num int) {
-	width := totalWidth(end-start+1, 3) // 3: '$, '
-	// +2: "()"
-	// -2: last interval doesn't have a trailing ', '
-	x := int((width+2)*num - 2)
+// grow reserves room for groups groups of groupLen placeholders, starting at
+// offset and each preceded by prefix, in w's Dialect.
+func (w *Buffer) grow(offset, groupLen, groups int, prefix ...int) {
+	n := int64(EstimateGroupsSize(offset, groupLen, groups, w.dialect))
+	if len(prefix) > 0 {
+		var width int64
+		for _, v := range prefix {
+			width += int64(len(w.dialect.prefix()) + 2) // 2: ', '
+			if w.dialect.numbered() {
+				width += int64(prefixWidth(v))
+			}
+		}
+		n += width * int64(groups)
+	}
+	w.growN(n)
+}
 
-	const intSize = (32 << (^uint(0) >> 63)) - 1
+// growInterval reserves room for num copies of the interval [start, end] in
+// w's Dialect.
+func (w *Buffer) growInterval(start, end, num int) {
+	w.growN(intervalSize(start, end, num, w.dialect))
+}
 
-	// x > 0 ? x : 0.
-	w.Grow(x & ^(x >> intSize))
+// intervalSize returns the number of bytes WriteInterval writes in d.
+func intervalSize(start, end, num int, d Dialect) int64 {
+	n := int64(EstimateGroupsSize(start, end-start+1, 1, d))
+	// +1: ',' between intervals
+	// -1: last interval isn't followed by ','
+	return (n+1)*int64(num) - 1
+}
+
+// growN grows w by n bytes if n is positive and fits in an int. It's
+// computed in 64 bits so it can't overflow on 32-bit platforms.
+func (w *Buffer) growN(n int64) {
+	if n > 0 && n <= math.MaxInt && w.Available() < int(n) {
+		w.Grow(int(n))
+	}
 }
 
 // WriteGroups writes the interval [offset, offset+groupLen) to w N times.
-// Each number is prefixed with '$' and suffixed with ', '. The final value in
-// an interval and final interval in a set are not suffixed with ', '. The
-// intervals are wrapped in parenthases. An error is only returned if the
-// arguments are invalid. Arguments are invalid if offset < 0 or groups == 0.
+// Each number is prefixed with the Dialect's placeholder (e.g., '$') and
+// suffixed with ', '. The final value in an interval and final interval in
+// a set are not suffixed with ', '. The intervals are wrapped in
+// parentheses. An error is returned if the arguments are invalid, that is
+// if offset < 0 or groups == 0, or, with nothing written, if the output
+// would exceed w's limit (see SetLimit).
 //
-// 	WriteInterval(0, 4, 2) // ($0, $1, $2, $3, $4), ($5, $6, $7, $8, $9)
+// 	WriteGroups(0, 5, 2) // ($0, $1, $2, $3, $4), ($5, $6, $7, $8, $9)
 //
-func (w *Buffer) WriteGroups(offset, groupLen, groups int, prefix ...int) error {
-	if offset < 0 || groups == 0 {
-		return errors.New("invalid arguments to WriteGroups")
+func (w *Buffer) WriteGroups(offset, groupLen, groups int, prefix ...int) (err error) {
+	switch {
+	case offset < 0:
+		return fmt.Errorf("%w: WriteGroups offset %d", ErrInvalidOffset, offset)
+	case groups == 0:
+		return fmt.Errorf("%w: WriteGroups(%d, %d, 0)", ErrZeroGroups, offset, groupLen)
+	}
+	start, rejects := w.Len(), w.rejects
+	if w.literals != nil {
+		if err := w.writeLiteralGroups(offset, groupLen, groups, prefix); err != nil {
+			return err
+		}
+		return w.limited(start, rejects)
+	}
+	if verifying.Load() {
+		defer func(offset, groups int) {
+			if err == nil {
+				w.verifyGroups(start, offset, groupLen, groups, prefix)
+			}
+		}(offset, groups)
 	}
-	w.grow(0, groupLen, groups)
+
+	// Prefixed groups are rare enough that they're not worth caching.
+	k := shape{offset: offset, n: groupLen, num: groups, dialect: w.dialect}
+	if len(prefix) == 0 && w.writeShape(k) {
+		return w.limited(start, rejects)
+	}
+
+	w.grow(offset, groupLen, groups, prefix...)
 	offset += w.writeGroup(prefix, offset, groupLen)
 
 	// Assuming we have more to write...
@@ -142,63 +355,204 @@ func (w *Buffer) WriteGroups(offset, groupLen, groups int, prefix ...int) error
 		w.WriteByte(',')
 		offset += w.writeGroup(prefix, offset, groupLen)
 	}
+
+	if err := w.limited(start, rejects); err != nil {
+		return err
+	}
+	if len(prefix) == 0 {
+		storeShape(k, w.Bytes()[start:])
+	}
 	return nil
 }
 
+// MustWriteGroups is like WriteGroups but panics if the arguments are
+// invalid. It's meant for arguments that are constants, where the error
+// can't happen.
+func (w *Buffer) MustWriteGroups(offset, groupLen, groups int, prefix ...int) {
+	if err := w.WriteGroups(offset, groupLen, groups, prefix...); err != nil {
+		panic(err)
+	}
+}
+
 func (w *Buffer) writeGroup(prefix []int, offset, groupLen int) int {
-	w.WriteString(" ($")
+	d := w.dialect
+
+	// Reserve enough room for the whole group so the digits can be written
+	// straight into w's free capacity.
+	width := len(d.prefix()) + 2 // 2: ', '
+	if d.numbered() {
+		width += digits(uint(offset + groupLen))
+	}
+	n := 3 + width*groupLen + (width+maxIntWidth)*len(prefix) // 3: " ()"
+	if w.Available() < n {
+		w.Grow(n)
+	}
+
+	b := append(w.AvailableBuffer(), " ("...)
 	for _, v := range prefix {
-		w.WriteInt(v)
-		w.WriteString(", $")
+		b = d.appendPlaceholder(b, v)
+		b = append(b, ", "...)
 	}
-	w.WriteInt(offset)
-	for i := 1; i < groupLen; i, offset = i+1, offset+1 {
-		w.WriteString(", $")
-		w.WriteInt(offset + 1)
+	if !d.numbered() {
+		b = d.appendPlaceholder(b, offset)
+		for i := 1; i < groupLen; i++ {
+			b = append(b, ", "...)
+			b = d.appendPlaceholder(b, offset+i)
+		}
+	} else {
+		p := d.prefix()
+		var dec decimal
+		dec.set(uint(offset))
+		b = append(b, p...)
+		b = append(b, dec.bytes()...)
+		for i := 1; i < groupLen; i++ {
+			dec.inc()
+			b = append(b, ", "...)
+			b = append(b, p...)
+			b = append(b, dec.bytes()...)
+		}
 	}
-	w.WriteByte(')')
+	b = append(b, ')')
+	w.Write(b)
 	return groupLen
 }
 
 // WriteInterval writes the interval [start, end] to w N times. Each number is
-// prefixed with '$' and suffixed with ', '. The final value in an interval
-// and final interval in a set are not suffixed with ', '. The intervals are
-// wrapped in parenthases. An error is only returned if the arguments are
-// invalid. Arguments are invalid if start < 0, start >= end, or num == 0.
+// prefixed with the Dialect's placeholder (e.g., '$') and suffixed with ', '.
+// The final value in an interval and final interval in a set are not suffixed
+// with ', '. The intervals are wrapped in parentheses. An error is
+// returned if the arguments are invalid, that is if start < 0, start >= end,
+// or num == 0, or, with nothing written, if the output would exceed w's
+// limit (see SetLimit).
 //
-// 	WriteInterval(0, 4, 2) // (0, 1, 2, 3, 4), (0, 1, 2, 3, 4)
+// 	WriteInterval(0, 4, 2) // ($0, $1, $2, $3, $4), ($0, $1, $2, $3, $4)
 //
-func (w *Buffer) WriteInterval(start, end, num int) error {
-	if start < 0 || start >= end || num == 0 {
-		return errors.New("invalid arguments to WriteInterval")
+func (w *Buffer) WriteInterval(start, end, num int) (err error) {
+	switch {
+	case start < 0:
+		return fmt.Errorf("%w: WriteInterval start %d", ErrInvalidOffset, start)
+	case start >= end:
+		return fmt.Errorf("%w: WriteInterval from %d to %d", ErrInvalidInterval, start, end)
+	case num == 0:
+		return fmt.Errorf("%w: WriteInterval(%d, %d, 0)", ErrZeroGroups, start, end)
+	}
+	off, rejects := w.Len(), w.rejects
+	if w.literals != nil {
+		if err := w.writeLiteralInterval(start, end, num); err != nil {
+			return err
+		}
+		return w.limited(off, rejects)
+	}
+	if verifying.Load() {
+		defer func() {
+			if err == nil {
+				w.verifyInterval("WriteInterval", off, start, end, 1, max(num, 1))
+			}
+		}()
+	}
+
+	k := shape{offset: start, n: end, num: num, dialect: w.dialect, interval: true}
+	if w.writeShape(k) {
+		return w.limited(off, rejects)
 	}
 
-	w.grow(start, end, num)
+	w.growInterval(start, end, num)
+	w.writeGroup(nil, start, end-start+1)
+	w.repeatInterval(off, num)
 
-	w.WriteString(" ($")
-	w.WriteInt(start)
-	for i := start; i < end; i++ {
-		w.WriteString(", $")
-		w.WriteInt(i + 1)
+	if err := w.limited(off, rejects); err != nil {
+		return err
 	}
-	w.WriteByte(')')
+	storeShape(k, w.Bytes()[off:])
+	return nil
+}
 
-	// Assuming we have more to write...
-	for num--; num > 0; num-- {
-		w.WriteString(", ($")
-		w.WriteInt(start)
-		for i := start; i < end; i++ {
-			w.WriteString(", $")
-			w.WriteInt(i + 1)
+// MustWriteInterval is like WriteInterval but panics if the arguments are
+// invalid.
+func (w *Buffer) MustWriteInterval(start, end, num int) {
+	if err := w.WriteInterval(start, end, num); err != nil {
+		panic(err)
+	}
+}
+
+// repeatInterval appends num-1 copies of the interval written at off, each
+// preceded by ','. Rather than formatting the interval again, it copies the
+// copies written so far, doubling their number each time.
+func (w *Buffer) repeatInterval(off, num int) {
+	if num < 2 {
+		return
+	}
+	g := w.Len() - off
+	w.WriteByte(',')
+	w.Write(w.Bytes()[off : off+g])
+
+	rs, unit := off+g, g+1 // the repeats and the length of each
+	for n := 1; n < num-1; {
+		m := min(n, num-1-n)
+		w.Write(w.Bytes()[rs : rs+m*unit])
+		n += m
+	}
+}
+
+// WriteIntervalStep is like WriteInterval but writes from, from+step,
+// from+2*step, and so on, stopping before passing to. A negative step writes
+// the interval in descending order. An error is returned if the arguments
+// are invalid, that is if from or to are < 0, step is 0 or moves away from
+// to, or num == 0, or, with nothing written, if the output would exceed w's
+// limit.
+//
+//	WriteIntervalStep(1, 7, 3, 1) // ($1, $4, $7)
+//	WriteIntervalStep(3, 1, -1, 2) // ($3, $2, $1), ($3, $2, $1)
+func (w *Buffer) WriteIntervalStep(from, to, step, num int) (err error) {
+	switch {
+	case from < 0 || to < 0:
+		return fmt.Errorf("%w: WriteIntervalStep from %d to %d", ErrInvalidOffset, from, to)
+	case step == 0 || (step > 0 && from > to) || (step < 0 && from < to):
+		return fmt.Errorf("%w: WriteIntervalStep from %d to %d by %d", ErrInvalidInterval, from, to, step)
+	case num == 0:
+		return fmt.Errorf("%w: WriteIntervalStep(%d, %d, %d, 0)", ErrZeroGroups, from, to, step)
+	}
+	mark, rejects := w.Len(), w.rejects
+	if verifying.Load() && w.literals == nil {
+		defer func() {
+			if err == nil {
+				w.verifyInterval("WriteIntervalStep", mark, from, to, step, max(num, 0))
+			}
+		}()
+	}
+	for i := 0; i < num; i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(" (")
+		// Counting, rather than stepping n past to, can't overflow.
+		for k := range (to-from)/step + 1 {
+			n := from + k*step
+			if k > 0 {
+				w.WriteString(", ")
+			}
+			if w.literals == nil {
+				w.writePlaceholder(n)
+			} else if err := w.writeLiteralAt(n); err != nil {
+				w.Truncate(mark)
+				return err
+			}
 		}
 		w.WriteByte(')')
 	}
+	return w.limited(mark, rejects)
+}
 
-	return nil
+// prefixWidth returns the number of bytes needed to write v.
+func prefixWidth(v int) int {
+	if v < 0 {
+		return digits(uint(-v)) + 1
+	}
+	return digits(uint(v))
 }
 
-// {pow, sum}
-var cache = [...][2]int{
+// {pow, sum}. The values are int64 since most overflow a 32-bit int.
+var cache = [...][2]int64{
 	{0, 0},
 	{9, 9},
 	{99, 189},
@@ -220,8 +574,19 @@ var cache = [...][2]int{
 }
 
 // totalWidth finds the cumulative length of all numbers in the range [1, n];
-// add is added to each number. If n <= 0 add is returned.
+// add is added to each number. If n <= 0 add is returned. If the result
+// doesn't fit in an int, 0 is returned.
 func totalWidth(n, add int) int {
+	w := totalWidth64(int64(n), int64(add))
+	if w < 0 || w > math.MaxInt {
+		return 0
+	}
+	return int(w)
+}
+
+// totalWidth64 is like totalWidth but returns -1 if the result overflows an
+// int64.
+func totalWidth64(n, add int64) int64 {
 	switch {
 	case n <= 0:
 		return add
@@ -230,11 +595,44 @@ func totalWidth(n, add int) int {
 	case n < 100:
 		return n*2 - 9 + n*add
 	case n > cache[len(cache)-1][0]:
-		return 0
+		return wideWidth(n, add)
 	}
 	for i := 3; ; i++ {
 		if n <= cache[i][0] {
-			return (n-cache[i-1][0])*i + cache[i-1][1] + n*add
+			return (n-cache[i-1][0])*int64(i) + cache[i-1][1] + n*add
 		}
 	}
 }
+
+// wideWidth computes totalWidth64 for n past the end of cache, where every
+// number has either 18 or 19 digits, checking for overflow.
+func wideWidth(n, add int64) int64 {
+	const max18 = 999999999999999999 // largest 18 digit number
+
+	last := cache[len(cache)-1]
+	lo, sum := uint64(last[0]), uint64(last[1])
+	var carry uint64
+	span := func(hi uint64, digits uint64) {
+		hi2, lo2 := bits.Mul64(hi-lo, digits)
+		var c uint64
+		sum, c = bits.Add64(sum, lo2, 0)
+		carry |= hi2 | c
+		lo = hi
+	}
+	if n > max18 {
+		span(max18, 18)
+		span(uint64(n), 19)
+	} else {
+		span(uint64(n), 18)
+	}
+	if add != 0 {
+		hi, lo := bits.Mul64(uint64(n), uint64(add))
+		var c uint64
+		sum, c = bits.Add64(sum, lo, 0)
+		carry |= hi | c
+	}
+	if carry != 0 || sum > math.MaxInt64 {
+		return -1
+	}
+	return int64(sum)
+}
@@ -2,6 +2,8 @@ package pools
 
 import (
 	"bytes"
+	"errors"
+	"math"
 	"strconv"
 	"testing"
 )
@@ -61,3 +63,265 @@ func BenchmarkTestBuffer_WriteInt(b *testing.B) {
 	}
 	bbb = tb.Bytes()
 }
+
+func TestBuffer_WriteGroupsDialect(t *testing.T) {
+	for _, tt := range []struct {
+		d    Dialect
+		want string
+	}{
+		{Postgres, " ($1, $2), ($3, $4)"},
+		{MySQL, " (?, ?), (?, ?)"},
+		{Oracle, " (:1, :2), (:3, :4)"},
+		{SQLServer, " (@p1, @p2), (@p3, @p4)"},
+	} {
+		// Twice: once to fill the shape cache, once to read from it.
+		for i := 0; i < 2; i++ {
+			w := GetBuffer()
+			w.SetDialect(tt.d)
+			w.WriteGroups(1, 2, 2)
+			expect(t, tt.want, w.String())
+			PutBuffer(w)
+		}
+	}
+}
+
+func TestBuffer_WriteIntervalCached(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		w := GetBuffer()
+		w.WriteString("VALUES")
+		w.WriteInterval(1, 3, 2)
+		expect(t, "VALUES ($1, $2, $3), ($1, $2, $3)", w.String())
+		PutBuffer(w)
+	}
+}
+
+func TestBuffer_WriteGroupsCarry(t *testing.T) {
+	w := GetBuffer()
+	w.WriteGroups(998, 3, 1)
+	expect(t, " ($998, $999, $1000)", w.String())
+	PutBuffer(w)
+}
+
+func BenchmarkBuffer_WriteGroups(b *testing.B) {
+	var buf Buffer
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		// Offset by i so the shape cache isn't hit.
+		buf.WriteGroups(i%1024, 6, 100)
+	}
+	bbb = buf.Bytes()
+}
+
+func TestBuffer_WriteValues(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.WriteString("INSERT INTO t (a, b)")
+	w.WriteValues(1, 2, 2)
+	expect(t, "INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4)", w.String())
+
+	w.Reset()
+	w.WriteValues(1, 1, 1)
+	expect(t, "VALUES ($1)", w.String())
+
+	w.Reset()
+	w.WriteString("SELECT * FROM t WHERE id ")
+	w.WriteIn(1, 3)
+	expect(t, "SELECT * FROM t WHERE id IN ($1, $2, $3)", w.String())
+
+	w.Reset()
+	if err := w.WriteIn(-1, 3); err == nil {
+		t.Fatal("expected an error")
+	}
+	expect(t, 0, w.Len())
+}
+
+func TestBuffer_WriteIntervalStep(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.WriteIntervalStep(1, 7, 3, 1)
+	expect(t, " ($1, $4, $7)", w.String())
+
+	w.Reset()
+	w.WriteIntervalStep(3, 1, -1, 2)
+	expect(t, " ($3, $2, $1), ($3, $2, $1)", w.String())
+
+	w.Reset()
+	w.WriteIntervalStep(1, 6, 2, 1) // stops before passing 6
+	expect(t, " ($1, $3, $5)", w.String())
+
+	// Stepping past the last value would overflow.
+	w.Reset()
+	defer SetVerify(false)
+	SetVerify(true)
+	w.WriteIntervalStep(math.MaxInt-3, math.MaxInt, 2, 1)
+	expect(t, " ($"+strconv.Itoa(math.MaxInt-3)+", $"+strconv.Itoa(math.MaxInt-1)+")", w.String())
+
+	w.Reset()
+	if err := w.WriteIntervalStep(1, 3, -1, 1); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestBuffer_InvalidArguments(t *testing.T) {
+	var w Buffer
+	for _, tc := range []struct {
+		err  error
+		want error
+		msg  string
+	}{
+		{w.WriteGroups(-1, 2, 1), ErrInvalidOffset, "pools: negative offset: WriteGroups offset -1"},
+		{w.WriteGroups(1, 2, 0), ErrZeroGroups, "pools: zero groups: WriteGroups(1, 2, 0)"},
+		{w.WriteInterval(-2, 4, 1), ErrInvalidOffset, "pools: negative offset: WriteInterval start -2"},
+		{w.WriteInterval(4, 4, 1), ErrInvalidInterval, "pools: invalid interval: WriteInterval from 4 to 4"},
+		{w.WriteInterval(0, 4, 0), ErrZeroGroups, "pools: zero groups: WriteInterval(0, 4, 0)"},
+		{w.WriteIntervalStep(1, 3, -1, 1), ErrInvalidInterval, "pools: invalid interval: WriteIntervalStep from 1 to 3 by -1"},
+		{w.WriteIntervalStep(1, 3, 1, 0), ErrZeroGroups, "pools: zero groups: WriteIntervalStep(1, 3, 1, 0)"},
+	} {
+		expect(t, true, errors.Is(tc.err, tc.want))
+		expect(t, tc.msg, tc.err.Error())
+	}
+	expect(t, 0, w.Len())
+}
+
+func TestBuffer_Must(t *testing.T) {
+	var w Buffer
+	w.MustWriteGroups(1, 2, 1)
+	w.MustWriteInterval(1, 2, 1)
+	expect(t, " ($1, $2) ($1, $2)", w.String())
+
+	defer func() {
+		err, _ := recover().(error)
+		expect(t, true, errors.Is(err, ErrZeroGroups))
+	}()
+	w.MustWriteInterval(1, 2, 0)
+	t.Fatal("want a panic")
+}
+
+func TestGetBufferSize(t *testing.T) {
+	b := GetBufferSize(4096)
+	defer PutBuffer(b)
+	if b.Cap() < 4096 {
+		t.Fatalf("want cap >= 4096, got %d", b.Cap())
+	}
+	expect(t, 0, b.Len())
+}
+
+func TestBuffer_Rollback(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.WriteString("SELECT * FROM t")
+	m := w.Mark()
+	w.WriteString(" WHERE ")
+	w.Rollback(m)
+	expect(t, "SELECT * FROM t", w.String())
+
+	// Rolling back to a mark past the end is a no-op.
+	w.Rollback(Mark{n: 100})
+	expect(t, "SELECT * FROM t", w.String())
+}
+
+func TestBuffer_WriteInt(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	for _, i := range []int64{0, 9, 10, 99, 100, 9999, 10000, -1, -10000, 1<<63 - 1} {
+		w.Reset()
+		w.WriteInt64(i)
+		expect(t, strconv.FormatInt(i, 10), w.String())
+	}
+}
+
+func TestBuffer_WriteIntervalRepeat(t *testing.T) {
+	for num := 1; num <= 9; num++ {
+		w := GetBuffer()
+		w.WriteString("x")
+		w.WriteInterval(3, 4, num)
+
+		want := "x ($3, $4)"
+		for i := 1; i < num; i++ {
+			want += ", ($3, $4)"
+		}
+		expect(t, want, w.String())
+		PutBuffer(w)
+	}
+}
+
+func BenchmarkBuffer_WriteInterval(b *testing.B) {
+	var buf Buffer
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		buf.WriteInterval(1, 6, 100+i%1024)
+	}
+	bbb = buf.Bytes()
+}
+
+func TestBuffer_WriteGroupsPrefixGrow(t *testing.T) {
+	var w Buffer
+	w.grow(1, 2, 50, 1000000, 2000000)
+	c := w.Cap()
+	w.WriteGroups(1, 2, 50, 1000000, 2000000)
+	// Everything written should have fit in the initial Grow.
+	expect(t, c, w.Cap())
+	expect(t, " ($1000000, $2000000, $1, $2),", w.String()[:30])
+}
+
+func TestTotalWidth(t *testing.T) {
+	expect(t, 9, totalWidth(9, 0))
+	expect(t, 189, totalWidth(99, 0))
+	expect(t, 2889+4, totalWidth(1000, 0))
+	expect(t, 0, totalWidth(-1, 0))
+}
+
+func TestEstimateGroupsSize(t *testing.T) {
+	for _, d := range []Dialect{Postgres, MySQL, Oracle, SQLServer} {
+		for _, tt := range [][3]int{{0, 4, 2}, {1, 6, 100}, {95, 3, 5}, {998, 1, 30}} {
+			w := GetBuffer()
+			w.SetDialect(d)
+			w.WriteGroups(tt[0], tt[1], tt[2])
+			expect(t, w.Len(), EstimateGroupsSize(tt[0], tt[1], tt[2], d))
+			PutBuffer(w)
+		}
+	}
+	expect(t, 0, EstimateGroupsSize(-1, 1, 1, Postgres))
+}
+
+func TestBuffer_GrowDialect(t *testing.T) {
+	for _, d := range []Dialect{Postgres, MySQL, SQLServer} {
+		var w Buffer
+		w.SetDialect(d)
+		w.WriteInterval(1, 6, 100)
+		expect(t, int64(w.Len()), intervalSize(1, 6, 100, d))
+	}
+}
+
+func TestTotalWidthWide(t *testing.T) {
+	// One past the last cache entry: 18 digits.
+	n := cache[len(cache)-1][0]
+	expect(t, cache[len(cache)-1][1]+18, totalWidth64(n+1, 0))
+	expect(t, cache[len(cache)-1][1]+18*2, totalWidth64(n+2, 0))
+	// The width of every number up to 1<<63-1 overflows.
+	expect(t, int64(-1), totalWidth64(1<<63-1, 0))
+	if totalWidth(int(n+1), 0) == 0 {
+		t.Fatal("totalWidth gave up on a representable width")
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	var d decimal
+	d.set(0)
+	for i := 0; i < 100000; i++ {
+		if got := string(d.bytes()); got != strconv.Itoa(i) {
+			t.Fatalf("want %d, got %s", i, got)
+		}
+		d.inc()
+	}
+}
+
+func TestAppendTo(t *testing.T) {
+	b := GetBuffer()
+	b.WriteString("hello, world")
+	b.Next(7)
+	dst := b.AppendTo([]byte("> "))
+	PutBuffer(b)
+	expect(t, "> world", string(dst))
+	expect(t, 0, len(new(Buffer).AppendTo(nil)))
+}
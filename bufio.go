@@ -0,0 +1,48 @@
+package pools
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+var bufioReaderPool = sync.Pool{
+	New: func() interface{} {
+		return bufio.NewReader(nil)
+	},
+}
+
+// GetBufioReader returns a pooled bufio.Reader, with bufio's default size,
+// reading from r.
+func GetBufioReader(r io.Reader) *bufio.Reader {
+	br := bufioReaderPool.Get().(*bufio.Reader)
+	br.Reset(r)
+	return br
+}
+
+// PutBufioReader returns br to the pool. Any buffered data is discarded.
+func PutBufioReader(br *bufio.Reader) {
+	br.Reset(nil)
+	bufioReaderPool.Put(br)
+}
+
+var bufioWriterPool = sync.Pool{
+	New: func() interface{} {
+		return bufio.NewWriter(nil)
+	},
+}
+
+// GetBufioWriter returns a pooled bufio.Writer, with bufio's default size,
+// writing to w.
+func GetBufioWriter(w io.Writer) *bufio.Writer {
+	bw := bufioWriterPool.Get().(*bufio.Writer)
+	bw.Reset(w)
+	return bw
+}
+
+// PutBufioWriter returns bw to the pool. Any unflushed data is discarded, so
+// call Flush first.
+func PutBufioWriter(bw *bufio.Writer) {
+	bw.Reset(nil)
+	bufioWriterPool.Put(bw)
+}
@@ -1,22 +1,201 @@
 package pools
 
 import (
-	"sync"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
 
 	flatbuffers "github.com/google/flatbuffers/go"
 )
 
-var builderPool = sync.Pool{
-	New: func() interface{} {
-		return flatbuffers.NewBuilder(0)
-	},
+var maxBuilderSize atomic.Int64
+
+// SetMaxBuilderSize causes Builders whose backing array exceeds n bytes to be
+// dropped by PutBuilder instead of returned to the pool, so that one huge
+// table doesn't leave every pooled Builder inflated. A n <= 0 removes the
+// cap, which is the default.
+func SetMaxBuilderSize(n int) {
+	maxBuilderSize.Store(int64(max(n, 0)))
+}
+
+// builderClasses are the smallest capacities of the Builders in each of
+// builderPools.
+var builderClasses = [...]int{0, 4 << 10, 64 << 10, 1 << 20}
+
+// builderPools holds idle Builders segregated by capacity, so that a program
+// building both small and large messages doesn't hand large Builders to small
+// messages. Since Builders only grow, a Builder's capacity reflects the
+// largest message it has built.
+var builderPools [len(builderClasses)]*drainPool
+
+func init() {
+	for i := range builderPools {
+		builderPools[i] = newDrainPool(nil)
+	}
+}
+
+// builderClass returns the index of the class that n falls in.
+func builderClass(n int) int {
+	i := len(builderClasses) - 1
+	for builderClasses[i] > n {
+		i--
+	}
+	return i
 }
 
 func GetBuilder() *flatbuffers.Builder {
-	return builderPool.Get().(*flatbuffers.Builder)
+	checkOutBuilder()
+	b := getBuilder(0)
+	trackGet(b)
+	traceEvent("get", KindBuilder, cap(b.Bytes))
+	return b
+}
+
+// GetBuilderSize is like GetBuilder but the returned Builder's backing array
+// holds at least n bytes, so it doesn't have to reallocate while building a
+// message of that size.
+func GetBuilderSize(n int) *flatbuffers.Builder {
+	checkOutBuilder()
+	b := getBuilder(n)
+	trackGet(b)
+	traceEvent("get", KindBuilder, cap(b.Bytes))
+	return b
+}
+
+// TryGetBuilder is like GetBuilder but returns ErrOutstanding instead of
+// panicking if the limit set by SetMaxOutstanding has been reached.
+func TryGetBuilder() (*flatbuffers.Builder, error) {
+	if _, err := reserveOutstanding(&builderStats.outstanding, 1); err != nil {
+		return nil, err
+	}
+	b := getBuilder(0)
+	trackGet(b)
+	traceEvent("get", KindBuilder, cap(b.Bytes))
+	return b, nil
+}
+
+// checkOutBuilder records that a Builder was taken from the pool, panicking
+// if that exceeds the limit set by SetMaxOutstanding.
+func checkOutBuilder() {
+	if _, err := reserveOutstanding(&builderStats.outstanding, 1); err != nil {
+		panic(err)
+	}
+}
+
+// getBuilder returns a Builder that holds at least n bytes from the pool for
+// n's class, or allocates one. It must have been checked out.
+func getBuilder(n int) *flatbuffers.Builder {
+	builderStats.gets.Add(1)
+	getHooks.call(KindBuilder)
+	var x interface{}
+	if !noPooling.Load() {
+		x = builderPools[builderClass(n)].Get()
+	}
+	if x == nil {
+		builderStats.news.Add(1)
+		return flatbuffers.NewBuilder(n)
+	}
+	b := x.(*flatbuffers.Builder)
+	builderStats.retained.Add(-int64(cap(b.Bytes)))
+	if cap(b.Bytes) < n {
+		b.Bytes = make([]byte, n)
+		b.Reset()
+	}
+	return b
 }
 
 func PutBuilder(b *flatbuffers.Builder) {
-	b.Reset()
-	builderPool.Put(b)
+	putBuilder(b, true)
+}
+
+// PutBuilderNoReset is like PutBuilder but doesn't reset b, for callers that
+// already have. Such Builders aren't counted in BuilderStats.SizeHistogram.
+// In debug builds it panics if b wasn't reset.
+func PutBuilderNoReset(b *flatbuffers.Builder) {
+	if debug && b.Offset() != 0 {
+		panic("pools: PutBuilderNoReset called with a Builder that wasn't reset")
+	}
+	putBuilder(b, false)
+}
+
+func putBuilder(b *flatbuffers.Builder, reset bool) {
+	putHooks.call(KindBuilder)
+	trackPut(b)
+	traceEvent("put", KindBuilder, cap(b.Bytes))
+	builderStats.outstanding.Add(-1)
+	builderStats.puts.Add(1)
+	if reset {
+		builderStats.hist[sizeClass(int(b.Offset()))].Add(1)
+	}
+	if m := maxBuilderSize.Load(); m > 0 && int64(cap(b.Bytes)) > m {
+		builderStats.discards.Add(1)
+		evict(Eviction{Kind: KindBuilder, Reason: EvictOversize, Size: cap(b.Bytes)})
+		return
+	}
+	if reset {
+		poison(b.Bytes)
+		b.Reset()
+	}
+	if noPooling.Load() {
+		builderStats.discards.Add(1)
+		return
+	}
+	builderStats.retained.Add(int64(cap(b.Bytes)))
+	raceRecycle(unsafe.Pointer(b), unsafe.Sizeof(*b), b.Bytes, func() {
+		builderPools[builderClass(cap(b.Bytes))].Put(b)
+	})
+}
+
+// FinishedBytes copies b's finished bytes into a new slice of the same
+// length and returns b to the pool. Unlike calling b.FinishedBytes and then
+// PutBuilder, the result doesn't alias memory the pool will reuse. It panics
+// if b isn't finished.
+func FinishedBytes(b *flatbuffers.Builder) []byte {
+	p := copyFinished(b)
+	PutBuilder(b)
+	return p
+}
+
+func copyFinished(b *flatbuffers.Builder) []byte {
+	f := b.FinishedBytes()
+	p := make([]byte, len(f))
+	copy(p, f)
+	return p
+}
+
+// BuildWith gets a Builder from the pool, calls build with it, and returns a
+// copy of its finished bytes. The Builder is returned to the pool even if
+// build returns an error or panics. build must call Finish unless it returns
+// an error.
+//
+//	p, err := pools.BuildWith(func(b *flatbuffers.Builder) error {
+//		name := b.CreateString(u.Name)
+//		UserStart(b)
+//		UserAddName(b, name)
+//		b.Finish(UserEnd(b))
+//		return nil
+//	})
+func BuildWith(build func(b *flatbuffers.Builder) error) ([]byte, error) {
+	b := GetBuilder()
+	defer PutBuilder(b)
+	if err := build(b); err != nil {
+		return nil, err
+	}
+	return copyFinished(b), nil
+}
+
+// UnsafeFinishedBytes returns b's finished bytes without copying them, and
+// returns b to the pool once the returned slice becomes unreachable. It's
+// the Builder counterpart of Buffer.UnsafeBytes, for response paths where
+// the copy made by FinishedBytes is the largest allocation.
+//
+// The backing array belongs to the returned slice from then on, so b goes
+// back to the pool without it and allocates a new one when it's next used.
+// Do not call PutBuilder on b after calling UnsafeFinishedBytes. It panics if
+// b isn't finished.
+func UnsafeFinishedBytes(b *flatbuffers.Builder) []byte {
+	p := b.FinishedBytes()
+	b.Bytes = nil
+	runtime.AddCleanup(&p[0], PutBuilder, b)
+	return p
 }
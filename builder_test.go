@@ -0,0 +1,192 @@
+package pools
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+func TestMaxBuilderSize(t *testing.T) {
+	defer SetMaxBuilderSize(0)
+	var evicted atomic.Int64
+	OnEvict(func(e Eviction) {
+		if e.Kind == KindBuilder && e.Reason == EvictOversize {
+			evicted.Store(int64(e.Size))
+		}
+	})
+
+	SetMaxBuilderSize(1024)
+	b := GetBuilder()
+	b.CreateByteString(make([]byte, 4096))
+	PutBuilder(b)
+	if evicted.Load() < 4096 {
+		t.Fatalf("want a Builder of at least 4096 bytes evicted, got %d", evicted.Load())
+	}
+}
+
+func TestGetBuilderSize(t *testing.T) {
+	b := GetBuilderSize(4096)
+	defer PutBuilder(b)
+	if cap(b.Bytes) < 4096 {
+		t.Fatalf("want cap >= 4096, got %d", cap(b.Bytes))
+	}
+	expect(t, flatbuffers.UOffsetT(0), b.Offset())
+
+	s := b.CreateString("hello")
+	b.Finish(s)
+	if len(b.FinishedBytes()) == 0 {
+		t.Fatal("want finished bytes")
+	}
+}
+
+func TestReadBuilderStats(t *testing.T) {
+	Drain()
+	b := GetBuilder()
+	b.Finish(b.CreateString("hello"))
+	n := int(b.Offset())
+	PutBuilder(b)
+
+	var s BuilderStats
+	ReadBuilderStats(&s)
+	expect(t, uint64(1), s.Gets)
+	expect(t, uint64(1), s.Puts)
+	expect(t, uint64(1), s.News)
+	expect(t, uint64(0), s.Discards)
+	expect(t, cap(b.Bytes), s.RetainedBytes)
+	expect(t, uint64(1), s.SizeHistogram[sizeClass(n)])
+}
+
+func TestFinishedBytes(t *testing.T) {
+	b := GetBuilder()
+	b.Finish(b.CreateString("hello"))
+	want := string(b.FinishedBytes())
+	p := FinishedBytes(b)
+	expect(t, want, string(p))
+	expect(t, len(p), cap(p))
+
+	// Reusing the pool doesn't affect p.
+	b = GetBuilder()
+	b.Finish(b.CreateString("world"))
+	PutBuilder(b)
+	expect(t, want, string(p))
+}
+
+func TestUnsafeFinishedBytes(t *testing.T) {
+	var before, after BuilderStats
+	ReadBuilderStats(&before)
+
+	b := GetBuilder()
+	b.Finish(b.CreateString("hello"))
+	want := string(b.FinishedBytes())
+	p := UnsafeFinishedBytes(b)
+	expect(t, want, string(p))
+	p = nil
+
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+		ReadBuilderStats(&after)
+		if after.Puts > before.Puts {
+			return
+		}
+	}
+	t.Fatal("Builder was never returned to the pool")
+}
+
+func TestBuilderClasses(t *testing.T) {
+	expect(t, 0, builderClass(0))
+	expect(t, 0, builderClass(4<<10-1))
+	expect(t, 1, builderClass(4<<10))
+	expect(t, 3, builderClass(1<<30))
+
+	Drain()
+	big := GetBuilderSize(64 << 10)
+	PutBuilder(big)
+	small := GetBuilder()
+	defer PutBuilder(small)
+	if small == big {
+		t.Fatal("want a large Builder kept away from small messages")
+	}
+}
+
+func TestBuildWith(t *testing.T) {
+	var before, after BuilderStats
+	ReadBuilderStats(&before)
+
+	p, err := BuildWith(func(b *flatbuffers.Builder) error {
+		b.Finish(b.CreateString("hello"))
+		return nil
+	})
+	expect(t, nil, err)
+	if len(p) == 0 {
+		t.Fatal("want finished bytes")
+	}
+
+	errBuild := errors.New("build failed")
+	p, err = BuildWith(func(*flatbuffers.Builder) error { return errBuild })
+	expect(t, errBuild, err)
+	expect(t, 0, len(p))
+
+	func() {
+		defer func() { recover() }()
+		BuildWith(func(*flatbuffers.Builder) error { panic("oops") })
+	}()
+
+	ReadBuilderStats(&after)
+	expect(t, before.Puts+3, after.Puts)
+}
+
+func TestPutBuilderNoReset(t *testing.T) {
+	var before, after BuilderStats
+	ReadBuilderStats(&before)
+	b := GetBuilder()
+	b.Finish(b.CreateString("hello"))
+	b.Reset()
+	PutBuilderNoReset(b)
+	ReadBuilderStats(&after)
+	expect(t, before.Puts+1, after.Puts)
+	expect(t, before.SizeHistogram, after.SizeHistogram)
+
+	if !debug {
+		return
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want a panic for a Builder that wasn't reset")
+		}
+	}()
+	b = GetBuilder()
+	b.Finish(b.CreateString("hello"))
+	PutBuilderNoReset(b)
+}
+
+func TestBuilderLeakReport(t *testing.T) {
+	if !debug {
+		t.Skip("acquisition stacks are only recorded in debug builds")
+	}
+	TrackLeaks(true)
+	defer TrackLeaks(false)
+
+	b := leakBuilder()
+	var out bytes.Buffer
+	expect(t, nil, Report(&out))
+	PutBuilder(b)
+
+	// The site is leakBuilder, and the stack continues into the test.
+	if !strings.Contains(out.String(), "0 Buffers, 1 Builders from ") ||
+		!strings.Contains(out.String(), "/pools.leakBuilder (") ||
+		!strings.Contains(out.String(), "\t\tgithub.com/") {
+		t.Fatalf("report is missing the Builder's stack: %q", out.String())
+	}
+}
+
+//go:noinline
+func leakBuilder() *flatbuffers.Builder {
+	return GetBuilder()
+}
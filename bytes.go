@@ -0,0 +1,88 @@
+package pools
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// Slices from GetBytes come in power-of-two size classes from
+// 1<<minBytesShift to 1<<maxBytesShift bytes. Larger requests are allocated
+// and dropped as usual. The small classes are backed by slabs; see slab.go.
+const (
+	minBytesShift = 6
+	maxBytesShift = 20
+)
+
+// bytesPools holds *[]byte rather than []byte so that putting a slice doesn't
+// allocate. The *[]byte themselves are recycled through copyBufHeaders.
+var bytesPools [maxBytesShift - minBytesShift + 1]sync.Pool
+
+// bytesClass returns the index into bytesPools for a slice of capacity n,
+// rounding up, or -1 if n is too big to pool.
+func bytesClass(n int) int {
+	if n <= 1<<minBytesShift {
+		return 0
+	}
+	shift := bits.Len(uint(n - 1))
+	if shift > maxBytesShift {
+		return -1
+	}
+	return shift - minBytesShift
+}
+
+// GetBytes returns a slice of length n from the pool. Its capacity may be
+// larger and its contents are undefined. Return it with PutBytes.
+func GetBytes(n int) []byte {
+	c := bytesClass(n)
+	if c < 0 || noPooling.Load() {
+		return make([]byte, n)
+	}
+	if c < numSlabClasses {
+		return slabs[c].get(c)[:n]
+	}
+	if h, ok := bytesPools[c].Get().(*[]byte); ok {
+		p := *h
+		*h = nil
+		copyBufHeaders.Put(h)
+		return p[:n]
+	}
+	return make([]byte, n, 1<<(c+minBytesShift))
+}
+
+// PutBytes returns p, which should have come from GetBytes, to the pool. p
+// must not be used afterward. Slices whose capacity isn't one of the pool's
+// size classes are dropped.
+func PutBytes(p []byte) {
+	c := bytesClass(cap(p))
+	if c < 0 || cap(p) != 1<<(c+minBytesShift) || noPooling.Load() {
+		return
+	}
+	raceRecycle(nil, 0, p, func() {
+		if c < numSlabClasses {
+			slabs[c].put(p)
+			return
+		}
+		h := copyBufHeaders.Get().(*[]byte)
+		*h = p[:0]
+		bytesPools[c].Put(h)
+	})
+}
+
+// BufferProvider hands out byte slices of a requested size. It matches the
+// size-aware buffer-pool hooks of websocket and other networking libraries,
+// so that frame assembly can share the package's slice pool. See Slices.
+type BufferProvider interface {
+	// Get returns a slice of length n.
+	Get(n int) []byte
+	// Put recycles a slice returned by Get. The slice must not be used
+	// afterward.
+	Put(p []byte)
+}
+
+// Slices is the BufferProvider backed by GetBytes and PutBytes.
+var Slices BufferProvider = slicePool{}
+
+type slicePool struct{}
+
+func (slicePool) Get(n int) []byte { return GetBytes(n) }
+func (slicePool) Put(p []byte)     { PutBytes(p) }
@@ -0,0 +1,30 @@
+package pools
+
+import "testing"
+
+func TestGetBytes(t *testing.T) {
+	for _, n := range []int{0, 1, 64, 65, 1000, 1 << 20} {
+		p := GetBytes(n)
+		expect(t, n, len(p))
+		if c := cap(p); c&(c-1) != 0 {
+			t.Fatalf("GetBytes(%d): capacity %d isn't a power of two", n, c)
+		}
+		Slices.Put(p)
+	}
+	big := Slices.Get(1<<20 + 1)
+	expect(t, 1<<20+1, cap(big))
+	PutBytes(big)
+
+	// sync.Pool randomly drops Puts under the race detector, so try a few
+	// times.
+	var reused bool
+	for i := 0; i < 100 && !reused; i++ {
+		p := GetBytes(100)
+		p[0] = 42
+		PutBytes(p)
+		q := GetBytes(100)
+		reused = &p[0] == &q[0]
+		PutBytes(q)
+	}
+	expect(t, true, reused)
+}
@@ -0,0 +1,163 @@
+package pools
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// callers counts Gets and Puts by call site. See TrackCallers.
+var callers struct {
+	enabled atomic.Bool
+	mu      sync.Mutex
+	counts  map[callerKey]*callerCount
+	inside  map[uintptr]bool // PC -> whether it's in the package's code.
+}
+
+type callerKey struct {
+	pc   uintptr
+	kind string
+}
+
+type callerCount struct {
+	gets, puts, retained int64
+}
+
+// TrackCallers turns on, or off, counting the Gets and Puts of Buffers and
+// Builders by the function that called them, like the mutex and block
+// profiles attribute contention. Calls from within the package, such as
+// GetBufferSize calling GetBuffer, are attributed to their first caller
+// outside it. The counts are served by Handler, so the subsystem responsible
+// for churn in the pools can be found. Turning TrackCallers on or off resets
+// the counts.
+//
+// Counting costs a stack walk on every Get and Put, so it's only done in
+// debug builds (see the poolsdebug build tag); otherwise TrackCallers does
+// nothing.
+func TrackCallers(enabled bool) {
+	callers.mu.Lock()
+	defer callers.mu.Unlock()
+	callers.enabled.Store(debug && enabled)
+	callers.counts = nil
+}
+
+// countCaller attributes the Get, or Put if put is true, of x to its caller.
+func countCaller(x interface{}, put bool) {
+	var pc [8]uintptr
+	n := runtime.Callers(3, pc[:])
+	kind, size := KindBuffer, 0
+	switch x := x.(type) {
+	case *Buffer:
+		size = x.Cap()
+	case *flatbuffers.Builder:
+		kind, size = KindBuilder, cap(x.Bytes)
+	}
+
+	callers.mu.Lock()
+	defer callers.mu.Unlock()
+	if !callers.enabled.Load() {
+		return
+	}
+	if callers.counts == nil {
+		callers.counts = make(map[callerKey]*callerCount)
+	}
+	k := callerKey{pc: callerPC(pc[:n]), kind: kind}
+	c := callers.counts[k]
+	if c == nil {
+		c = new(callerCount)
+		callers.counts[k] = c
+	}
+	if put {
+		c.puts++
+		c.retained += int64(size)
+	} else {
+		c.gets++
+	}
+}
+
+// callerPC returns the first PC in stack outside the package's code, or the
+// last one if there are none. callers.mu must be held.
+func callerPC(stack []uintptr) uintptr {
+	if callers.inside == nil {
+		callers.inside = make(map[uintptr]bool)
+	}
+	for i, pc := range stack {
+		inside, ok := callers.inside[pc]
+		if !ok {
+			inside = insidePackage(pc)
+			callers.inside[pc] = inside
+		}
+		if !inside || i == len(stack)-1 {
+			return pc
+		}
+	}
+	return 0
+}
+
+// insidePackage reports whether pc is in one of the package's functions,
+// counting its tests and examples as outside.
+func insidePackage(pc uintptr) bool {
+	f := runtime.FuncForPC(pc - 1)
+	if f == nil {
+		return false
+	}
+	name, ok := strings.CutPrefix(f.Name(), "github.com/sermodigital/pools.")
+	if !ok {
+		return false
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// callerSite is a function's use of one of the package's pools.
+type callerSite struct {
+	Function string
+	File     string
+	Line     int
+	Kind     string
+	Gets     int64
+	Puts     int64
+
+	// Retained is the capacity, in bytes, of the objects the site returned
+	// to the pool, which the pool holds on to until they're reused or
+	// collected.
+	Retained int64
+}
+
+// callerSites returns the counts recorded by TrackCallers, busiest first.
+func callerSites() []callerSite {
+	callers.mu.Lock()
+	sites := make([]callerSite, 0, len(callers.counts))
+	pcs := make([]uintptr, 0, len(callers.counts))
+	for k, c := range callers.counts {
+		sites = append(sites, callerSite{
+			Kind:     k.kind,
+			Gets:     c.gets,
+			Puts:     c.puts,
+			Retained: c.retained,
+		})
+		pcs = append(pcs, k.pc)
+	}
+	callers.mu.Unlock()
+
+	for i, pc := range pcs {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		sites[i].Function, sites[i].File, sites[i].Line = f.Function, f.File, f.Line
+	}
+	slices.SortFunc(sites, func(a, b callerSite) int {
+		if c := cmp.Compare(b.Gets+b.Puts, a.Gets+a.Puts); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Function, b.Function)
+	})
+	return sites
+}
@@ -0,0 +1,42 @@
+package pools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrackCallers(t *testing.T) {
+	TrackCallers(true)
+	defer TrackCallers(false)
+	for range 3 {
+		b := GetBufferSize(100)
+		PutBuffer(b)
+	}
+	PutBuilder(GetBuilder())
+	sites := callerSites()
+	if !debug {
+		expect(t, 0, len(sites))
+		return
+	}
+
+	var gets, puts, retained, builders int64
+	for _, s := range sites {
+		if !strings.HasSuffix(s.Function, ".TestTrackCallers") {
+			continue
+		}
+		switch s.Kind {
+		case KindBuffer:
+			gets += s.Gets
+			puts += s.Puts
+			retained += s.Retained
+		case KindBuilder:
+			builders += s.Gets + s.Puts
+		}
+	}
+	expect(t, int64(3), gets)
+	expect(t, int64(3), puts)
+	expect(t, int64(2), builders)
+	if retained < 300 {
+		t.Fatalf("want at least 300 bytes retained, got %d", retained)
+	}
+}
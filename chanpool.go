@@ -0,0 +1,58 @@
+package pools
+
+import "context"
+
+// NewChanPool returns a Pool backed by a buffered channel holding exactly n
+// Buffers, all allocated up front. It's useful for benchmarks and for
+// programs that need predictable memory use.
+//
+// When the pool is empty, Get allocates a new Buffer, or, if block is true,
+// waits for one to be returned. GetCtx always waits. Buffers returned to a
+// full pool are dropped. NewChanPool panics if n <= 0.
+func NewChanPool(n int, block bool, opts ...Option) *Pool {
+	if n <= 0 {
+		panic("pools: non-positive size for NewChanPool")
+	}
+	return newPool(opts, func(alloc func() *Buffer) freeList {
+		c := &chanPool{free: make(chan *Buffer, n), block: block}
+		for i := 0; i < n; i++ {
+			c.free <- alloc()
+		}
+		return c
+	})
+}
+
+type chanPool struct {
+	free  chan *Buffer
+	block bool
+}
+
+func (c *chanPool) get() *Buffer {
+	if c.block {
+		return <-c.free
+	}
+	select {
+	case b := <-c.free:
+		return b
+	default:
+		return nil
+	}
+}
+
+func (c *chanPool) put(b *Buffer) bool {
+	select {
+	case c.free <- b:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *chanPool) wait(ctx context.Context) (*Buffer, error) {
+	select {
+	case b := <-c.free:
+		return b, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,176 @@
+package pools
+
+import (
+	"io"
+	"sync"
+)
+
+// chunkSize is the size of each ChunkedBuffer segment.
+const chunkSize = 16 << 10
+
+type chunk [chunkSize]byte
+
+var chunkPool = sync.Pool{
+	New: func() interface{} {
+		return new(chunk)
+	},
+}
+
+var chunkedPool = sync.Pool{
+	New: func() interface{} {
+		return new(ChunkedBuffer)
+	},
+}
+
+// ChunkedBuffer is a buffer made of pooled, fixed-size segments. Unlike
+// Buffer, growing it never reallocates or copies what's already been
+// written, which makes it better suited to building multi-megabyte payloads.
+// Segments are returned to their pool as they're read and when the
+// ChunkedBuffer is Reset.
+type ChunkedBuffer struct {
+	chunks []*chunk
+	w      int // write offset into the data.
+	r      int // read offset into the data.
+}
+
+// GetChunkedBuffer returns an empty ChunkedBuffer from the pool.
+func GetChunkedBuffer() *ChunkedBuffer {
+	return chunkedPool.Get().(*ChunkedBuffer)
+}
+
+// PutChunkedBuffer resets c and returns it to the pool.
+func PutChunkedBuffer(c *ChunkedBuffer) {
+	c.Reset()
+	chunkedPool.Put(c)
+}
+
+// Len returns the number of unread bytes in c.
+func (c *ChunkedBuffer) Len() int {
+	return c.w - c.r
+}
+
+// Reset empties c and returns its segments to the pool.
+func (c *ChunkedBuffer) Reset() {
+	for i, ch := range c.chunks {
+		chunkPool.Put(ch)
+		c.chunks[i] = nil
+	}
+	c.chunks = c.chunks[:0]
+	c.w, c.r = 0, 0
+}
+
+// tail returns the unused space in the last segment, adding a segment if
+// the last one is full.
+func (c *ChunkedBuffer) tail() []byte {
+	i := c.w % chunkSize
+	if i == 0 && c.w/chunkSize == len(c.chunks) {
+		c.chunks = append(c.chunks, chunkPool.Get().(*chunk))
+	}
+	return c.chunks[len(c.chunks)-1][i:]
+}
+
+// Write appends p to c. It always returns len(p), nil.
+func (c *ChunkedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		m := copy(c.tail(), p)
+		c.w += m
+		p = p[m:]
+	}
+	return n, nil
+}
+
+// WriteString appends s to c. It always returns len(s), nil.
+func (c *ChunkedBuffer) WriteString(s string) (int, error) {
+	n := len(s)
+	for len(s) > 0 {
+		m := copy(c.tail(), s)
+		c.w += m
+		s = s[m:]
+	}
+	return n, nil
+}
+
+// WriteByte appends b to c. It always returns nil.
+func (c *ChunkedBuffer) WriteByte(b byte) error {
+	c.tail()[0] = b
+	c.w++
+	return nil
+}
+
+// segment returns the unread bytes in the first unread segment.
+func (c *ChunkedBuffer) segment() []byte {
+	first := c.r / chunkSize
+	end := min(c.w, (first+1)*chunkSize)
+	return c.chunks[first][c.r%chunkSize : end-first*chunkSize]
+}
+
+// advance marks n bytes as read, returning segments that have been read
+// completely to the pool.
+func (c *ChunkedBuffer) advance(n int) {
+	c.r += n
+	if c.r == c.w {
+		c.Reset()
+		return
+	}
+	if done := c.r / chunkSize; done > 0 {
+		for _, ch := range c.chunks[:done] {
+			chunkPool.Put(ch)
+		}
+		m := copy(c.chunks, c.chunks[done:])
+		clear(c.chunks[m:])
+		c.chunks = c.chunks[:m]
+		c.r -= done * chunkSize
+		c.w -= done * chunkSize
+	}
+}
+
+// Read reads the next len(p) bytes from c or until c is drained. If c has no
+// data, err is io.EOF unless len(p) is zero.
+func (c *ChunkedBuffer) Read(p []byte) (int, error) {
+	if c.Len() == 0 {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	var n int
+	for n < len(p) && c.Len() > 0 {
+		m := copy(p[n:], c.segment())
+		n += m
+		c.advance(m)
+	}
+	return n, nil
+}
+
+// WriteTo writes c's data to w, one segment at a time, until c is drained
+// or an error occurs.
+func (c *ChunkedBuffer) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for c.Len() > 0 {
+		seg := c.segment()
+		n, err := w.Write(seg)
+		total += int64(n)
+		c.advance(n)
+		if err != nil {
+			return total, err
+		}
+		if n != len(seg) {
+			return total, io.ErrShortWrite
+		}
+	}
+	return total, nil
+}
+
+// AppendTo appends c's unread data to dst and returns the extended slice. c
+// is not modified.
+func (c *ChunkedBuffer) AppendTo(dst []byte) []byte {
+	r := c.r
+	for r < c.w {
+		i := r / chunkSize
+		end := min(c.w, (i+1)*chunkSize)
+		dst = append(dst, c.chunks[i][r%chunkSize:end-i*chunkSize]...)
+		r = end
+	}
+	return dst
+}
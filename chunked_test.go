@@ -0,0 +1,44 @@
+package pools
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestChunkedBuffer(t *testing.T) {
+	want := bytes.Repeat([]byte("0123456789abcdef"), 3*chunkSize/16+7)
+
+	c := GetChunkedBuffer()
+	defer PutChunkedBuffer(c)
+	c.Write(want[:100])
+	c.WriteString(string(want[100 : chunkSize+1]))
+	for _, b := range want[chunkSize+1:] {
+		c.WriteByte(b)
+	}
+	expect(t, len(want), c.Len())
+	expect(t, 4, len(c.chunks))
+
+	if got := c.AppendTo(nil); !bytes.Equal(want, got) {
+		t.Fatal("AppendTo returned the wrong data")
+	}
+
+	// Read part, then WriteTo the rest.
+	head := make([]byte, chunkSize+3)
+	if _, err := io.ReadFull(c, head); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, 3, len(c.chunks))
+
+	var out bytes.Buffer
+	n, err := c.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, int64(len(want)-len(head)), n)
+	if !bytes.Equal(want, append(head, out.Bytes()...)) {
+		t.Fatal("Read and WriteTo returned the wrong data")
+	}
+	expect(t, 0, c.Len())
+	expect(t, 0, len(c.chunks))
+}
@@ -0,0 +1,215 @@
+// Command poolgen generates a strongly typed pool for a struct type, for
+// hot types where even a sync.Pool's interface conversions are measurable
+// and for code that can't use generics. Invoke it with go:generate:
+//
+//	//go:generate go run github.com/sermodigital/pools/cmd/poolgen -type Message
+//
+// For a type T it writes t_pool.go in the same package, declaring
+//
+//	func GetT() *T            // an idle T, or a new one.
+//	func PutT(x *T)           // resets x and returns it to the pool.
+//	type TPoolStats struct    // Gets, Puts, News, and Discards.
+//	func ReadTPoolStats(s *TPoolStats)
+//
+// with get, put, and so on if T is unexported, as in getRow for row. Idle
+// values are kept in a free list bounded by -size, which, unlike a
+// sync.Pool, isn't emptied by the GC. Put resets values with their Reset
+// method if *T has one, and by assigning the zero T otherwise.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+func main() {
+	typ := flag.String("type", "", "name of the struct type to pool; required")
+	size := flag.Int("size", 64, "most idle values kept")
+	output := flag.String("output", "", "output file; default <type>_pool.go")
+	flag.Parse()
+	if *typ == "" || *size <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = strings.ToLower(*typ) + "_pool.go"
+	}
+	src, err := generate(".", *typ, *size)
+	if err == nil {
+		err = os.WriteFile(*output, src, 0o666)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "poolgen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the source of a pool for the struct type typ declared in
+// the package in dir, with room for size idle values.
+func generate(dir, typ string, size int) ([]byte, error) {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	d := data{Type: typ, Size: size}
+	found := false
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		d.Package = f.Name.Name
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == typ {
+						if _, ok := ts.Type.(*ast.StructType); !ok {
+							return nil, fmt.Errorf("%s is not a struct type", typ)
+						}
+						found = true
+					}
+				}
+			case *ast.FuncDecl:
+				if isReset(decl, typ) {
+					d.HasReset = true
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("type %s not found in %s", typ, dir)
+	}
+
+	// The functions for an unexported type are unexported too, such as
+	// getRow for row.
+	d.Get, d.Put, d.Read = "get", "put", "read"
+	if r, _ := utf8.DecodeRuneInString(typ); unicode.IsUpper(r) {
+		d.Get, d.Put, d.Read = "Get", "Put", "Read"
+	}
+	d.Name = strings.ToUpper(typ[:1]) + typ[1:]
+	d.Var = strings.ToLower(typ[:1]) + typ[1:] + "Pool"
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, d); err != nil {
+		return nil, err
+	}
+	return format.Source(b.Bytes())
+}
+
+// isReset reports whether fn is a Reset method with no arguments or results
+// on *typ.
+func isReset(fn *ast.FuncDecl, typ string) bool {
+	if fn.Name.Name != "Reset" || fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return false
+	}
+	if fn.Type.Params.NumFields() != 0 || fn.Type.Results.NumFields() != 0 {
+		return false
+	}
+	star, ok := fn.Recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	id, ok := star.X.(*ast.Ident)
+	return ok && id.Name == typ
+}
+
+type data struct {
+	Package        string
+	Type           string
+	Name           string // Type, capitalized for use after a prefix.
+	Var            string // the pool variable.
+	Get, Put, Read string // function name prefixes.
+	Size           int
+	HasReset       bool
+}
+
+var tmpl = template.Must(template.New("pool").Parse(`// Code generated by poolgen -type {{.Type}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// {{.Var}} is a free list of idle {{.Type}}s.
+var {{.Var}} struct {
+	// The counters come first so they're 64-bit aligned on 32-bit
+	// platforms.
+	gets, puts, news, discards uint64
+
+	mu   sync.Mutex
+	free []*{{.Type}}
+}
+
+// {{.Type}}PoolStats describes the activity of the {{.Type}} pool.
+type {{.Type}}PoolStats struct {
+	Gets     uint64 // values requested from the pool.
+	Puts     uint64 // values returned to the pool.
+	News     uint64 // values allocated because the pool was empty.
+	Discards uint64 // values dropped because the pool was full.
+}
+
+// {{.Get}}{{.Name}} returns an idle {{.Type}} from the pool, or a new one if
+// the pool is empty. Return it with {{.Put}}{{.Name}}.
+func {{.Get}}{{.Name}}() *{{.Type}} {
+	atomic.AddUint64(&{{.Var}}.gets, 1)
+	{{.Var}}.mu.Lock()
+	if n := len({{.Var}}.free); n > 0 {
+		x := {{.Var}}.free[n-1]
+		{{.Var}}.free[n-1] = nil
+		{{.Var}}.free = {{.Var}}.free[:n-1]
+		{{.Var}}.mu.Unlock()
+		return x
+	}
+	{{.Var}}.mu.Unlock()
+	atomic.AddUint64(&{{.Var}}.news, 1)
+	return new({{.Type}})
+}
+
+// {{.Put}}{{.Name}} resets x and returns it to the pool. x must not be used
+// afterward.
+func {{.Put}}{{.Name}}(x *{{.Type}}) {
+	atomic.AddUint64(&{{.Var}}.puts, 1)
+{{- if .HasReset}}
+	x.Reset()
+{{- else}}
+	*x = {{.Type}}{}
+{{- end}}
+	{{.Var}}.mu.Lock()
+	if {{.Var}}.free == nil {
+		{{.Var}}.free = make([]*{{.Type}}, 0, {{.Size}})
+	}
+	if len({{.Var}}.free) < cap({{.Var}}.free) {
+		{{.Var}}.free = append({{.Var}}.free, x)
+		{{.Var}}.mu.Unlock()
+		return
+	}
+	{{.Var}}.mu.Unlock()
+	atomic.AddUint64(&{{.Var}}.discards, 1)
+}
+
+// {{.Read}}{{.Name}}PoolStats populates s with statistics about the {{.Type}}
+// pool.
+func {{.Read}}{{.Name}}PoolStats(s *{{.Type}}PoolStats) {
+	s.Gets = atomic.LoadUint64(&{{.Var}}.gets)
+	s.Puts = atomic.LoadUint64(&{{.Var}}.puts)
+	s.News = atomic.LoadUint64(&{{.Var}}.news)
+	s.Discards = atomic.LoadUint64(&{{.Var}}.discards)
+}
+`))
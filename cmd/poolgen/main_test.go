@@ -0,0 +1,64 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, tc := range []struct {
+		src, typ string
+		want     []string
+	}{
+		{
+			src:  "package msg\n\ntype Message struct{ ID int; Body []byte }\n",
+			typ:  "Message",
+			want: []string{"func GetMessage() *Message", "func PutMessage(x *Message)", "*x = Message{}", "func ReadMessagePoolStats("},
+		},
+		{
+			src:  "package msg\n\ntype row struct{ cols []string }\n\nfunc (r *row) Reset() { r.cols = r.cols[:0] }\n",
+			typ:  "row",
+			want: []string{"func getRow() *row", "func putRow(x *row)", "x.Reset()", "make([]*row, 0, 8)", "func readRowPoolStats(s *rowPoolStats)"},
+		},
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "msg.go"), []byte(tc.src), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		out, err := generate(dir, tc.typ, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range tc.want {
+			if !strings.Contains(string(out), w) {
+				t.Errorf("output is missing %q:\n%s", w, out)
+			}
+		}
+
+		// The output must type check alongside the input.
+		fset := token.NewFileSet()
+		var files []*ast.File
+		for _, src := range []string{tc.src, string(out)} {
+			f, err := parser.ParseFile(fset, "", src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files = append(files, f)
+		}
+		conf := types.Config{Importer: importer.Default()}
+		if _, err := conf.Check("msg", fset, files, nil); err != nil {
+			t.Fatalf("%v:\n%s", err, out)
+		}
+	}
+
+	if _, err := generate(t.TempDir(), "Missing", 8); err == nil {
+		t.Fatal("want an error for a missing type")
+	}
+}
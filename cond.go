@@ -0,0 +1,130 @@
+package pools
+
+import "sync"
+
+var condPool = sync.Pool{
+	New: func() interface{} {
+		return new(Cond)
+	},
+}
+
+// Cond builds the conditions of a WHERE clause. Like Fragment, '?' marks a
+// placeholder and binds the next argument:
+//
+//	c := pools.Where().
+//		And("tenant_id = ?", tenant).
+//		And("created_at > ?", since)
+//	if name != "" {
+//		c.And("name = ?", name)
+//	}
+//	defer pools.PutCond(c)
+//
+//	b.WriteString("SELECT * FROM users")
+//	next := c.WriteClause(b, 1) // SELECT * FROM users WHERE (tenant_id = $1) AND (created_at > $2)
+//
+// Each condition is parenthesized, but conditions are otherwise combined in
+// order, so SQL's precedence applies: AND binds more tightly than OR. Use
+// AndCond and OrCond to group conditions explicitly.
+type Cond struct {
+	f   Fragment
+	err error
+}
+
+// Where returns an empty Cond from the pool.
+func Where() *Cond {
+	return condPool.Get().(*Cond)
+}
+
+// PutCond resets c and returns it to the pool.
+func PutCond(c *Cond) {
+	c.f.Reset()
+	c.err = nil
+	condPool.Put(c)
+}
+
+// Empty reports whether c has no conditions.
+func (c *Cond) Empty() bool {
+	return c.f.sql.Len() == 0
+}
+
+// Err returns the first error encountered while adding conditions, such as a
+// mismatch between placeholders and arguments. Conditions that caused an
+// error are not added.
+func (c *Cond) Err() error {
+	return c.err
+}
+
+// And adds the condition sql, joined to the previous conditions with AND.
+func (c *Cond) And(sql string, args ...interface{}) *Cond {
+	return c.add(" AND (", sql, args)
+}
+
+// Or adds the condition sql, joined to the previous conditions with OR.
+func (c *Cond) Or(sql string, args ...interface{}) *Cond {
+	return c.add(" OR (", sql, args)
+}
+
+// AndCond adds the conditions in g as a single parenthesized condition,
+// joined to the previous conditions with AND. Nothing is added if g is empty.
+func (c *Cond) AndCond(g *Cond) *Cond {
+	return c.addCond(" AND (", g)
+}
+
+// OrCond is like AndCond but joins g with OR.
+func (c *Cond) OrCond(g *Cond) *Cond {
+	return c.addCond(" OR (", g)
+}
+
+func (c *Cond) add(op, sql string, args []interface{}) *Cond {
+	mark, marks, nargs := c.f.sql.Len(), len(c.f.marks), len(c.f.args)
+	c.open(op)
+	if err := c.f.Append(sql, args...); err != nil {
+		c.f.sql.Truncate(mark)
+		c.f.marks, c.f.args = c.f.marks[:marks], c.f.args[:nargs]
+		if c.err == nil {
+			c.err = err
+		}
+		return c
+	}
+	c.f.sql.WriteByte(')')
+	return c
+}
+
+func (c *Cond) addCond(op string, g *Cond) *Cond {
+	if c.err == nil {
+		c.err = g.err
+	}
+	if g.Empty() {
+		return c
+	}
+	c.open(op)
+	c.f.AppendFragment(&g.f)
+	c.f.sql.WriteByte(')')
+	return c
+}
+
+// open writes the operator and opening parenthesis, omitting the operator
+// for the first condition.
+func (c *Cond) open(op string) {
+	if c.Empty() {
+		op = "("
+	}
+	c.f.sql.WriteString(op)
+}
+
+// WriteClause writes " WHERE " followed by c's conditions to b, numbering its
+// placeholders from start in b's Dialect, and returns the number of the next
+// placeholder. Nothing is written if c is empty.
+func (c *Cond) WriteClause(b *Buffer, start int) int {
+	if c.Empty() {
+		return start
+	}
+	b.WriteString(" WHERE ")
+	return c.f.WriteNumbered(b, start)
+}
+
+// Args returns the arguments bound by c's placeholders, in order. The slice
+// aliases c.
+func (c *Cond) Args() []interface{} {
+	return c.f.args
+}
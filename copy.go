@@ -0,0 +1,274 @@
+package pools
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// copyFlushSize is the amount of buffered data that triggers a write to the
+// underlying io.Writer.
+const copyFlushSize = 64 << 10
+
+// binaryCopyHeader is the signature, flags field, and header extension length
+// that begin a binary COPY stream.
+var binaryCopyHeader = []byte("PGCOPY\n\377\r\n\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+
+// binaryCopyNull is the field length that marks a NULL value.
+var binaryCopyNull = []byte{0xff, 0xff, 0xff, 0xff}
+
+// postgresEpoch is the zero time for binary timestamps.
+var postgresEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// CopyWriter writes rows in PostgreSQL's COPY FROM STDIN format, buffering
+// them in a pooled Buffer. Rows are written in text format unless the
+// CopyWriter was created with binary set.
+//
+// In text format, values are separated by tabs and escaped; nil, nil
+// pointers, and invalid sql.Null* values are written as \N. In binary format
+// each value is encoded using the width of its Go type (int32 as int4, int64
+// and int as int8, float64 as float8, time.Time as timestamptz, string and
+// []byte verbatim), so the Go types must match the destination columns.
+// driver.Valuers are encoded using the value they return.
+//
+// Close must be called to write the end of the stream and return the Buffer
+// to the pool.
+type CopyWriter struct {
+	w      io.Writer
+	buf    *Buffer
+	binary bool
+	err    error
+}
+
+// NewCopyWriter returns a CopyWriter that writes to w.
+func NewCopyWriter(w io.Writer, binary bool) *CopyWriter {
+	c := &CopyWriter{w: w, buf: GetBuffer(), binary: binary}
+	if binary {
+		c.buf.Write(binaryCopyHeader)
+	}
+	return c
+}
+
+// WriteRow writes a single row containing values.
+func (c *CopyWriter) WriteRow(values ...interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.buf == nil {
+		return errors.New("pools: WriteRow called after Close")
+	}
+	mark := c.buf.Len()
+	var err error
+	if c.binary {
+		err = c.writeBinaryRow(values)
+	} else {
+		err = c.writeTextRow(values)
+	}
+	if err != nil {
+		// Don't leave half a row in the stream.
+		c.buf.Truncate(mark)
+		return err
+	}
+	if c.buf.Len() >= copyFlushSize {
+		return c.Flush()
+	}
+	return nil
+}
+
+func (c *CopyWriter) writeTextRow(values []interface{}) error {
+	for i, v := range values {
+		if i > 0 {
+			c.buf.WriteByte('\t')
+		}
+		if err := c.buf.writeCopyText(v); err != nil {
+			return err
+		}
+	}
+	c.buf.WriteByte('\n')
+	return nil
+}
+
+func (c *CopyWriter) writeBinaryRow(values []interface{}) error {
+	if len(values) > math.MaxInt16 {
+		return errors.New("pools: too many values in COPY row")
+	}
+	var tmp [8]byte
+	binary.BigEndian.PutUint16(tmp[:], uint16(len(values)))
+	c.buf.Write(tmp[:2])
+	for _, v := range values {
+		if err := c.buf.writeCopyBinary(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered rows to the underlying io.Writer.
+func (c *CopyWriter) Flush() error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.buf == nil || c.buf.Len() == 0 {
+		return nil
+	}
+	_, c.err = c.buf.WriteTo(c.w)
+	return c.err
+}
+
+// Close writes the binary trailer, if any, flushes the CopyWriter, and
+// returns its Buffer to the pool. The text format has no trailer; the end of
+// the data is signaled by ending the COPY itself.
+func (c *CopyWriter) Close() error {
+	if c.buf == nil {
+		return c.err
+	}
+	if c.binary && c.err == nil {
+		c.buf.Write([]byte{0xff, 0xff})
+	}
+	err := c.Flush()
+	PutBuffer(c.buf)
+	c.buf = nil
+	return err
+}
+
+// writeCopyText writes v in COPY text format.
+func (w *Buffer) writeCopyText(v interface{}) error {
+	v, err := nullable(v)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		w.WriteString(`\N`)
+	case string:
+		w.writeCopyEscaped(v)
+	case []byte:
+		if v == nil {
+			w.WriteString(`\N`)
+			break
+		}
+		// bytea hex format; the backslash itself must be escaped.
+		w.WriteString(`\\x`)
+		w.Grow(hex.EncodedLen(len(v)))
+		w.Write(hex.AppendEncode(w.AvailableBuffer(), v))
+	case bool:
+		if v {
+			w.WriteByte('t')
+		} else {
+			w.WriteByte('f')
+		}
+	case int:
+		w.WriteInt(v)
+	case int16:
+		w.WriteInt64(int64(v))
+	case int32:
+		w.WriteInt64(int64(v))
+	case int64:
+		w.WriteInt64(v)
+	case float32:
+		w.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case float64:
+		w.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		w.WriteString(v.Format(time.RFC3339Nano))
+	default:
+		return fmt.Errorf("pools: unsupported COPY value type %T", v)
+	}
+	return nil
+}
+
+// writeCopyEscaped writes s with the backslash escapes required by the COPY
+// text format.
+func (w *Buffer) writeCopyEscaped(s string) {
+	last := 0
+	for i := 0; i < len(s); i++ {
+		var esc string
+		switch s[i] {
+		case '\\':
+			esc = `\\`
+		case '\n':
+			esc = `\n`
+		case '\r':
+			esc = `\r`
+		case '\t':
+			esc = `\t`
+		case '\b':
+			esc = `\b`
+		case '\f':
+			esc = `\f`
+		case '\v':
+			esc = `\v`
+		default:
+			continue
+		}
+		w.WriteString(s[last:i])
+		w.WriteString(esc)
+		last = i + 1
+	}
+	w.WriteString(s[last:])
+}
+
+// writeCopyBinary writes v as a length-prefixed field in COPY binary format.
+func (w *Buffer) writeCopyBinary(v interface{}) error {
+	v, err := nullable(v)
+	if err != nil {
+		return err
+	}
+	var tmp [12]byte
+	// field writes the length n followed by the first n bytes of tmp[4:].
+	field := func(n int) {
+		binary.BigEndian.PutUint32(tmp[:], uint32(n))
+		w.Write(tmp[:4+n])
+	}
+	switch v := v.(type) {
+	case nil:
+		w.Write(binaryCopyNull)
+	case string:
+		binary.BigEndian.PutUint32(tmp[:], uint32(len(v)))
+		w.Write(tmp[:4])
+		w.WriteString(v)
+	case []byte:
+		if v == nil {
+			w.Write(binaryCopyNull)
+			break
+		}
+		binary.BigEndian.PutUint32(tmp[:], uint32(len(v)))
+		w.Write(tmp[:4])
+		w.Write(v)
+	case bool:
+		if v {
+			tmp[4] = 1
+		}
+		field(1)
+	case int16:
+		binary.BigEndian.PutUint16(tmp[4:], uint16(v))
+		field(2)
+	case int32:
+		binary.BigEndian.PutUint32(tmp[4:], uint32(v))
+		field(4)
+	case int:
+		binary.BigEndian.PutUint64(tmp[4:], uint64(v))
+		field(8)
+	case int64:
+		binary.BigEndian.PutUint64(tmp[4:], uint64(v))
+		field(8)
+	case float32:
+		binary.BigEndian.PutUint32(tmp[4:], math.Float32bits(v))
+		field(4)
+	case float64:
+		binary.BigEndian.PutUint64(tmp[4:], math.Float64bits(v))
+		field(8)
+	case time.Time:
+		us := v.UnixMicro() - postgresEpoch.UnixMicro()
+		binary.BigEndian.PutUint64(tmp[4:], uint64(us))
+		field(8)
+	default:
+		return fmt.Errorf("pools: unsupported COPY value type %T", v)
+	}
+	return nil
+}
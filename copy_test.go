@@ -0,0 +1,47 @@
+package pools
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCopyWriterText(t *testing.T) {
+	var out bytes.Buffer
+	c := NewCopyWriter(&out, false)
+	c.WriteRow(1, "a\tb\\c\n", nil, true, []byte{0xde, 0xad})
+	c.WriteRow(int64(2), "", 1.5, false, []byte(nil))
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "1\ta\\tb\\\\c\\n\t\\N\tt\t\\\\xdead\n2\t\t1.5\tf\t\\N\n", out.String())
+}
+
+func TestCopyWriterBinary(t *testing.T) {
+	var out bytes.Buffer
+	c := NewCopyWriter(&out, true)
+	c.WriteRow(int32(7), "hi", nil)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte(nil), binaryCopyHeader...)
+	want = append(want,
+		0, 3, // field count
+		0, 0, 0, 4, 0, 0, 0, 7, // int32
+		0, 0, 0, 2, 'h', 'i', // string
+		0xff, 0xff, 0xff, 0xff, // NULL
+		0xff, 0xff, // trailer
+	)
+	if !bytes.Equal(want, out.Bytes()) {
+		t.Fatalf("want %q, got %q", want, out.Bytes())
+	}
+}
+
+func TestCopyWriterUnsupported(t *testing.T) {
+	var out bytes.Buffer
+	c := NewCopyWriter(&out, false)
+	if err := c.WriteRow(struct{}{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	c.Close()
+	expect(t, 0, out.Len())
+}
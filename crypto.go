@@ -0,0 +1,32 @@
+package pools
+
+import "crypto/cipher"
+
+// maxRecordSize is the largest TLS record: a 5-byte header followed by up to
+// 16KB of plaintext plus 2KB of expansion.
+const maxRecordSize = 5 + 16<<10 + 2048
+
+// GetSealBuf returns an empty slice from the slice pool with room for n
+// bytes of plaintext sealed by aead, for use as Seal's or Open's dst:
+//
+//	dst := pools.GetSealBuf(aead, len(msg))
+//	ct := aead.Seal(dst, nonce, msg, nil)
+//	...
+//	pools.PutBytesZeroed(ct)
+func GetSealBuf(aead cipher.AEAD, n int) []byte {
+	return GetBytes(n + aead.Overhead())[:0]
+}
+
+// GetRecordBuf returns a slice from the slice pool big enough to read any
+// TLS record into.
+func GetRecordBuf() []byte {
+	return GetBytes(maxRecordSize)
+}
+
+// PutBytesZeroed is like PutBytes but first overwrites all of p's memory,
+// up to its capacity, with zeros. Use it for slices that held keys or
+// plaintext, so the data can't leak into later users of the slice.
+func PutBytesZeroed(p []byte) {
+	clear(p[:cap(p)])
+	PutBytes(p)
+}
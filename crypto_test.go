@@ -0,0 +1,46 @@
+package pools
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestSealBuf(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("attack at dawn")
+	nonce := make([]byte, aead.NonceSize())
+
+	dst := GetSealBuf(aead, len(msg))
+	ct := aead.Seal(dst, nonce, msg, nil)
+	if &ct[:1][0] != &dst[:1][0] {
+		t.Fatal("Seal reallocated dst")
+	}
+	pt, err := aead.Open(GetSealBuf(aead, len(ct)), nonce, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, string(msg), string(pt))
+	PutBytesZeroed(pt)
+
+	PutBytesZeroed(ct)
+	// The race detector reports reading memory after Put.
+	if !raceEnabled {
+		for _, c := range ct[:cap(ct)] {
+			if c != 0 {
+				t.Fatal("PutBytesZeroed left data behind")
+			}
+		}
+	}
+
+	if p := GetRecordBuf(); len(p) < 16<<10 {
+		t.Fatalf("record buffer too small: %d", len(p))
+	}
+}
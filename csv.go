@@ -0,0 +1,81 @@
+package pools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteCSVRow writes fields to w as a single CSV record terminated by CRLF.
+// Fields containing commas, double quotes, or line breaks are quoted, and
+// double quotes inside them are doubled, as described in RFC 4180.
+func (w *Buffer) WriteCSVRow(fields ...string) {
+	for i, f := range fields {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.writeCSVField(f)
+	}
+	w.WriteString("\r\n")
+}
+
+// WriteCSVValues is like WriteCSVRow but formats each value according to its
+// type. nil is written as an empty field, []byte as a string, time.Time in
+// RFC 3339 format, and numbers and bools using package strconv. An error is
+// returned, and nothing is written, if a value has an unsupported type.
+func (w *Buffer) WriteCSVValues(values ...interface{}) error {
+	mark := w.Len()
+	for i, v := range values {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		switch v := v.(type) {
+		case nil:
+		case string:
+			w.writeCSVField(v)
+		case []byte:
+			w.writeCSVField(string(v))
+		case bool:
+			w.WriteString(strconv.FormatBool(v))
+		case int:
+			w.WriteInt(v)
+		case int32:
+			w.WriteInt64(int64(v))
+		case int64:
+			w.WriteInt64(v)
+		case uint64:
+			w.WriteString(strconv.FormatUint(v, 10))
+		case float32:
+			w.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+		case float64:
+			w.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		case time.Time:
+			w.WriteString(v.Format(time.RFC3339Nano))
+		default:
+			w.Truncate(mark)
+			return fmt.Errorf("pools: unsupported CSV value type %T", v)
+		}
+	}
+	w.WriteString("\r\n")
+	return nil
+}
+
+func (w *Buffer) writeCSVField(s string) {
+	if !strings.ContainsAny(s, ",\"\r\n") {
+		w.WriteString(s)
+		return
+	}
+	w.WriteByte('"')
+	for {
+		i := strings.IndexByte(s, '"')
+		if i < 0 {
+			break
+		}
+		w.WriteString(s[:i+1])
+		w.WriteByte('"')
+		s = s[i+1:]
+	}
+	w.WriteString(s)
+	w.WriteByte('"')
+}
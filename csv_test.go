@@ -0,0 +1,37 @@
+package pools
+
+import (
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestBuffer_WriteCSVRow(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.WriteCSVRow("a", "b,c", `say "hi"`, "line\nbreak", "")
+	expect(t, "a,\"b,c\",\"say \"\"hi\"\"\",\"line\nbreak\",\r\n", w.String())
+
+	rec, err := csv.NewReader(w).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "b,c", `say "hi"`, "line\nbreak", ""}
+	if !reflect.DeepEqual(want, rec) {
+		t.Fatalf("want %q, got %q", want, rec)
+	}
+}
+
+func TestBuffer_WriteCSVValues(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	if err := w.WriteCSVValues(1, nil, "x,y", true, 2.5); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "1,,\"x,y\",true,2.5\r\n", w.String())
+
+	if err := w.WriteCSVValues(1, struct{}{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	expect(t, "1,,\"x,y\",true,2.5\r\n", w.String())
+}
@@ -0,0 +1,6 @@
+//go:build !poolsdebug
+
+package pools
+
+// debug enables extra checks. Build with -tags poolsdebug to turn it on.
+const debug = false
@@ -0,0 +1,40 @@
+package pools
+
+// decimal is a non-negative integer kept in decimal form. Placeholders are
+// written in runs of consecutive integers, so rather than formatting each
+// one, the previous value's digits are incremented in place.
+type decimal struct {
+	buf [maxIntWidth]byte
+	i   int // start of the digits in buf.
+}
+
+// set sets d to n.
+func (d *decimal) set(n uint) {
+	d.i = len(d.buf)
+	for n >= 10 {
+		d.i--
+		d.buf[d.i] = byte('0' + n%10)
+		n /= 10
+	}
+	d.i--
+	d.buf[d.i] = byte('0' + n)
+}
+
+// inc adds one to d.
+func (d *decimal) inc() {
+	for j := len(d.buf) - 1; j >= d.i; j-- {
+		if d.buf[j] != '9' {
+			d.buf[j]++
+			return
+		}
+		d.buf[j] = '0'
+	}
+	// Every digit was a 9, so the number gains a digit.
+	d.i--
+	d.buf[d.i] = '1'
+}
+
+// bytes returns d's digits. The slice is only valid until d is modified.
+func (d *decimal) bytes() []byte {
+	return d.buf[d.i:]
+}
@@ -0,0 +1,56 @@
+package pools
+
+// Dialect describes the bind parameter syntax and literal quoting rules of a
+// database.
+type Dialect uint8
+
+const (
+	Postgres   Dialect = iota // $1, $2, $3
+	MySQL                     // ?, ?, ?
+	Oracle                    // :1, :2, :3
+	SQLServer                 // @p1, @p2, @p3
+	ClickHouse                // ?, ?, ?
+	Athena                    // ?, ?, ?
+)
+
+// prefix returns the text that precedes a placeholder's index.
+func (d Dialect) prefix() string {
+	switch d {
+	case MySQL, ClickHouse, Athena:
+		return "?"
+	case Oracle:
+		return ":"
+	case SQLServer:
+		return "@p"
+	default:
+		return "$"
+	}
+}
+
+// numbered reports whether the dialect's placeholders include an index.
+func (d Dialect) numbered() bool {
+	switch d {
+	case MySQL, ClickHouse, Athena:
+		return false
+	}
+	return true
+}
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case Oracle:
+		return "oracle"
+	case SQLServer:
+		return "sqlserver"
+	case ClickHouse:
+		return "clickhouse"
+	case Athena:
+		return "athena"
+	default:
+		return "unknown"
+	}
+}
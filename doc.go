@@ -0,0 +1,25 @@
+// Package pools provides pooled Buffers, flatbuffers Builders, and byte
+// slices, along with writers for SQL placeholders and literals built on
+// them.
+//
+// # Environment
+//
+// POOLSDEBUG holds comma-separated key=value settings, in the style of
+// GODEBUG, that are applied when the package is initialized so operators can
+// tune a program without rebuilding it. For example:
+//
+//	POOLSDEBUG=maxsize=1MB,leakcheck=1
+//
+// The settings are:
+//
+//	maxsize=SIZE        SetMaxBufferSize(SIZE); SIZE may end in KB, MB, or GB.
+//	maxbuildersize=SIZE SetMaxBuilderSize(SIZE)
+//	maxoutstanding=N    SetMaxOutstanding(N)
+//	idlettl=DURATION    SetIdleTTL(DURATION), e.g. idlettl=30s.
+//	leakcheck=1         TrackLeaks(true)
+//	trace=1             SetTracing(true)
+//	nopool=1            DisablePooling(true)
+//	verify=1            SetVerify(true)
+//
+// Unknown settings and malformed values are ignored.
+package pools
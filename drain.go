@@ -0,0 +1,89 @@
+package pools
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// drainPool is a sync.Pool that can be emptied all at once. If new is nil,
+// Get returns nil when the pool is empty.
+type drainPool struct {
+	p   atomic.Pointer[sync.Pool]
+	new func() interface{}
+}
+
+func newDrainPool(new func() interface{}) *drainPool {
+	p := &drainPool{new: new}
+	p.drain()
+	return p
+}
+
+func (p *drainPool) Get() interface{} {
+	x := p.p.Load().Get()
+	if x == nil && p.new != nil {
+		x = p.new()
+	}
+	return x
+}
+
+func (p *drainPool) Put(x interface{}) {
+	p.p.Load().Put(x)
+}
+
+// drain replaces the underlying sync.Pool, leaving everything it retained to
+// the GC.
+func (p *drainPool) drain() {
+	p.p.Store(new(sync.Pool))
+}
+
+// sweep drains the pool and returns the objects for which keep returns true
+// to it. It reports how many objects were kept.
+func (p *drainPool) sweep(keep func(interface{}) bool) (n int) {
+	old := p.p.Swap(new(sync.Pool))
+	for x := old.Get(); x != nil; x = old.Get() {
+		if keep(x) {
+			p.Put(x)
+			n++
+		}
+	}
+	return n
+}
+
+// Drain drops every Buffer and Builder retained by the package's pools and
+// the unused slabs behind GetBytes, calls ResetStats, and forgets the
+// learned size histogram, retention cap, and Grow hint. The dropped objects
+// are freed by the GC like any other garbage.
+//
+// Drain is useful in tests that measure allocations and for releasing memory
+// after a spike in traffic. Objects that are checked out during the call are
+// unaffected and can be returned with Put as usual.
+func Drain() {
+	ReleaseIdle()
+	ResetStats()
+
+	trim.samples.Store(0)
+	for i := range trim.sizes {
+		trim.sizes[i].Store(0)
+	}
+	trim.p95.Store(0)
+	trim.retainCap.Store(0)
+	trim.growHint.Store(0)
+	for i := range trim.hist {
+		trim.hist[i].Store(0)
+	}
+}
+
+// ReleaseIdle drops every Buffer and Builder retained by the package's pools
+// and the unused slabs behind GetBytes, leaving them to the GC, like Drain
+// but without resetting statistics or what the pools have learned. See also
+// OnMemoryPressure.
+func ReleaseIdle() {
+	bufferPool.drain()
+	for _, p := range builderPools {
+		p.drain()
+	}
+	for i := range slabs {
+		slabs[i].drain()
+	}
+	builderStats.retained.Store(0)
+}
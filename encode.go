@@ -0,0 +1,136 @@
+package pools
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"sync"
+)
+
+// encodeBufSize is the size of the staging area of the streaming encoders.
+const encodeBufSize = 1 << 10
+
+var base64EncoderPool = sync.Pool{
+	New: func() interface{} {
+		return new(Base64Encoder)
+	},
+}
+
+// Base64Encoder is a pooled streaming base64 encoder, like the one returned
+// by base64.NewEncoder.
+type Base64Encoder struct {
+	enc  *base64.Encoding
+	w    io.Writer
+	err  error
+	buf  [3]byte // partial input block.
+	nbuf int
+	out  [encodeBufSize]byte
+}
+
+// GetBase64Encoder returns a pooled Base64Encoder that writes the encoding
+// of what's written to it to w. Writing to a Buffer, for instance one that
+// holds a JSON response, base64-encodes a blob without allocating. Return it
+// with PutBase64Encoder.
+func GetBase64Encoder(enc *base64.Encoding, w io.Writer) *Base64Encoder {
+	e := base64EncoderPool.Get().(*Base64Encoder)
+	e.enc, e.w = enc, w
+	return e
+}
+
+// PutBase64Encoder flushes e, like Close, and returns it to the pool. It
+// returns the first error e encountered.
+func PutBase64Encoder(e *Base64Encoder) error {
+	err := e.Close()
+	// A failed write can leave a partial block behind; it mustn't reach the
+	// encoder's next user.
+	e.enc, e.w, e.err = nil, nil, nil
+	e.buf, e.nbuf = [3]byte{}, 0
+	base64EncoderPool.Put(e)
+	return err
+}
+
+func (e *Base64Encoder) Write(p []byte) (n int, err error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	// Finish a partial block from the last Write.
+	if e.nbuf > 0 {
+		i := copy(e.buf[e.nbuf:], p)
+		e.nbuf += i
+		n, p = i, p[i:]
+		if e.nbuf < 3 {
+			return n, nil
+		}
+		e.enc.Encode(e.out[:], e.buf[:])
+		if _, e.err = e.w.Write(e.out[:4]); e.err != nil {
+			return n, e.err
+		}
+		e.nbuf = 0
+	}
+	for len(p) >= 3 {
+		nn := min(len(e.out)/4*3, len(p))
+		nn -= nn % 3
+		e.enc.Encode(e.out[:], p[:nn])
+		if _, e.err = e.w.Write(e.out[:nn/3*4]); e.err != nil {
+			return n, e.err
+		}
+		n += nn
+		p = p[nn:]
+	}
+	e.nbuf = copy(e.buf[:], p)
+	return n + e.nbuf, nil
+}
+
+// Close writes any partial block, with padding if the encoding has it. It
+// doesn't close the underlying writer, and e may not be written to
+// afterward, other than by returning it with PutBase64Encoder.
+func (e *Base64Encoder) Close() error {
+	if e.err == nil && e.nbuf > 0 {
+		e.enc.Encode(e.out[:], e.buf[:e.nbuf])
+		_, e.err = e.w.Write(e.out[:e.enc.EncodedLen(e.nbuf)])
+		e.nbuf = 0
+	}
+	return e.err
+}
+
+var hexEncoderPool = sync.Pool{
+	New: func() interface{} {
+		return new(HexEncoder)
+	},
+}
+
+// HexEncoder is a pooled streaming hex encoder, like the one returned by
+// hex.NewEncoder.
+type HexEncoder struct {
+	w   io.Writer
+	out [encodeBufSize]byte
+}
+
+// GetHexEncoder returns a pooled HexEncoder that writes the lowercase hex
+// encoding of what's written to it to w. Return it with PutHexEncoder.
+func GetHexEncoder(w io.Writer) *HexEncoder {
+	e := hexEncoderPool.Get().(*HexEncoder)
+	e.w = w
+	return e
+}
+
+// PutHexEncoder returns e to the pool. Hex has no partial blocks, so there's
+// nothing to flush.
+func PutHexEncoder(e *HexEncoder) {
+	e.w = nil
+	hexEncoderPool.Put(e)
+}
+
+func (e *HexEncoder) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := min(len(p), len(e.out)/2)
+		hex.Encode(e.out[:], p[:chunk])
+		written, err := e.w.Write(e.out[:chunk*2])
+		n += written / 2
+		if err != nil {
+			return n, err
+		}
+		p = p[chunk:]
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package pools
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestBase64Encoder(t *testing.T) {
+	p := bytes.Repeat([]byte("0123456789"), 300)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawURLEncoding} {
+		b := GetBuffer()
+		e := GetBase64Encoder(enc, b)
+		// Uneven writes exercise the partial block handling.
+		for rest := p; len(rest) > 0; {
+			n := min(len(rest), 7)
+			e.Write(rest[:n])
+			rest = rest[n:]
+		}
+		if err := PutBase64Encoder(e); err != nil {
+			t.Fatal(err)
+		}
+		expect(t, enc.EncodeToString(p), b.String())
+		PutBuffer(b)
+	}
+}
+
+func TestBase64EncoderWriteError(t *testing.T) {
+	boom := errors.New("boom")
+	e := GetBase64Encoder(base64.StdEncoding, errWriter{boom})
+	e.Write([]byte("a"))
+	_, err := e.Write([]byte("bc")) // completes a block, which fails to write.
+	expect(t, boom, err)
+	expect(t, boom, PutBase64Encoder(e))
+
+	// The failed block mustn't be encoded for the encoder's next user.
+	expect(t, 0, e.nbuf)
+	expect(t, [3]byte{}, e.buf)
+}
+
+func TestHexEncoder(t *testing.T) {
+	p := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, 300)
+	b := GetBuffer()
+	defer PutBuffer(b)
+	e := GetHexEncoder(b)
+	n, err := e.Write(p)
+	PutHexEncoder(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, len(p), n)
+	expect(t, hex.EncodeToString(p), b.String())
+}
@@ -0,0 +1,69 @@
+package pools
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// init applies POOLSDEBUG; see the package documentation.
+func init() {
+	parseEnv(os.Getenv("POOLSDEBUG"))
+}
+
+func parseEnv(s string) {
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "maxsize":
+			if n, ok := parseSize(v); ok {
+				SetMaxBufferSize(n)
+			}
+		case "maxbuildersize":
+			if n, ok := parseSize(v); ok {
+				SetMaxBuilderSize(n)
+			}
+		case "maxoutstanding":
+			if n, err := strconv.Atoi(v); err == nil {
+				SetMaxOutstanding(n)
+			}
+		case "idlettl":
+			if d, err := time.ParseDuration(v); err == nil {
+				SetIdleTTL(d)
+			}
+		case "leakcheck":
+			TrackLeaks(v == "1")
+		case "trace":
+			SetTracing(v == "1")
+		case "nopool":
+			DisablePooling(v == "1")
+		case "verify":
+			SetVerify(v == "1")
+		}
+	}
+}
+
+// parseSize parses a number of bytes with an optional KB, MB, or GB suffix.
+func parseSize(s string) (int, bool) {
+	shift := 0
+	switch {
+	case strings.HasSuffix(s, "KB"):
+		shift = 10
+	case strings.HasSuffix(s, "MB"):
+		shift = 20
+	case strings.HasSuffix(s, "GB"):
+		shift = 30
+	}
+	if shift != 0 {
+		s = s[:len(s)-2]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > (int(^uint(0)>>1))>>shift {
+		return 0, false
+	}
+	return n << shift, true
+}
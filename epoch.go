@@ -0,0 +1,36 @@
+package pools
+
+import "sync/atomic"
+
+// bufferEpoch is incremented by Invalidate. Buffers from an earlier epoch
+// aren't returned to bufferPool.
+var bufferEpoch atomic.Uint32
+
+// getIdle returns a Buffer from bufferPool, replacing any allocated before
+// the last call to Invalidate.
+func getIdle() *Buffer {
+	if noPooling.Load() {
+		bufferStats.news.Add(1)
+		return newBuffer()
+	}
+	b := bufferPool.Get().(*Buffer)
+	if b.epoch == bufferEpoch.Load() {
+		return b
+	}
+	evictBuffer(b, EvictInvalidated)
+	bufferStats.discards.Add(1)
+	bufferStats.news.Add(1)
+	return newBuffer()
+}
+
+// Invalidate causes every Buffer allocated by GetBuffer before the call to
+// be dropped instead of reused. The idle Buffers in the pool are dropped
+// immediately and those checked out are dropped, with EvictInvalidated,
+// when they're returned with PutBuffer. Invalidate is useful when a change
+// in configuration, such as a new SetMaxBufferSize or a switch to
+// PutBufferZeroed, must apply to every Buffer the program holds. It also
+// revokes every Handle from GetHandle. See Pool.Invalidate for Pools.
+func Invalidate() {
+	bufferEpoch.Add(1)
+	bufferPool.drain()
+}
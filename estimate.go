@@ -0,0 +1,35 @@
+package pools
+
+import "math"
+
+// EstimateGroupsSize returns the number of bytes WriteGroups writes for the
+// given arguments, without a prefix, in dialect. Callers composing a
+// statement from several pieces can use it to Grow the final Buffer once up
+// front. It returns 0 if the arguments are invalid or the size doesn't fit in
+// an int.
+func EstimateGroupsSize(offset, groupLen, groups int, dialect Dialect) int {
+	if offset < 0 || groupLen < 1 || groups < 1 {
+		return 0
+	}
+	n := int64(groupLen) * int64(groups) // placeholders
+	size := int64(groups)*3 + // " ()"
+		(n-int64(groups))*2 + // ", " between placeholders
+		int64(groups) - 1 + // "," between groups
+		n*int64(len(dialect.prefix()))
+	if dialect.numbered() {
+		last := int64(offset) + n - 1
+		hi, lo := totalWidth64(last, 0), totalWidth64(int64(offset)-1, 0)
+		if hi < 0 || lo < 0 {
+			return 0
+		}
+		digits := hi - lo
+		if offset == 0 {
+			digits++ // "0"
+		}
+		size += digits
+	}
+	if size <= 0 || size > math.MaxInt {
+		return 0
+	}
+	return int(size)
+}
@@ -0,0 +1,74 @@
+package pools
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Execer is implemented by *sql.DB, *sql.Tx, and *sql.Conn.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+var argsPool = sync.Pool{
+	New: func() interface{} {
+		return new([]interface{})
+	},
+}
+
+func getArgs() *[]interface{} {
+	return argsPool.Get().(*[]interface{})
+}
+
+func putArgs(p *[]interface{}) {
+	// Drop references so pooled slices don't keep caller data alive.
+	clear(*p)
+	*p = (*p)[:0]
+	argsPool.Put(p)
+}
+
+// ExecGroups executes query followed by one group of Postgres placeholders
+// per row, binding the rows' values in order:
+//
+//	pools.ExecGroups(ctx, db, "INSERT INTO t (a, b) VALUES", [][]interface{}{
+//		{1, "x"},
+//		{2, "y"},
+//	})
+//	// INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4)
+//
+// The statement and arguments are built in pooled memory which is recycled
+// once the statement has executed. Every row must have the same, non-zero
+// number of values.
+func ExecGroups(ctx context.Context, db Execer, query string, rows [][]interface{}) (sql.Result, error) {
+	return ExecGroupsDialect(ctx, db, Postgres, query, rows)
+}
+
+// ExecGroupsDialect is like ExecGroups but writes placeholders for d.
+func ExecGroupsDialect(ctx context.Context, db Execer, d Dialect, query string, rows [][]interface{}) (sql.Result, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, errors.New("pools: ExecGroups called without values")
+	}
+	n := len(rows[0])
+
+	args := getArgs()
+	defer putArgs(args)
+	for _, row := range rows {
+		if len(row) != n {
+			return nil, errors.New("pools: ExecGroups rows have different lengths")
+		}
+		*args = append(*args, row...)
+	}
+
+	b := GetBuffer()
+	b.SetDialect(d)
+	b.WriteString(query)
+	b.WriteGroups(1, n, len(rows))
+	// The driver may hold on to the query (e.g., in a statement cache), so it
+	// must not alias the pooled Buffer.
+	stmt := b.String()
+	PutBuffer(b)
+
+	return db.ExecContext(ctx, stmt, *args...)
+}
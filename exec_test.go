@@ -0,0 +1,62 @@
+package pools
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+type recordExecer struct {
+	query string
+	args  []interface{}
+}
+
+func (r *recordExecer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.query = query
+	r.args = append([]interface{}(nil), args...)
+	return nil, nil
+}
+
+func TestExecGroups(t *testing.T) {
+	var r recordExecer
+	_, err := ExecGroups(context.Background(), &r, "INSERT INTO t (a, b) VALUES", [][]interface{}{
+		{1, "x"},
+		{2, "y"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4)", r.query)
+	expect(t, 4, len(r.args))
+	expect(t, "y", r.args[3])
+}
+
+func TestExecGroupsRagged(t *testing.T) {
+	var r recordExecer
+	_, err := ExecGroups(context.Background(), &r, "INSERT INTO t VALUES", [][]interface{}{
+		{1, 2},
+		{3},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestBuffer_Value(t *testing.T) {
+	b := GetBuffer()
+	defer PutBuffer(b)
+	v, err := b.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := v.([]byte); !ok || p == nil {
+		t.Fatalf("want empty []byte, got %#v", v)
+	}
+
+	b.WriteString("blob")
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.SetLiterals([]interface{}{b})
+	w.WriteGroups(1, 1, 1)
+	expect(t, ` (E'\\x626c6f62'::bytea)`, w.String())
+}
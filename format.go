@@ -0,0 +1,163 @@
+package pools
+
+import (
+	"encoding"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// WriteFormat formats according to format, like fmt.Fprintf, straight into
+// w's free capacity, so it doesn't allocate fmt's intermediate buffer.
+// Formats that use no verbs other than plain %d, %s, and %% with integer,
+// string, and []byte arguments don't go through fmt at all.
+func (w *Buffer) WriteFormat(format string, args ...interface{}) {
+	if b, ok := appendSimple(w.AvailableBuffer(), format, args); ok {
+		w.Write(b)
+		return
+	}
+	w.Write(fmt.Appendf(w.AvailableBuffer(), format, args...))
+}
+
+// Sprintf is like fmt.Sprintf but formats into a pooled Buffer with
+// WriteFormat, so the only allocation is the returned string.
+func Sprintf(format string, args ...interface{}) string {
+	b := GetBuffer()
+	b.WriteFormat(format, args...)
+	s := b.String()
+	PutBuffer(b)
+	return s
+}
+
+// Concat returns the concatenation of parts, built in a pooled Buffer so the
+// only allocation is the returned string.
+func Concat(parts ...string) string {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	if n == 0 {
+		return ""
+	}
+	b := GetBufferSize(n)
+	for _, p := range parts {
+		b.WriteString(p)
+	}
+	s := b.String()
+	PutBuffer(b)
+	return s
+}
+
+// appendSimple appends format and args to b if format only uses plain %d,
+// %s, and %% with arguments of the matching basic types. Otherwise it
+// reports false, leaving the work to fmt.
+func appendSimple(b []byte, format string, args []interface{}) ([]byte, bool) {
+	n := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			b = append(b, c)
+			continue
+		}
+		if i++; i == len(format) {
+			return b, false
+		}
+		verb := format[i]
+		if verb == '%' {
+			b = append(b, '%')
+			continue
+		}
+		if n == len(args) {
+			return b, false
+		}
+		a := args[n]
+		n++
+		switch verb {
+		case 'd':
+			switch v := a.(type) {
+			case int:
+				b = strconv.AppendInt(b, int64(v), 10)
+			case int64:
+				b = strconv.AppendInt(b, v, 10)
+			case int32:
+				b = strconv.AppendInt(b, int64(v), 10)
+			case uint:
+				b = strconv.AppendUint(b, uint64(v), 10)
+			case uint64:
+				b = strconv.AppendUint(b, v, 10)
+			case uint32:
+				b = strconv.AppendUint(b, uint64(v), 10)
+			default:
+				return b, false
+			}
+		case 's':
+			switch v := a.(type) {
+			case string:
+				b = append(b, v...)
+			case []byte:
+				b = append(b, v...)
+			default:
+				return b, false
+			}
+		default:
+			return b, false
+		}
+	}
+	return b, n == len(args)
+}
+
+// WriteStringer writes v.String() to w.
+func (w *Buffer) WriteStringer(v fmt.Stringer) {
+	w.WriteString(v.String())
+}
+
+// WriteText writes the text form of v to w. If v implements
+// encoding.TextAppender its text is appended straight into w's free
+// capacity instead of being marshaled into a temporary slice. If marshaling
+// fails w is left unchanged and the error is returned.
+func (w *Buffer) WriteText(v encoding.TextMarshaler) error {
+	if a, ok := v.(encoding.TextAppender); ok {
+		b, err := a.AppendText(w.AvailableBuffer())
+		if err != nil {
+			return err
+		}
+		w.Write(b)
+		return nil
+	}
+	b, err := v.MarshalText()
+	if err != nil {
+		return err
+	}
+	w.Write(b)
+	return nil
+}
+
+// WriteRedacted writes a masked form of s, such as a credential, for audit
+// logs: its first and last keep characters with every character between
+// them replaced by '*'. If s has no more than 2*keep characters it's masked
+// entirely, so the whole of a short secret is never written. Only the kept
+// characters are copied into w, so the rest never reach pooled memory.
+//
+//	WriteRedacted("sk_live_4eC39HqLyjWDarjtT1", 4) // sk_l******************jtT1
+func (w *Buffer) WriteRedacted(s string, keep int) {
+	keep = max(keep, 0)
+	n := utf8.RuneCountInString(s)
+	if n <= 2*keep {
+		keep = 0
+	}
+	i := 0
+	for range keep {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	j := len(s)
+	for range keep {
+		_, size := utf8.DecodeLastRuneInString(s[:j])
+		j -= size
+	}
+	w.WriteString(s[:i])
+	for range n - 2*keep {
+		w.WriteByte('*')
+	}
+	w.WriteString(s[j:])
+}
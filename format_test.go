@@ -0,0 +1,119 @@
+package pools
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestWriteFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		args   []interface{}
+	}{
+		{"plain", nil},
+		{"%d rows in %s", []interface{}{42, "users"}},
+		{"%d%%, %d, %d, %d", []interface{}{int64(-1), uint(2), int32(3), uint64(4)}},
+		{"%s=%s", []interface{}{"k", []byte("v")}},
+		{"%5d|%q", []interface{}{7, "x"}},
+		{"%s", []interface{}{errors.New("err")}},
+		{"%d", []interface{}{"not an int"}},
+		{"%d %d", []interface{}{1}},
+		{"%d", []interface{}{1, 2}},
+		{"trailing %", nil},
+		{"%v %x", []interface{}{1.5, 255}},
+	} {
+		var b Buffer
+		b.WriteString("> ")
+		b.WriteFormat(tc.format, tc.args...)
+		expect(t, "> "+fmt.Sprintf(tc.format, tc.args...), b.String())
+	}
+}
+
+func TestWriteFormatAllocs(t *testing.T) {
+	var b Buffer
+	b.Grow(64)
+	if n := testing.AllocsPerRun(100, func() {
+		b.Reset()
+		b.WriteFormat("%s: %d", "rows", 7)
+	}); n != 0 {
+		t.Fatalf("want no allocations, got %v", n)
+	}
+}
+
+type textOnly string
+
+func (s textOnly) MarshalText() ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("empty")
+	}
+	return []byte(s), nil
+}
+
+func TestWriteText(t *testing.T) {
+	var b Buffer
+	b.WriteStringer(time.Second)
+	b.WriteByte(' ')
+	if err := b.WriteText(netip.MustParseAddr("10.0.0.1")); err != nil {
+		t.Fatal(err)
+	}
+	b.WriteByte(' ')
+	if err := b.WriteText(textOnly("plain")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteText(textOnly("")); err == nil {
+		t.Fatal("want an error")
+	}
+	expect(t, "1s 10.0.0.1 plain", b.String())
+
+	b.Reset()
+	b.Grow(64)
+	var addr encoding.TextMarshaler = netip.MustParseAddr("192.168.1.1")
+	if n := testing.AllocsPerRun(100, func() {
+		b.Reset()
+		b.WriteText(addr)
+	}); n != 0 {
+		t.Fatalf("want no allocations, got %v", n)
+	}
+}
+
+func TestWriteRedacted(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		keep int
+		want string
+	}{
+		{"sk_live_4eC39HqLyjWDarjtT1", 4, "sk_l******************jtT1"},
+		{"hunter2", 0, "*******"},
+		{"hunter2", 3, "hun*er2"},
+		{"secret", 3, "******"},
+		{"pässwörd", 2, "pä****rd"},
+		{"", 2, ""},
+		{"abc", -1, "***"},
+	} {
+		var b Buffer
+		b.WriteRedacted(tc.s, tc.keep)
+		expect(t, tc.want, b.String())
+	}
+}
+
+func TestSprintfConcat(t *testing.T) {
+	expect(t, "id=7 name=x 1.5", Sprintf("id=%d name=%s %v", 7, "x", 1.5))
+	expect(t, "a-b-c", Concat("a", "-", "b", "-", "c"))
+	expect(t, "", Concat())
+	expect(t, "", Concat("", ""))
+
+	if raceEnabled {
+		t.Skip("sync.Pool drops objects under the race detector")
+	}
+	parts := []string{"SELECT ", "id", " FROM ", "users"}
+	if n := testing.AllocsPerRun(100, func() {
+		Sprintf("%s: %d", "rows", 7)
+		Concat(parts...)
+	}); n != 2 {
+		t.Fatalf("want 2 allocations, got %v", n)
+	}
+}
@@ -0,0 +1,164 @@
+package pools
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+var fragmentPool = sync.Pool{
+	New: func() interface{} {
+		return new(Fragment)
+	},
+}
+
+// Fragment is a piece of SQL and the arguments bound by its placeholders.
+// Fragments are written using '?' for every placeholder, regardless of
+// dialect, and can be built independently and combined. They're numbered
+// when the final statement is built:
+//
+//	where := pools.GetFragment()
+//	where.Append("a = ? AND b = ?", 1, 2)
+//
+//	stmt := pools.GetFragment()
+//	stmt.Append("UPDATE t SET c = ? WHERE ", 3)
+//	stmt.AppendFragment(where)
+//
+//	b := pools.GetBuffer()
+//	args := stmt.Build(b) // UPDATE t SET c = $1 WHERE a = $2 AND b = $3
+type Fragment struct {
+	sql   Buffer // the SQL with placeholders removed.
+	marks []int  // offsets into sql where placeholders go.
+	args  []interface{}
+}
+
+// GetFragment returns an empty Fragment from the pool.
+func GetFragment() *Fragment {
+	return fragmentPool.Get().(*Fragment)
+}
+
+// PutFragment resets f and returns it to the pool.
+func PutFragment(f *Fragment) {
+	f.Reset()
+	fragmentPool.Put(f)
+}
+
+// Reset empties f.
+func (f *Fragment) Reset() {
+	f.sql.Reset()
+	f.marks = f.marks[:0]
+	clear(f.args)
+	f.args = f.args[:0]
+}
+
+// Len returns the number of placeholders in f.
+func (f *Fragment) Len() int {
+	return len(f.marks)
+}
+
+// Args returns the arguments bound by f's placeholders, in order. The slice
+// aliases f.
+func (f *Fragment) Args() []interface{} {
+	return f.args
+}
+
+// Append appends sql to f. Each '?' in sql is a placeholder and binds the
+// next value in args. An error is returned, and f is left unchanged, if the
+// number of placeholders doesn't match len(args).
+func (f *Fragment) Append(sql string, args ...interface{}) error {
+	if strings.Count(sql, "?") != len(args) {
+		return errors.New("pools: Fragment placeholder and argument counts differ")
+	}
+	for {
+		i := strings.IndexByte(sql, '?')
+		if i < 0 {
+			break
+		}
+		f.sql.WriteString(sql[:i])
+		f.marks = append(f.marks, f.sql.Len())
+		sql = sql[i+1:]
+	}
+	f.sql.WriteString(sql)
+	f.args = append(f.args, args...)
+	return nil
+}
+
+// AppendFragment appends g, including its arguments, to f.
+func (f *Fragment) AppendFragment(g *Fragment) {
+	off := f.sql.Len()
+	f.sql.Write(g.sql.Bytes())
+	for _, m := range g.marks {
+		f.marks = append(f.marks, off+m)
+	}
+	f.args = append(f.args, g.args...)
+}
+
+// AppendGroups appends one parenthesized group of placeholders per row,
+// binding the rows' values in order, in the same format as
+// Buffer.WriteGroups. An error is returned, and f is left unchanged, if the
+// rows are empty or have different lengths.
+func (f *Fragment) AppendGroups(rows [][]interface{}) error {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return errors.New("pools: AppendGroups called without values")
+	}
+	n := len(rows[0])
+	for _, row := range rows {
+		if len(row) != n {
+			return errors.New("pools: AppendGroups rows have different lengths")
+		}
+	}
+	for i, row := range rows {
+		if i > 0 {
+			f.sql.WriteByte(',')
+		}
+		f.sql.WriteString(" (")
+		for j := range row {
+			if j > 0 {
+				f.sql.WriteString(", ")
+			}
+			f.marks = append(f.marks, f.sql.Len())
+		}
+		f.sql.WriteByte(')')
+		f.args = append(f.args, row...)
+	}
+	return nil
+}
+
+// Join returns a pooled Fragment containing frags separated by sep. Empty
+// fragments are skipped. The caller should return it with PutFragment.
+func Join(sep string, frags ...*Fragment) *Fragment {
+	f := GetFragment()
+	for _, g := range frags {
+		if g.sql.Len() == 0 && len(g.marks) == 0 {
+			continue
+		}
+		if f.sql.Len() > 0 || len(f.marks) > 0 {
+			f.sql.WriteString(sep)
+		}
+		f.AppendFragment(g)
+	}
+	return f
+}
+
+// Build writes f to b, numbering its placeholders from 1 in b's Dialect, and
+// returns the arguments they bind. The slice aliases f.
+func (f *Fragment) Build(b *Buffer) []interface{} {
+	f.WriteNumbered(b, 1)
+	return f.args
+}
+
+// WriteNumbered writes f to b, numbering its placeholders from start in b's
+// Dialect, and returns the number of the next placeholder. It's useful when
+// f follows other placeholders written directly to b.
+func (f *Fragment) WriteNumbered(b *Buffer, start int) int {
+	p := f.sql.Bytes()
+	last := 0
+	for _, m := range f.marks {
+		b.Write(p[last:m])
+		b.writePlaceholder(start)
+		start++
+		last = m
+	}
+	b.Write(p[last:])
+	return start
+}
@@ -0,0 +1,117 @@
+package pools
+
+import "testing"
+
+func TestFragment(t *testing.T) {
+	a := GetFragment()
+	defer PutFragment(a)
+	a.Append("a = ?", 1)
+
+	c := GetFragment()
+	defer PutFragment(c)
+	c.Append("c IN (?, ?)", 2, 3)
+
+	empty := GetFragment()
+	defer PutFragment(empty)
+
+	where := Join(" AND ", a, empty, c)
+	defer PutFragment(where)
+
+	stmt := GetFragment()
+	defer PutFragment(stmt)
+	stmt.Append("UPDATE t SET d = ? WHERE ", 0)
+	stmt.AppendFragment(where)
+
+	b := GetBuffer()
+	defer PutBuffer(b)
+	args := stmt.Build(b)
+	expect(t, "UPDATE t SET d = $1 WHERE a = $2 AND c IN ($3, $4)", b.String())
+	expect(t, 4, len(args))
+	expect(t, 3, args[3])
+
+	b.Reset()
+	b.SetDialect(SQLServer)
+	stmt.Build(b)
+	expect(t, "UPDATE t SET d = @p1 WHERE a = @p2 AND c IN (@p3, @p4)", b.String())
+}
+
+func TestFragment_AppendGroups(t *testing.T) {
+	f := GetFragment()
+	defer PutFragment(f)
+	f.Append("INSERT INTO t (a, b) VALUES")
+	if err := f.AppendGroups([][]interface{}{{1, 2}, {3, 4}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Append("x = ?"); err == nil {
+		t.Fatal("expected a count mismatch error")
+	}
+
+	b := GetBuffer()
+	defer PutBuffer(b)
+	f.Build(b)
+	expect(t, "INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4)", b.String())
+}
+
+func TestCond(t *testing.T) {
+	sub := Where().And("b = ?", 2).Or("b IS NULL")
+	defer PutCond(sub)
+
+	c := Where().And("a = ?", 1).AndCond(sub).Or("c = ?")
+	defer PutCond(c)
+	if c.Err() == nil {
+		t.Fatal("expected a count mismatch error")
+	}
+
+	b := GetBuffer()
+	defer PutBuffer(b)
+	b.WriteString("UPDATE t SET x = $1")
+	next := c.WriteClause(b, 2)
+	expect(t, "UPDATE t SET x = $1 WHERE (a = $2) AND ((b = $3) OR (b IS NULL))", b.String())
+	expect(t, 4, next)
+	expect(t, 2, len(c.Args()))
+}
+
+func TestCondEmpty(t *testing.T) {
+	c := Where()
+	defer PutCond(c)
+	b := GetBuffer()
+	defer PutBuffer(b)
+	expect(t, 1, c.WriteClause(b, 1))
+	expect(t, 0, b.Len())
+}
+
+func TestBuffer_WritePagination(t *testing.T) {
+	for _, tt := range []struct {
+		limit, offset int
+		d             Dialect
+		want          string
+	}{
+		{10, 20, Postgres, " LIMIT 10 OFFSET 20"},
+		{10, 0, MySQL, " LIMIT 10"},
+		{0, 0, Postgres, ""},
+		{10, 20, Athena, " OFFSET 20 LIMIT 10"},
+		{10, 20, SQLServer, " OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{10, 0, Oracle, " OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{0, 5, SQLServer, " OFFSET 5 ROWS"},
+		{0, 5, MySQL, " LIMIT 18446744073709551615 OFFSET 5"},
+		{0, 5, Postgres, " OFFSET 5"},
+	} {
+		w := GetBuffer()
+		w.WritePagination(tt.limit, tt.offset, tt.d)
+		expect(t, tt.want, w.String())
+		PutBuffer(w)
+	}
+}
+
+func TestFragment_AppendPagination(t *testing.T) {
+	f := GetFragment()
+	defer PutFragment(f)
+	f.Append("SELECT * FROM t WHERE a = ?", 1)
+	f.AppendPagination(10, 20, Postgres)
+
+	b := GetBuffer()
+	defer PutBuffer(b)
+	args := f.Build(b)
+	expect(t, "SELECT * FROM t WHERE a = $1 LIMIT $2 OFFSET $3", b.String())
+	expect(t, 20, args[2])
+}
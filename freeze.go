@@ -0,0 +1,45 @@
+package pools
+
+import "io"
+
+// ReadOnlyBytes is a read-only view of a Buffer's contents, returned by
+// Buffer.Freeze.
+type ReadOnlyBytes struct {
+	p []byte
+}
+
+// Len returns the number of bytes in r.
+func (r ReadOnlyBytes) Len() int { return len(r.p) }
+
+// Bytes returns r's contents. The slice must not be modified.
+func (r ReadOnlyBytes) Bytes() []byte { return r.p[:len(r.p):len(r.p)] }
+
+// String returns r's contents as a string.
+func (r ReadOnlyBytes) String() string { return string(r.p) }
+
+// WriteTo writes r's contents to w.
+func (r ReadOnlyBytes) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(r.p)
+	return int64(n), err
+}
+
+// Freeze returns a read-only view of w's unread contents and marks w as
+// frozen until it's next Reset or returned to the pool. When built with the
+// poolsdebug tag, writing to a frozen Buffer panics, which catches writes
+// that would silently change data still referenced through the view.
+func (w *Buffer) Freeze() ReadOnlyBytes {
+	w.frozen = true
+	return ReadOnlyBytes{p: w.Bytes()}
+}
+
+// Frozen reports whether w has been frozen.
+func (w *Buffer) Frozen() bool {
+	return w.frozen
+}
+
+// checkFrozen panics if debug checks are enabled and w is frozen.
+func (w *Buffer) checkFrozen() {
+	if debug && w.frozen {
+		panic("pools: write to frozen Buffer")
+	}
+}
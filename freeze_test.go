@@ -0,0 +1,140 @@
+package pools
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"testing"
+)
+
+func TestBuffer_Freeze(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.WriteString("hello")
+	r := w.Freeze()
+	expect(t, true, w.Frozen())
+	expect(t, "hello", r.String())
+	expect(t, 5, cap(r.Bytes()))
+
+	w.Reset()
+	expect(t, false, w.Frozen())
+}
+
+func TestBuffer_FreezeWritePanics(t *testing.T) {
+	if !debug {
+		t.Skip("requires -tags poolsdebug")
+	}
+	if raceEnabled {
+		t.Skip("reads memory after Put, which the race detector reports")
+	}
+	w := GetBuffer()
+	w.Freeze()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	w.WriteString("x")
+}
+
+func TestPutBufferPoison(t *testing.T) {
+	if !debug {
+		t.Skip("requires -tags poolsdebug")
+	}
+	if raceEnabled {
+		t.Skip("reads memory after Put, which the race detector reports")
+	}
+	b := GetBuffer()
+	b.WriteString("secret")
+	p := b.Bytes()
+	PutBuffer(b)
+	for _, c := range p {
+		if c != poisonByte {
+			t.Fatalf("want poisoned bytes, got %q", p)
+		}
+	}
+}
+
+func TestPutBuilderPoison(t *testing.T) {
+	if !debug {
+		t.Skip("requires -tags poolsdebug")
+	}
+	if raceEnabled {
+		t.Skip("reads memory after Put, which the race detector reports")
+	}
+	b := GetBuilder()
+	b.Finish(b.CreateString("secret"))
+	p := b.FinishedBytes()
+	PutBuilder(b)
+	for _, c := range p {
+		if c != poisonByte {
+			t.Fatalf("want poisoned bytes, got %q", p)
+		}
+	}
+}
+
+func TestPutBufferZeroed(t *testing.T) {
+	if raceEnabled {
+		t.Skip("reads memory after Put, which the race detector reports")
+	}
+	b := GetBuffer()
+	b.WriteString("password")
+	p := b.Bytes()
+	PutBufferZeroed(b)
+	want := byte(0)
+	if debug {
+		want = poisonByte
+	}
+	for _, c := range p {
+		if c != want {
+			t.Fatalf("want wiped bytes, got %q", p)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	TrackLeaks(true)
+	defer TrackLeaks(false)
+
+	b := GetBuffer()
+	var out bytes.Buffer
+	expect(t, nil, Report(&out))
+	PutBuffer(b)
+
+	if !strings.HasPrefix(out.String(), "pools: ") {
+		t.Fatalf("unexpected report: %q", out.String())
+	}
+	site := "1 Buffers, 0 Builders from github.com/"
+	if debug && (!strings.Contains(out.String(), site) ||
+		!strings.Contains(out.String(), "/pools.TestReport (")) {
+		t.Fatalf("report is missing the acquisition site: %q", out.String())
+	}
+}
+
+func TestOutstandingProfile(t *testing.T) {
+	TrackLeaks(true)
+	defer TrackLeaks(false)
+
+	p := pprof.Lookup("pools.outstanding")
+	n := p.Count()
+	b := GetBuffer()
+	expect(t, n+1, p.Count())
+	PutBuffer(b)
+	expect(t, n, p.Count())
+}
+
+func TestTracing(t *testing.T) {
+	SetTracing(true)
+	defer SetTracing(false)
+
+	var out bytes.Buffer
+	if err := trace.Start(&out); err != nil {
+		t.Skip("tracer unavailable:", err)
+	}
+	PutBuffer(GetBuffer())
+	trace.Stop()
+	if !bytes.Contains(out.Bytes(), []byte("get buffer")) {
+		t.Fatal("trace is missing the Get event")
+	}
+}
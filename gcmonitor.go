@@ -0,0 +1,163 @@
+package pools
+
+import (
+	"math"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	gcScaleOne = 1024 // gcScale when there's no GC pressure.
+	gcScaleMin = gcScaleOne / 16
+
+	gcHeapHigh = 0.8  // live heap over heap goal considered pressure.
+	gcCPUHigh  = 0.10 // fraction of CPU spent in GC considered pressure.
+	memHigh    = 0.9  // memory in use over the memory limit that releases idle objects.
+)
+
+// gcScale scales the learned retention cap and Grow hint, in units of
+// 1/gcScaleOne. StartGCMonitor halves it while the GC is under pressure and
+// doubles it back once the pressure is gone.
+var gcScale atomic.Int64
+
+func init() {
+	gcScale.Store(gcScaleOne)
+}
+
+// retainCap returns the learned retention cap scaled for GC pressure, or 0
+// if none has been learned.
+func retainCap() int64 {
+	c := trim.retainCap.Load()
+	if c == 0 {
+		return 0
+	}
+	// Buffers of up to trimMinSize are always retained, however it's scaled.
+	return max(c*gcScale.Load()/gcScaleOne, min(c, trimMinSize))
+}
+
+// growHint returns the learned Grow hint scaled for GC pressure.
+func growHint() int64 {
+	return trim.growHint.Load() * gcScale.Load() / gcScaleOne
+}
+
+// gcSampleNames are the runtime/metrics StartGCMonitor reads.
+var gcSampleNames = [...]string{
+	"/gc/heap/live:bytes",
+	"/gc/heap/goal:bytes",
+	"/cpu/classes/gc/total:cpu-seconds",
+	"/cpu/classes/total:cpu-seconds",
+	"/gc/gomemlimit:bytes",
+	"/memory/classes/total:bytes",
+	"/memory/classes/heap/released:bytes",
+}
+
+// gcMonitor holds the previous reading of the CPU metrics, which are
+// cumulative.
+type gcMonitor struct {
+	samples       []metrics.Sample
+	gcCPU, allCPU float64
+}
+
+// StartGCMonitor starts a goroutine that, every interval, reads the GC's
+// state from runtime/metrics and adapts the package's pools to it. While
+// the live heap is near the GC's heap goal or the GC is using much of the
+// CPU, retaining large Buffers makes the GC work harder for little gain, so
+// the learned retention cap and Grow hint are halved, down to a sixteenth,
+// every interval; once the pressure is gone they double back. The current
+// scale is reported by ReadStats as GCScale. This helps programs running in
+// memory-constrained containers, where pooling can otherwise fight the GC.
+//
+// If a memory limit is set, with debug.SetMemoryLimit or GOMEMLIMIT, and the
+// memory the runtime holds approaches it, idle objects are the first thing
+// sacrificed: ReleaseIdle is called and then the hooks registered with
+// OnMemoryPressure, every interval until the pressure subsides.
+//
+// Call Stop to end the goroutine, which also restores the full scale.
+// StartGCMonitor panics if interval <= 0.
+func StartGCMonitor(interval time.Duration) *Janitor {
+	if interval <= 0 {
+		panic("pools: non-positive interval for StartGCMonitor")
+	}
+	m := newGCMonitor()
+	m.pressure() // take the first CPU reading.
+	return startJanitor(interval, m.tick, func() { gcScale.Store(gcScaleOne) })
+}
+
+func newGCMonitor() *gcMonitor {
+	m := &gcMonitor{samples: make([]metrics.Sample, len(gcSampleNames))}
+	for i, name := range gcSampleNames {
+		m.samples[i].Name = name
+	}
+	return m
+}
+
+func (m *gcMonitor) tick() {
+	gc, mem := m.pressure()
+	adaptGCScale(gc || mem)
+	if mem {
+		relieveMemory()
+	}
+}
+
+// pressure reports whether the GC has been under pressure since the last
+// call, and whether memory is near its limit.
+func (m *gcMonitor) pressure() (gc, mem bool) {
+	metrics.Read(m.samples)
+	value := func(i int) float64 {
+		switch v := m.samples[i].Value; v.Kind() {
+		case metrics.KindUint64:
+			return float64(v.Uint64())
+		case metrics.KindFloat64:
+			return v.Float64()
+		}
+		return 0
+	}
+	live, goal := value(0), value(1)
+	gcCPU, allCPU := value(2), value(3)
+	limit, used := value(4), value(5)-value(6)
+	defer func() { m.gcCPU, m.allCPU = gcCPU, allCPU }()
+
+	gc = goal > 0 && live/goal > gcHeapHigh
+	if d := allCPU - m.allCPU; d > 0 && (gcCPU-m.gcCPU)/d > gcCPUHigh {
+		gc = true
+	}
+	// Without a limit, gomemlimit is math.MaxInt64.
+	mem = limit > 0 && limit < math.MaxInt64 && used/limit > memHigh
+	return gc, mem
+}
+
+// adaptGCScale halves gcScale under pressure and doubles it otherwise.
+func adaptGCScale(pressure bool) {
+	s := gcScale.Load()
+	if pressure {
+		s = max(s/2, gcScaleMin)
+	} else {
+		s = min(s*2, gcScaleOne)
+	}
+	gcScale.Store(s)
+}
+
+var pressureHooks hooks
+
+// memoryPressure counts calls to relieveMemory.
+var memoryPressure atomic.Uint64
+
+// OnMemoryPressure registers f to be called when StartGCMonitor finds memory
+// near its limit, after the pools' idle objects have been released, so the
+// program can shed its own caches too. f is called on the monitor's
+// goroutine and can't be unregistered.
+func OnMemoryPressure(f func()) {
+	if f == nil {
+		panic("pools: nil hook")
+	}
+	pressureHooks.add(func(string) { f() })
+}
+
+// relieveMemory releases the pools' idle objects and calls the
+// OnMemoryPressure hooks.
+func relieveMemory() {
+	memoryPressure.Add(1)
+	ReleaseIdle()
+	pressureHooks.call("")
+}
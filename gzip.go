@@ -0,0 +1,243 @@
+package pools
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize is the smallest response GzipMiddleware compresses. Smaller
+// responses usually fit in a single packet anyway.
+const gzipMinSize = 1 << 10
+
+// gzipPools holds a pool of gzip.Writers for each compression level, from
+// gzip.HuffmanOnly to gzip.BestCompression.
+var gzipPools [gzip.BestCompression - gzip.HuffmanOnly + 1]sync.Pool
+
+// GzipMiddleware returns an http.Handler that compresses next's responses
+// with gzip at the given level for clients that accept it, using pooled
+// gzip.Writers. Output is held in a pooled Buffer until there is enough to be
+// worth compressing, so small responses are sent as is. Responses that
+// already have a Content-Encoding are left alone.
+//
+// GzipMiddleware panics if level is not a valid gzip compression level.
+func GzipMiddleware(next http.Handler, level int) http.Handler {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		panic(fmt.Sprintf("pools: invalid gzip level %d", level))
+	}
+	pool := &gzipPools[level-gzip.HuffmanOnly]
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{
+			ResponseWriter: w,
+			pool:           pool,
+			level:          level,
+			buf:            GetBuffer(),
+			status:         http.StatusOK,
+		}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept-Encoding") {
+		for _, enc := range strings.Split(v, ",") {
+			enc, q, _ := strings.Cut(enc, ";")
+			if !strings.EqualFold(strings.TrimSpace(enc), "gzip") {
+				continue
+			}
+			q, ok := strings.CutPrefix(strings.TrimSpace(q), "q=")
+			if f, err := strconv.ParseFloat(q, 64); !ok || err != nil || f > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// gzipWriter buffers the start of a response until it knows whether to
+// compress it.
+type gzipWriter struct {
+	http.ResponseWriter
+	pool  *sync.Pool
+	level int
+
+	buf     *Buffer      // output held back before deciding; nil after.
+	gz      *gzip.Writer // non-nil once compressing.
+	status  int
+	started bool // the header has been written.
+}
+
+func (w *gzipWriter) WriteHeader(status int) {
+	// Informational responses are sent straight away.
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if !w.started {
+		w.status = status
+	}
+}
+
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	if w.buf == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	n, _ := w.buf.Write(p)
+	if w.buf.Len() >= gzipMinSize {
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// start writes the header, compressing if possible, and what has been
+// buffered so far.
+func (w *gzipWriter) start() error {
+	h := w.Header()
+	if h.Get("Content-Encoding") == "" && w.buf.Len() >= gzipMinSize {
+		// Once compressed, net/http can't sniff the content type.
+		if h.Get("Content-Type") == "" {
+			h.Set("Content-Type", http.DetectContentType(w.buf.Bytes()))
+		}
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		gz, _ := w.pool.Get().(*gzip.Writer)
+		if gz == nil {
+			gz, _ = gzip.NewWriterLevel(w.ResponseWriter, w.level)
+		} else {
+			gz.Reset(w.ResponseWriter)
+		}
+		w.gz = gz
+	}
+	w.started = true
+	w.ResponseWriter.WriteHeader(w.status)
+
+	b := w.buf
+	w.buf = nil
+	defer PutBuffer(b)
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(b.Bytes())
+	} else if b.Len() > 0 {
+		_, err = w.ResponseWriter.Write(b.Bytes())
+	}
+	return err
+}
+
+// Flush sends what has been written so far, compressed if it's long enough.
+func (w *gzipWriter) Flush() {
+	if w.buf != nil {
+		w.start()
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap allows http.ResponseController to reach the underlying
+// ResponseWriter.
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *gzipWriter) close() {
+	if w.buf != nil {
+		w.start()
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		w.gz.Reset(nil)
+		w.pool.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// compressors holds a pool of *compressor for each compression level, like
+// gzipPools.
+var compressors [gzip.BestCompression - gzip.HuffmanOnly + 1]sync.Pool
+
+// gzipReaders holds idle *gzip.Readers.
+var gzipReaders sync.Pool
+
+// compressor is a gzip.Writer that counts the bytes it writes.
+type compressor struct {
+	gz *gzip.Writer
+	countingWriter
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// CompressTo writes the unread portion of w to dst compressed with gzip at
+// the given level, using a pooled gzip.Writer, so the compressed copy is
+// streamed out rather than held in memory. Like WriteTo it drains w. It
+// returns the number of compressed bytes written.
+func (w *Buffer) CompressTo(dst io.Writer, level int) (int64, error) {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return 0, fmt.Errorf("pools: invalid gzip level %d", level)
+	}
+	pool := &compressors[level-gzip.HuffmanOnly]
+	c, _ := pool.Get().(*compressor)
+	if c == nil {
+		c = new(compressor)
+		c.gz, _ = gzip.NewWriterLevel(&c.countingWriter, level)
+	}
+	c.w, c.n = dst, 0
+	c.gz.Reset(&c.countingWriter)
+	_, err := w.WriteTo(c.gz)
+	if cerr := c.gz.Close(); err == nil {
+		err = cerr
+	}
+	n := c.n
+	c.w = nil
+	pool.Put(c)
+	return n, err
+}
+
+// DecompressFrom reads a gzip stream from r and appends its decompressed
+// contents to w, using a pooled gzip.Reader. It returns the number of
+// decompressed bytes appended.
+func (w *Buffer) DecompressFrom(r io.Reader) (int64, error) {
+	zr, _ := gzipReaders.Get().(*gzip.Reader)
+	var err error
+	if zr == nil {
+		zr, err = gzip.NewReader(r)
+	} else {
+		err = zr.Reset(r)
+	}
+	if err != nil {
+		if zr != nil {
+			gzipReaders.Put(zr)
+		}
+		return 0, err
+	}
+	n, err := w.ReadFrom(zr)
+	if cerr := zr.Close(); err == nil {
+		err = cerr
+	}
+	gzipReaders.Put(zr)
+	return n, err
+}
@@ -0,0 +1,112 @@
+package pools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipMiddleware(t *testing.T) {
+	body := strings.Repeat("hello, world! ", 200)
+	h := GzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		if r.URL.Path == "/small" {
+			io.WriteString(w, "small")
+			return
+		}
+		io.WriteString(w, body)
+	}), gzip.BestSpeed)
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept-Encoding", accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		expect(t, http.StatusAccepted, w.Code)
+		return w
+	}
+
+	w := get("/", "br, gzip")
+	expect(t, "gzip", w.Header().Get("Content-Encoding"))
+	expect(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, body, string(got))
+
+	for _, accept := range []string{"", "gzip;q=0", "deflate"} {
+		w = get("/", accept)
+		expect(t, "", w.Header().Get("Content-Encoding"))
+		expect(t, body, w.Body.String())
+	}
+
+	w = get("/small", "gzip")
+	expect(t, "", w.Header().Get("Content-Encoding"))
+	expect(t, "small", w.Body.String())
+}
+
+func TestGzipMiddlewareLevel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want a panic for an invalid level")
+		}
+	}()
+	GzipMiddleware(http.NotFoundHandler(), 10)
+}
+
+func TestCompressTo(t *testing.T) {
+	want := strings.Repeat("compressible ", 1000)
+	b := GetBuffer()
+	defer PutBuffer(b)
+	var z bytes.Buffer
+	for range 2 { // the second time with pooled state.
+		b.WriteString(want)
+		z.Reset()
+		n, err := b.CompressTo(&z, gzip.BestSpeed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect(t, int64(z.Len()), n)
+		expect(t, 0, b.Len())
+		if z.Len() >= len(want)/10 {
+			t.Fatalf("poorly compressed: %d bytes", z.Len())
+		}
+
+		b.WriteString("> ")
+		m, err := b.DecompressFrom(bytes.NewReader(z.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect(t, int64(len(want)), m)
+		expect(t, "> "+want, b.String())
+		b.Reset()
+	}
+
+	if _, err := b.CompressTo(&z, 42); err == nil {
+		t.Fatal("want an error for an invalid level")
+	}
+	if _, err := b.DecompressFrom(strings.NewReader("not gzip")); err == nil {
+		t.Fatal("want an error for invalid input")
+	}
+
+	if raceEnabled {
+		t.Skip("sync.Pool drops objects under the race detector")
+	}
+	b.WriteString(want)
+	if n := testing.AllocsPerRun(10, func() {
+		z.Reset()
+		b.WriteString("x")
+		b.CompressTo(&z, gzip.BestSpeed)
+	}); n != 0 {
+		t.Fatalf("want no allocations, got %v", n)
+	}
+}
@@ -0,0 +1,103 @@
+package pools
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ErrHandleInvalid is returned by a Handle's methods after Release, or once
+// the Buffer's pool has been invalidated.
+var ErrHandleInvalid = errors.New("pools: Handle is released or invalidated")
+
+// Handle is a revocable reference to a checked-out Buffer. Unlike a *Buffer,
+// which is memory the pool will recycle, a Handle can be made unusable en
+// masse, for example on shutdown or a change in configuration, by the
+// package's Invalidate or the Pool's Invalidate. After that, or after
+// Release, its methods return ErrHandleInvalid instead of touching a Buffer
+// that may belong to someone else.
+//
+// Like a Buffer, a Handle isn't safe for concurrent use.
+type Handle struct {
+	b     *Buffer
+	epoch uint32
+	pool  *Pool // nil for the package's pool.
+}
+
+// GetHandle returns a Handle to a Buffer from GetBuffer.
+func GetHandle() *Handle {
+	b := GetBuffer()
+	return &Handle{b: b, epoch: bufferEpoch.Load()}
+}
+
+// GetHandle returns a Handle to a Buffer from p.Get.
+func (p *Pool) GetHandle() *Handle {
+	b := p.Get()
+	return &Handle{b: b, epoch: p.epoch.Load(), pool: p}
+}
+
+// current returns the epoch of the Handle's pool.
+func (h *Handle) current() *atomic.Uint32 {
+	if h.pool != nil {
+		return &h.pool.epoch
+	}
+	return &bufferEpoch
+}
+
+// Buffer returns the Buffer h refers to, or ErrHandleInvalid. The Buffer
+// mustn't be used after h is released or invalidated.
+func (h *Handle) Buffer() (*Buffer, error) {
+	if h.b == nil || h.epoch != h.current().Load() {
+		return nil, ErrHandleInvalid
+	}
+	return h.b, nil
+}
+
+// Valid reports whether h's methods will use its Buffer.
+func (h *Handle) Valid() bool {
+	_, err := h.Buffer()
+	return err == nil
+}
+
+// Write appends p to the Buffer.
+func (h *Handle) Write(p []byte) (int, error) {
+	b, err := h.Buffer()
+	if err != nil {
+		return 0, err
+	}
+	return b.Write(p)
+}
+
+// WriteString appends s to the Buffer.
+func (h *Handle) WriteString(s string) (int, error) {
+	b, err := h.Buffer()
+	if err != nil {
+		return 0, err
+	}
+	return b.WriteString(s)
+}
+
+// WriteTo writes the Buffer's contents to w, draining it.
+func (h *Handle) WriteTo(w io.Writer) (int64, error) {
+	b, err := h.Buffer()
+	if err != nil {
+		return 0, err
+	}
+	return b.WriteTo(w)
+}
+
+// Release returns the Buffer to its pool and invalidates h. If h was
+// already invalidated the Buffer is dropped, as PutBuffer drops Buffers from
+// before an Invalidate. Calling Release more than once does nothing.
+func (h *Handle) Release() {
+	b := h.b
+	if b == nil {
+		return
+	}
+	h.b = nil
+	if h.pool != nil {
+		h.pool.Put(b)
+	} else {
+		PutBuffer(b)
+	}
+}
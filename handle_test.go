@@ -0,0 +1,45 @@
+package pools
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHandle(t *testing.T) {
+	h := GetHandle()
+	if _, err := h.WriteString("hello"); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	h.WriteTo(&out)
+	expect(t, "hello", out.String())
+	h.Release()
+	h.Release()
+	expect(t, false, h.Valid())
+	if _, err := h.Write([]byte("x")); err != ErrHandleInvalid {
+		t.Fatalf("want ErrHandleInvalid, got %v", err)
+	}
+
+	h = GetHandle()
+	Invalidate()
+	if _, err := h.WriteString("x"); err != ErrHandleInvalid {
+		t.Fatalf("want ErrHandleInvalid, got %v", err)
+	}
+	h.Release()
+	h = GetHandle()
+	expect(t, true, h.Valid())
+	h.Release()
+}
+
+func TestPoolHandle(t *testing.T) {
+	p := NewChanPool(1, false)
+	h := p.GetHandle()
+	b, err := h.Buffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Invalidate()
+	expect(t, false, h.Valid())
+	h.Release()
+	expect(t, true, p.Get() != b)
+}
@@ -0,0 +1,96 @@
+package pools
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// state is the document served by Handler.
+type state struct {
+	Buffers  Stats
+	Builders BuilderStats
+	// Sites are where outstanding objects were acquired. See TrackLeaks.
+	Sites []leakSite `json:",omitempty"`
+	// Callers are the call sites using the pools. See TrackCallers.
+	Callers []callerSite `json:",omitempty"`
+}
+
+func readState() *state {
+	var s state
+	ReadStats(&s.Buffers)
+	ReadBuilderStats(&s.Builders)
+	s.Sites = leakSites()
+	s.Callers = callerSites()
+	return &s
+}
+
+// Handler returns an http.Handler that serves the state of the package's
+// pools: the statistics from ReadStats and ReadBuilderStats and, in debug
+// builds with TrackLeaks on, where the objects that are checked out were
+// acquired, or with TrackCallers on, which functions get and put them. It
+// serves HTML to browsers and JSON to everything else, and is meant to be
+// mounted under /debug/pools:
+//
+//	http.Handle("/debug/pools", pools.Handler())
+func Handler() http.Handler {
+	return http.HandlerFunc(serveState)
+}
+
+func serveState(w http.ResponseWriter, r *http.Request) {
+	s := readState()
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		stateTemplate.Execute(w, s)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+var stateTemplate = template.Must(template.New("state").Parse(`<!DOCTYPE html>
+<title>pools</title>
+<h1>Buffers</h1>
+<table>
+<tr><td>Gets</td><td>{{.Buffers.Gets}}</td></tr>
+<tr><td>Puts</td><td>{{.Buffers.Puts}}</td></tr>
+<tr><td>News</td><td>{{.Buffers.News}}</td></tr>
+<tr><td>Discards</td><td>{{.Buffers.Discards}}</td></tr>
+<tr><td>Outstanding</td><td>{{.Buffers.Outstanding}}</td></tr>
+<tr><td>Retain cap</td><td>{{.Buffers.RetainCap}}</td></tr>
+<tr><td>Grow hint</td><td>{{.Buffers.GrowHint}}</td></tr>
+<tr><td>GC scale</td><td>{{.Buffers.GCScale}}</td></tr>
+<tr><td>Peak outstanding</td><td>{{.Buffers.PeakOutstanding}} (max {{.Buffers.MaxOutstanding}})</td></tr>
+<tr><td>Peak size</td><td>{{.Buffers.PeakSize}} (max {{.Buffers.MaxSize}})</td></tr>
+</table>
+<h2>Size histogram</h2>
+<table>
+<tr><th>Bucket</th><th>Count</th></tr>
+{{range $i, $n := .Buffers.SizeHistogram}}<tr><td>{{$i}}</td><td>{{$n}}</td></tr>
+{{end}}</table>
+<h1>Builders</h1>
+<table>
+<tr><td>Gets</td><td>{{.Builders.Gets}}</td></tr>
+<tr><td>Puts</td><td>{{.Builders.Puts}}</td></tr>
+<tr><td>News</td><td>{{.Builders.News}}</td></tr>
+<tr><td>Discards</td><td>{{.Builders.Discards}}</td></tr>
+<tr><td>Outstanding</td><td>{{.Builders.Outstanding}}</td></tr>
+<tr><td>Retained bytes</td><td>{{.Builders.RetainedBytes}}</td></tr>
+</table>
+<h2>Size histogram</h2>
+<table>
+<tr><th>Bucket</th><th>Count</th></tr>
+{{range $i, $n := .Builders.SizeHistogram}}<tr><td>{{$i}}</td><td>{{$n}}</td></tr>
+{{end}}</table>
+{{if .Sites}}<h1>Outstanding objects</h1>
+<table>
+<tr><th>Buffers</th><th>Builders</th><th>Acquired by</th></tr>
+{{range .Sites}}<tr><td>{{.Buffers}}</td><td>{{.Builders}}</td><td>{{.Function}} ({{.File}}:{{.Line}})</td></tr>
+{{end}}</table>
+{{end}}{{if .Callers}}<h1>Callers</h1>
+<table>
+<tr><th>Kind</th><th>Gets</th><th>Puts</th><th>Retained bytes</th><th>Function</th></tr>
+{{range .Callers}}<tr><td>{{.Kind}}</td><td>{{.Gets}}</td><td>{{.Puts}}</td><td>{{.Retained}}</td><td>{{.Function}} ({{.File}}:{{.Line}})</td></tr>
+{{end}}</table>
+{{end}}`))
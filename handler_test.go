@@ -0,0 +1,31 @@
+package pools
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	PutBuffer(GetBuffer())
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pools", nil))
+	expect(t, "application/json", rec.Header().Get("Content-Type"))
+	var s state
+	if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Buffers.Gets == 0 {
+		t.Fatalf("want Gets > 0, got %+v", s.Buffers)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/debug/pools", nil)
+	req.Header.Set("Accept", "text/html")
+	Handler().ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), "<h1>Buffers</h1>") {
+		t.Fatalf("unexpected HTML: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,46 @@
+package pools
+
+import (
+	"bytes"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// BufferFromBuilder returns a Buffer from the pool holding the message fb
+// finished, without copying it: the Buffer takes over fb's backing array,
+// and fb, given the Buffer's old memory in exchange, is reset and returned
+// to its pool. fb mustn't be used afterward. BufferFromBuilder panics if fb
+// hasn't finished a message.
+//
+// The Buffer has no free capacity, so writing to it reallocates.
+func BufferFromBuilder(fb *flatbuffers.Builder) *Buffer {
+	msg := fb.FinishedBytes()
+	b := GetBuffer()
+	fb.Bytes = takeMem(b)
+	b.Buffer = *bytes.NewBuffer(msg)
+	fb.Reset()
+	PutBuilderNoReset(fb)
+	return b
+}
+
+// BuilderFromBuffer returns a Builder from the pool whose backing array is
+// b's memory, so a message as large as b's capacity can be built without
+// growing it. b, given the Builder's old memory in exchange, is returned to
+// the pool and mustn't be used afterward.
+func BuilderFromBuffer(b *Buffer) *flatbuffers.Builder {
+	fb := GetBuilder()
+	old := fb.Bytes
+	fb.Bytes = takeMem(b)
+	fb.Reset()
+	b.Buffer = *bytes.NewBuffer(old[:0])
+	PutBuffer(b)
+	return fb
+}
+
+// takeMem empties b and returns the whole of its backing array, which the
+// caller must replace.
+func takeMem(b *Buffer) []byte {
+	b.Reset()
+	mem := b.AvailableBuffer()
+	return mem[:cap(mem)]
+}
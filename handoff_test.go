@@ -0,0 +1,36 @@
+package pools
+
+import (
+	"bytes"
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+func TestBufferFromBuilder(t *testing.T) {
+	fb := GetBuilder()
+	s := fb.CreateString("hello")
+	fb.Finish(s)
+	want := bytes.Clone(fb.FinishedBytes())
+	msg := &fb.FinishedBytes()[0]
+
+	b := BufferFromBuilder(fb)
+	expect(t, true, bytes.Equal(want, b.Bytes()))
+	expect(t, msg, &b.Bytes()[0]) // not copied.
+	PutBuffer(b)
+}
+
+func TestBuilderFromBuffer(t *testing.T) {
+	b := GetBufferSize(1 << 10)
+	b.WriteString("scratch")
+	n := b.Cap()
+	mem := &b.Bytes()[0]
+
+	fb := BuilderFromBuffer(b)
+	expect(t, n, len(fb.Bytes))
+	expect(t, mem, &fb.Bytes[0])
+	expect(t, flatbuffers.UOffsetT(0), fb.Offset())
+	fb.Finish(fb.CreateString("hi"))
+	expect(t, mem, &fb.Bytes[0]) // didn't grow.
+	PutBuilder(fb)
+}
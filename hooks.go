@@ -0,0 +1,136 @@
+package pools
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kinds of pooled objects passed to hooks.
+const (
+	KindBuffer  = "buffer"
+	KindBuilder = "builder"
+)
+
+// hooks is a copy-on-write list of callbacks so that calling them doesn't
+// need a lock.
+type hooks struct {
+	mu sync.Mutex
+	fs atomic.Pointer[[]func(kind string)]
+}
+
+func (h *hooks) add(f func(kind string)) {
+	if f == nil {
+		panic("pools: nil hook")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var fs []func(kind string)
+	if p := h.fs.Load(); p != nil {
+		fs = append(fs, *p...)
+	}
+	fs = append(fs, f)
+	h.fs.Store(&fs)
+}
+
+func (h *hooks) call(kind string) {
+	p := h.fs.Load()
+	if p == nil {
+		return
+	}
+	for _, f := range *p {
+		f(kind)
+	}
+}
+
+var getHooks, putHooks hooks
+
+// OnGet registers f to be called every time an object is taken from one of
+// the package's pools. kind is KindBuffer or KindBuilder.
+//
+// Hooks are called synchronously on the caller's goroutine, in the order
+// they were registered, and can't be unregistered. They must be safe for
+// concurrent use and should be cheap, since they run on every Get.
+func OnGet(f func(kind string)) {
+	getHooks.add(f)
+}
+
+// OnPut is like OnGet but f is called every time an object is returned to
+// one of the package's pools, before it's reset.
+func OnPut(f func(kind string)) {
+	putHooks.add(f)
+}
+
+// Reasons an object was evicted instead of returned to its pool.
+const (
+	// EvictOversize means the object was much larger than the sizes the
+	// program typically uses.
+	EvictOversize = "oversize"
+
+	// EvictIdle means the object sat unused in its pool for longer than
+	// the idle TTL. See SetIdleTTL.
+	EvictIdle = "idle"
+
+	// EvictShrink means the janitor released the object because the pool
+	// held more than the program recently needed. See StartJanitor.
+	EvictShrink = "shrink"
+
+	// EvictFull means the object's bounded pool was already full.
+	EvictFull = "full"
+
+	// EvictInvalidated means the object was allocated before its pool was
+	// invalidated. See Invalidate.
+	EvictInvalidated = "invalidated"
+)
+
+// Eviction describes an object that was dropped instead of being returned to
+// its pool.
+type Eviction struct {
+	Kind   string        // KindBuffer or KindBuilder.
+	Reason string        // why it was dropped, e.g. EvictOversize.
+	Size   int           // capacity in bytes.
+	Age    time.Duration // time since it was allocated, or 0 if unknown.
+}
+
+var evictHooks struct {
+	mu sync.Mutex
+	fs atomic.Pointer[[]func(Eviction)]
+}
+
+// OnEvict registers f to be called every time an object is dropped instead
+// of being returned to its pool. The same rules as OnGet apply.
+func OnEvict(f func(Eviction)) {
+	if f == nil {
+		panic("pools: nil hook")
+	}
+	evictHooks.mu.Lock()
+	defer evictHooks.mu.Unlock()
+	var fs []func(Eviction)
+	if p := evictHooks.fs.Load(); p != nil {
+		fs = append(fs, *p...)
+	}
+	fs = append(fs, f)
+	evictHooks.fs.Store(&fs)
+}
+
+func evict(e Eviction) {
+	p := evictHooks.fs.Load()
+	if p == nil {
+		return
+	}
+	for _, f := range *p {
+		f(e)
+	}
+}
+
+// evictBuffer reports b as evicted for reason.
+func evictBuffer(b *Buffer, reason string) {
+	if evictHooks.fs.Load() == nil {
+		return
+	}
+	var age time.Duration
+	if !b.created.IsZero() {
+		age = time.Since(b.created)
+	}
+	evict(Eviction{Kind: KindBuffer, Reason: reason, Size: b.Cap(), Age: age})
+}
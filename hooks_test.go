@@ -0,0 +1,57 @@
+package pools
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	var gets, puts [2]atomic.Int64
+	count := func(n *[2]atomic.Int64) func(string) {
+		return func(kind string) {
+			switch kind {
+			case KindBuffer:
+				n[0].Add(1)
+			case KindBuilder:
+				n[1].Add(1)
+			default:
+				t.Errorf("unknown kind %q", kind)
+			}
+		}
+	}
+	OnGet(count(&gets))
+	OnPut(count(&puts))
+
+	PutBuffer(GetBuffer())
+	PutBuilder(GetBuilder())
+	PutBuilder(GetBuilder())
+	expect(t, int64(1), gets[0].Load())
+	expect(t, int64(1), puts[0].Load())
+	expect(t, int64(2), gets[1].Load())
+	expect(t, int64(2), puts[1].Load())
+}
+
+func TestOnEvict(t *testing.T) {
+	defer trim.retainCap.Store(0)
+
+	var got atomic.Pointer[Eviction]
+	OnEvict(func(e Eviction) {
+		got.Store(&e)
+	})
+
+	trim.retainCap.Store(1)
+	for i := 0; i < trimSampleRate*100 && got.Load() == nil; i++ {
+		b := GetBuffer()
+		b.WriteString("x")
+		PutBuffer(b)
+	}
+	e := got.Load()
+	if e == nil {
+		t.Fatal("OnEvict was never called")
+	}
+	expect(t, KindBuffer, e.Kind)
+	expect(t, EvictOversize, e.Reason)
+	if e.Size < 1 {
+		t.Fatalf("want size > 0, got %d", e.Size)
+	}
+}
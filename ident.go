@@ -0,0 +1,47 @@
+package pools
+
+import (
+	"errors"
+	"strings"
+)
+
+// WriteIdentifier writes name as a quoted identifier in w's Dialect: "name"
+// for Postgres, Oracle, and Athena, `name` for MySQL and ClickHouse, and
+// [name] for SQL Server. Closing quote characters inside name are doubled. An
+// error is returned, and nothing is written, if name is empty or contains a
+// NUL byte.
+//
+// Quoted identifiers are case sensitive in most databases, so name should be
+// spelled exactly as the object was created.
+func (w *Buffer) WriteIdentifier(name string) error {
+	if name == "" {
+		return errors.New("pools: empty identifier")
+	}
+	if strings.IndexByte(name, 0) >= 0 {
+		return errors.New("pools: identifier contains NUL")
+	}
+
+	var lq, rq byte
+	switch w.dialect {
+	case MySQL, ClickHouse:
+		lq, rq = '`', '`'
+	case SQLServer:
+		lq, rq = '[', ']'
+	default:
+		lq, rq = '"', '"'
+	}
+
+	w.WriteByte(lq)
+	for {
+		i := strings.IndexByte(name, rq)
+		if i < 0 {
+			break
+		}
+		w.WriteString(name[:i+1])
+		w.WriteByte(rq)
+		name = name[i+1:]
+	}
+	w.WriteString(name)
+	w.WriteByte(rq)
+	return nil
+}
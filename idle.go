@@ -0,0 +1,62 @@
+package pools
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// idle releases Buffers that sit in the pool for too long. sync.Pool only
+// frees its contents after two GC cycles, which for a quiet program can be a
+// long time after a burst of traffic.
+var idle struct {
+	ttl      atomic.Int64 // time.Duration; 0 disables the TTL.
+	sweeping atomic.Bool  // a sweep is scheduled.
+}
+
+// SetIdleTTL causes Buffers that have been in the pool, unused, for longer
+// than d to be released. A d <= 0 disables the TTL, which is the default.
+//
+// Idle Buffers are found by a sweep that runs at most once every d while
+// Buffers are being returned to the pool, so a Buffer may stay in the pool
+// for up to twice d.
+func SetIdleTTL(d time.Duration) {
+	idle.ttl.Store(int64(max(d, 0)))
+}
+
+// markIdle records when b was returned to the pool and schedules a sweep if
+// the TTL is enabled.
+func markIdle(b *Buffer) {
+	ttl := time.Duration(idle.ttl.Load())
+	if ttl <= 0 {
+		return
+	}
+	b.idleSince = time.Now()
+	if idle.sweeping.CompareAndSwap(false, true) {
+		time.AfterFunc(ttl, sweepIdle)
+	}
+}
+
+// sweepIdle releases the Buffers that have been idle for longer than the TTL
+// and, if any Buffers are left, schedules another sweep.
+func sweepIdle() {
+	ttl := time.Duration(idle.ttl.Load())
+	if ttl <= 0 {
+		idle.sweeping.Store(false)
+		return
+	}
+	now := time.Now()
+	n := bufferPool.sweep(func(x interface{}) bool {
+		b := x.(*Buffer)
+		if now.Sub(b.idleSince) < ttl {
+			return true
+		}
+		bufferStats.discards.Add(1)
+		evictBuffer(b, EvictIdle)
+		return false
+	})
+	if n > 0 {
+		time.AfterFunc(ttl, sweepIdle)
+	} else {
+		idle.sweeping.Store(false)
+	}
+}
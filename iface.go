@@ -0,0 +1,46 @@
+package pools
+
+import flatbuffers "github.com/google/flatbuffers/go"
+
+// BufferPool is a pool of Buffers. It's implemented by Buffers, which uses
+// GetBuffer and PutBuffer, and by *Pool, and can be implemented by tests to
+// count or fake the Buffers a helper uses.
+type BufferPool interface {
+	// Get returns an empty Buffer.
+	Get() *Buffer
+	// Put returns a Buffer from Get. It must not be used afterward.
+	Put(*Buffer)
+}
+
+// BuilderPool is a pool of flatbuffers Builders. It's implemented by
+// Builders, which uses GetBuilder and PutBuilder.
+type BuilderPool interface {
+	// Get returns an empty Builder.
+	Get() *flatbuffers.Builder
+	// Put returns a Builder from Get. It must not be used afterward.
+	Put(*flatbuffers.Builder)
+}
+
+// BytePool is a pool of byte slices. It's implemented by Slices, which uses
+// GetBytes and PutBytes.
+type BytePool = BufferProvider
+
+var (
+	// Buffers is the BufferPool backed by GetBuffer and PutBuffer.
+	Buffers BufferPool = bufferFuncs{}
+
+	// Builders is the BuilderPool backed by GetBuilder and PutBuilder.
+	Builders BuilderPool = builderFuncs{}
+)
+
+type bufferFuncs struct{}
+
+func (bufferFuncs) Get() *Buffer  { return GetBuffer() }
+func (bufferFuncs) Put(b *Buffer) { PutBuffer(b) }
+
+type builderFuncs struct{}
+
+func (builderFuncs) Get() *flatbuffers.Builder  { return GetBuilder() }
+func (builderFuncs) Put(b *flatbuffers.Builder) { PutBuilder(b) }
+
+var _ BufferPool = (*Pool)(nil)
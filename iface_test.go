@@ -0,0 +1,44 @@
+package pools
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// countingPool is a BufferPool that counts the Buffers checked out of it.
+type countingPool struct {
+	BufferPool
+	gets, puts int
+}
+
+func (p *countingPool) Get() *Buffer {
+	p.gets++
+	return p.BufferPool.Get()
+}
+
+func (p *countingPool) Put(b *Buffer) {
+	p.puts++
+	p.BufferPool.Put(b)
+}
+
+func TestBufferPoolInjection(t *testing.T) {
+	p := &countingPool{BufferPool: NewPinnedPool(4)}
+
+	s := GetScopeFrom(p, Builders)
+	s.Buffer()
+	s.Buffer()
+	PutScope(s)
+	expect(t, 2, p.gets)
+	expect(t, 2, p.puts)
+
+	l := slog.New(SlogHandler(io.Discard, &SlogOptions{Buffers: p}))
+	l.Info("hello")
+	expect(t, 3, p.gets)
+	expect(t, 3, p.puts)
+
+	// Slog handlers use the package's pool by default.
+	l = slog.New(SlogHandler(io.Discard, nil))
+	l.Info("hello")
+	expect(t, 3, p.gets)
+}
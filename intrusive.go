@@ -0,0 +1,49 @@
+package pools
+
+import "sync"
+
+// NewIntrusivePool returns a Pool that retains up to n idle Buffers in a
+// linked list threaded through the Buffers themselves. Get and Put neither
+// box Buffers in interfaces, as a sync.Pool does, nor keep a separate slice
+// of them, so the pool costs no memory of its own beyond a few words. It's
+// meant for allocation-sensitive and embedded programs where those costs
+// are measurable. Like NewPinnedPool its contents survive GC.
+// NewIntrusivePool panics if n <= 0.
+func NewIntrusivePool(n int, opts ...Option) *Pool {
+	if n <= 0 {
+		panic("pools: non-positive size for NewIntrusivePool")
+	}
+	return newPool(opts, func(func() *Buffer) freeList {
+		return &intrusive{max: n}
+	})
+}
+
+type intrusive struct {
+	mu   sync.Mutex
+	head *Buffer
+	n    int
+	max  int
+}
+
+func (l *intrusive) get() *Buffer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.head
+	if b == nil {
+		return nil
+	}
+	l.head, b.next = b.next, nil
+	l.n--
+	return b
+}
+
+func (l *intrusive) put(b *Buffer) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.n == l.max {
+		return false
+	}
+	b.next, l.head = l.head, b
+	l.n++
+	return true
+}
@@ -0,0 +1,78 @@
+package pools
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultCopyBufSize is the default size of the buffers returned by
+// GetCopyBuf. It matches the buffer io.Copy allocates.
+const defaultCopyBufSize = 32 << 10
+
+var copyBufSize atomic.Int64
+
+func init() {
+	copyBufSize.Store(defaultCopyBufSize)
+}
+
+// copyBufPool holds *[]byte rather than []byte so that putting a slice
+// doesn't allocate. The *[]byte themselves are recycled through
+// copyBufHeaders.
+var (
+	copyBufPool    sync.Pool
+	copyBufHeaders = sync.Pool{
+		New: func() interface{} {
+			return new([]byte)
+		},
+	}
+)
+
+// SetCopyBufSize sets the size of the buffers returned by GetCopyBuf and used
+// by Copy. Pooled buffers of the old size are discarded as they're returned.
+// It panics if n <= 0.
+func SetCopyBufSize(n int) {
+	if n <= 0 {
+		panic("pools: SetCopyBufSize called with n <= 0")
+	}
+	copyBufSize.Store(int64(n))
+}
+
+// GetCopyBuf returns a scratch slice from the pool, suitable for
+// io.CopyBuffer, io.ReadFull loops, or reading from a net.Conn. Its length is
+// the size set by SetCopyBufSize, 32KB by default. Its contents are
+// undefined.
+func GetCopyBuf() []byte {
+	size := int(copyBufSize.Load())
+	if h, ok := copyBufPool.Get().(*[]byte); ok {
+		p := *h
+		*h = nil
+		copyBufHeaders.Put(h)
+		if cap(p) == size {
+			return p[:size]
+		}
+	}
+	return make([]byte, size)
+}
+
+// PutCopyBuf returns p, which should have come from GetCopyBuf, to the pool.
+// p must not be used afterward. Slices of a different size than the current
+// copy buffer size are dropped.
+func PutCopyBuf(p []byte) {
+	if cap(p) != int(copyBufSize.Load()) {
+		return
+	}
+	h := copyBufHeaders.Get().(*[]byte)
+	*h = p
+	copyBufPool.Put(h)
+}
+
+// Copy is like io.Copy but uses a buffer from GetCopyBuf instead of
+// allocating one when neither src implements io.WriterTo nor dst implements
+// io.ReaderFrom.
+func Copy(dst io.Writer, src io.Reader) (int64, error) {
+	p := GetCopyBuf()
+	n, err := io.CopyBuffer(dst, src, p)
+	PutCopyBuf(p)
+	return n, err
+}
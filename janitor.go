@@ -0,0 +1,83 @@
+package pools
+
+import (
+	"sync"
+	"time"
+)
+
+// Janitor periodically tends the package's pools. See StartJanitor and
+// StartGCMonitor.
+type Janitor struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// StartJanitor starts a goroutine that, every interval, trims the Buffer pool
+// toward the program's recent working set: the most Buffers checked out at
+// once during the previous interval. Buffers beyond that, and Buffers larger
+// than the learned retention cap, are released.
+//
+// This is useful for long-running programs whose traffic varies a lot over
+// the day, where sync.Pool can otherwise hold on to memory sized for the last
+// peak. Call Stop to end the goroutine. StartJanitor panics if interval <= 0.
+func StartJanitor(interval time.Duration) *Janitor {
+	if interval <= 0 {
+		panic("pools: non-positive interval for StartJanitor")
+	}
+	return startJanitor(interval, func() { shrink() }, nil)
+}
+
+// startJanitor starts a Janitor that calls f every interval and, if it's
+// not nil, stopped before Stop returns.
+func startJanitor(interval time.Duration, f, stopped func()) *Janitor {
+	j := &Janitor{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go j.run(interval, f, stopped)
+	return j
+}
+
+func (j *Janitor) run(interval time.Duration, f, stopped func()) {
+	defer close(j.done)
+	if stopped != nil {
+		defer stopped()
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			f()
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// Stop stops the janitor and waits for it to finish. It's safe to call Stop
+// more than once.
+func (j *Janitor) Stop() {
+	j.once.Do(func() { close(j.stop) })
+	<-j.done
+}
+
+// shrink releases pooled Buffers beyond the recent working set and reports
+// how many it kept.
+func shrink() int {
+	// Start the next interval's peak at the current number of Buffers in
+	// use.
+	want := int(bufferStats.peak.Swap(max(bufferStats.outstanding.Load(), 0)))
+	limit := retainCap()
+	return bufferPool.sweep(func(x interface{}) bool {
+		b := x.(*Buffer)
+		if want > 0 && (limit == 0 || int64(b.Cap()) <= limit) {
+			want--
+			return true
+		}
+		bufferStats.discards.Add(1)
+		evictBuffer(b, EvictShrink)
+		return false
+	})
+}
@@ -0,0 +1,62 @@
+package pools
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// SetRawJSON controls how w is encoded by MarshalJSON and decoded by
+// UnmarshalJSON. By default, like a []byte, w is encoded as a base64 string.
+// In raw mode, w is assumed to hold JSON and is passed through unchanged.
+// Buffers are returned to the pool in the default mode.
+func (w *Buffer) SetRawJSON(raw bool) {
+	w.rawJSON = raw
+}
+
+// MarshalJSON implements json.Marshaler. See SetRawJSON.
+func (w *Buffer) MarshalJSON() ([]byte, error) {
+	if w.rawJSON {
+		if w.Len() == 0 {
+			return []byte("null"), nil
+		}
+		// encoding/json validates and copies the result, so it's safe to
+		// return w's contents directly.
+		return w.Bytes(), nil
+	}
+	p := make([]byte, 0, base64.StdEncoding.EncodedLen(w.Len())+2)
+	p = append(p, '"')
+	p = base64.StdEncoding.AppendEncode(p, w.Bytes())
+	return append(p, '"'), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing w's contents. See
+// SetRawJSON.
+func (w *Buffer) UnmarshalJSON(data []byte) error {
+	w.Reset()
+	if w.rawJSON {
+		w.Write(data)
+		return w.Err()
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("pools: Buffer JSON value must be a base64 string")
+	}
+	data = data[1 : len(data)-1]
+	n := base64.StdEncoding.DecodedLen(len(data))
+	w.Grow(n)
+	p, err := base64.StdEncoding.AppendDecode(w.AvailableBuffer(), data)
+	if err != nil {
+		return err
+	}
+	w.Write(p)
+	return w.Err()
+}
+
+var (
+	_ json.Marshaler   = (*Buffer)(nil)
+	_ json.Unmarshaler = (*Buffer)(nil)
+)
@@ -0,0 +1,67 @@
+package pools
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+)
+
+func TestBuffer_JSON(t *testing.T) {
+	type payload struct {
+		Data *Buffer `json:"data"`
+	}
+
+	b := GetBuffer()
+	defer PutBuffer(b)
+	b.WriteString("hi")
+	out, err := json.Marshal(payload{b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, `{"data":"aGk="}`, string(out))
+
+	in := payload{GetBuffer()}
+	defer PutBuffer(in.Data)
+	if err := json.Unmarshal(out, &in); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "hi", in.Data.String())
+}
+
+func TestBuffer_RawJSON(t *testing.T) {
+	b := GetBuffer()
+	defer PutBuffer(b)
+	b.SetRawJSON(true)
+	b.WriteString(`{"a": [1, 2]}`)
+	out, err := json.Marshal(map[string]*Buffer{"x": b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, `{"x":{"a":[1,2]}}`, string(out))
+
+	if err := json.Unmarshal([]byte(`{"b": true}`), b); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, `{"b": true}`, b.String())
+}
+
+func TestBuffer_Gob(t *testing.T) {
+	type payload struct {
+		Data *Buffer
+	}
+
+	b := GetBuffer()
+	defer PutBuffer(b)
+	b.WriteString("hello, world")
+
+	var enc bytes.Buffer
+	if err := gob.NewEncoder(&enc).Encode(payload{b}); err != nil {
+		t.Fatal(err)
+	}
+	var out payload
+	if err := gob.NewDecoder(&enc).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "hello, world", out.Data.String())
+}
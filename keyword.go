@@ -0,0 +1,45 @@
+package pools
+
+// WriteValues is like WriteGroups but writes the VALUES keyword before the
+// groups. A space is written first unless w is empty or already ends with
+// whitespace or '(', so callers don't need to account for the leading space
+// WriteGroups writes.
+//
+//	w.WriteString("INSERT INTO t (a, b)")
+//	w.WriteValues(1, 2, 2) // INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4)
+func (w *Buffer) WriteValues(offset, groupLen, groups int, prefix ...int) error {
+	return w.writeKeywordGroups("VALUES", offset, groupLen, groups, prefix)
+}
+
+// WriteIn writes the IN keyword followed by a single group of n placeholders
+// starting at offset. Spacing is handled as in WriteValues.
+//
+//	w.WriteString("SELECT * FROM t WHERE id")
+//	w.WriteIn(1, 3) // SELECT * FROM t WHERE id IN ($1, $2, $3)
+func (w *Buffer) WriteIn(offset, n int) error {
+	return w.writeKeywordGroups("IN", offset, n, 1, nil)
+}
+
+func (w *Buffer) writeKeywordGroups(kw string, offset, groupLen, groups int, prefix []int) error {
+	mark := w.Len()
+	w.writeSpace()
+	w.WriteString(kw)
+	if err := w.WriteGroups(offset, groupLen, groups, prefix...); err != nil {
+		w.Truncate(mark)
+		return err
+	}
+	return nil
+}
+
+// writeSpace writes a space unless w is empty or ends with whitespace or an
+// opening parenthesis.
+func (w *Buffer) writeSpace() {
+	if w.Len() == 0 {
+		return
+	}
+	switch w.Bytes()[w.Len()-1] {
+	case ' ', '\t', '\n', '\r', '(':
+		return
+	}
+	w.WriteByte(' ')
+}
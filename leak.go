@@ -0,0 +1,186 @@
+package pools
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// leaks records where each checked-out object was acquired.
+var leaks struct {
+	enabled atomic.Bool
+	mu      sync.Mutex
+	live    map[interface{}][]uintptr // object -> stack of the caller of Get.
+}
+
+// outstandingProfile is a pprof profile of the objects checked out of the
+// package's pools, recorded while TrackLeaks is on.
+var outstandingProfile = pprof.NewProfile("pools.outstanding")
+
+// TrackLeaks turns on, or off, recording where each Buffer and Builder is
+// checked out.
+//
+// While on, the stack that acquired each checked-out object is recorded in
+// the "pools.outstanding" pprof profile, so leaks can be found with go tool
+// pprof like goroutine leaks are. In debug builds (see the poolsdebug build
+// tag), Report also groups the objects that were never returned by
+// acquisition site, and prints the whole acquisition stack for sites that
+// leaked Builders. Recording costs a stack walk on every Get and Put.
+//
+// Go has no exit hooks, so to report leaks at exit, end main with
+//
+//	defer pools.Report(os.Stderr)
+func TrackLeaks(enabled bool) {
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+	leaks.enabled.Store(enabled)
+	leaks.live = nil
+}
+
+// trackGet records that x was acquired by the caller of the exported Get
+// function that called trackGet.
+func trackGet(x interface{}) {
+	if debug && callers.enabled.Load() {
+		countCaller(x, false)
+	}
+	if !leaks.enabled.Load() {
+		return
+	}
+	// Add panics on duplicates, which a double Put could cause.
+	outstandingProfile.Remove(x)
+	outstandingProfile.Add(x, 2)
+	if !debug {
+		return
+	}
+	// Builders are large enough that leaking one is costly, so record the
+	// whole stack to make it easier to find where it should be returned.
+	depth := 1
+	if _, ok := x.(*flatbuffers.Builder); ok {
+		depth = maxLeakDepth
+	}
+	pc := make([]uintptr, depth)
+	pc = pc[:runtime.Callers(3, pc)]
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+	if leaks.live == nil {
+		leaks.live = make(map[interface{}][]uintptr)
+	}
+	leaks.live[x] = pc
+}
+
+// maxLeakDepth is the number of frames recorded for leaked Builders.
+const maxLeakDepth = 32
+
+// trackPut records that x was returned.
+func trackPut(x interface{}) {
+	if debug && callers.enabled.Load() {
+		countCaller(x, true)
+	}
+	if !leaks.enabled.Load() {
+		return
+	}
+	outstandingProfile.Remove(x)
+	if !debug {
+		return
+	}
+	leaks.mu.Lock()
+	defer leaks.mu.Unlock()
+	delete(leaks.live, x)
+}
+
+// Report writes a summary of the Buffers and Builders checked out of the
+// package's pools and never returned. If TrackLeaks is on in a debug build,
+// the objects are grouped by where they were acquired, most frequent first.
+func Report(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "pools: %d Buffers and %d Builders checked out\n",
+		max(bufferStats.outstanding.Load(), 0),
+		max(builderStats.outstanding.Load(), 0))
+	if err != nil {
+		return err
+	}
+	for _, s := range leakSites() {
+		_, err := fmt.Fprintf(w, "\t%d Buffers, %d Builders from %s (%s:%d)\n",
+			s.Buffers, s.Builders, s.Function, s.File, s.Line)
+		if err != nil {
+			return err
+		}
+		for _, f := range s.Stack {
+			if _, err := fmt.Fprintf(w, "\t\t%s\n", f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// leakSite is a place where objects that are still checked out were
+// acquired.
+type leakSite struct {
+	Function string
+	File     string
+	Line     int
+	Buffers  int
+	Builders int
+
+	// Stack is the full acquisition stack of one of the site's Builders.
+	Stack []string `json:",omitempty"`
+}
+
+// leakSites groups the objects recorded by TrackLeaks by where they were
+// acquired, most frequent first.
+func leakSites() []leakSite {
+	type count struct {
+		stack             []uintptr
+		buffers, builders int
+	}
+	var counts []count
+	leaks.mu.Lock()
+	idx := make(map[uintptr]int)
+	for x, stack := range leaks.live {
+		if len(stack) == 0 {
+			continue
+		}
+		i, ok := idx[stack[0]]
+		if !ok {
+			i = len(counts)
+			idx[stack[0]] = i
+			counts = append(counts, count{stack: stack[:1]})
+		}
+		if _, ok := x.(*Buffer); ok {
+			counts[i].buffers++
+		} else {
+			counts[i].builders++
+			counts[i].stack = stack
+		}
+	}
+	leaks.mu.Unlock()
+
+	slices.SortFunc(counts, func(a, b count) int {
+		return cmp.Compare(b.buffers+b.builders, a.buffers+a.builders)
+	})
+	sites := make([]leakSite, len(counts))
+	for i, c := range counts {
+		frames := runtime.CallersFrames(c.stack)
+		f, more := frames.Next()
+		sites[i] = leakSite{
+			Function: f.Function,
+			File:     f.File,
+			Line:     f.Line,
+			Buffers:  c.buffers,
+			Builders: c.builders,
+		}
+		for more && c.builders > 0 {
+			f, more = frames.Next()
+			sites[i].Stack = append(sites[i].Stack,
+				fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line))
+		}
+	}
+	return sites
+}
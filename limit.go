@@ -0,0 +1,179 @@
+package pools
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// ErrLimit is returned by writes that would grow a Buffer past its limit.
+var ErrLimit = errors.New("pools: Buffer limit exceeded")
+
+// SetLimit caps the number of bytes w may hold at n. Writes that would exceed
+// the limit write nothing and return ErrLimit, and Grow never reserves space
+// beyond it. Helpers without an error result, like WriteInt, report rejected
+// writes through Err. A limit <= 0 removes the cap. Buffers are returned to
+// the pool without a limit.
+func (w *Buffer) SetLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+	w.limit = n
+}
+
+// Err returns ErrLimit if a write was rejected because of w's limit since w
+// was last Reset, and nil otherwise.
+func (w *Buffer) Err() error {
+	if w.exceeded {
+		return ErrLimit
+	}
+	return nil
+}
+
+// Reset resets w to be empty, unfreezes it, and clears any error reported by
+// Err, but it retains the underlying storage, limit, and Dialect.
+func (w *Buffer) Reset() {
+	w.exceeded = false
+	w.frozen = false
+	w.Buffer.Reset()
+}
+
+// fits reports whether n more bytes can be written to w, recording a failure
+// if not.
+func (w *Buffer) fits(n int) bool {
+	w.checkFrozen()
+	if w.limit == 0 || w.Len()+n <= w.limit {
+		return true
+	}
+	w.exceeded = true
+	w.rejects++
+	return false
+}
+
+// limited returns ErrLimit, after truncating w to mark, if a write was
+// rejected because of w's limit since w.rejects was rejects. Writers of
+// several pieces use it so that their output is all or nothing.
+func (w *Buffer) limited(mark int, rejects uint32) error {
+	if w.rejects == rejects {
+		return nil
+	}
+	w.Truncate(mark)
+	return ErrLimit
+}
+
+// Write appends p to w, subject to w's limit.
+func (w *Buffer) Write(p []byte) (int, error) {
+	if !w.fits(len(p)) {
+		return 0, ErrLimit
+	}
+	return w.Buffer.Write(p)
+}
+
+// WriteString appends s to w, subject to w's limit.
+func (w *Buffer) WriteString(s string) (int, error) {
+	if !w.fits(len(s)) {
+		return 0, ErrLimit
+	}
+	return w.Buffer.WriteString(s)
+}
+
+// WriteByte appends c to w, subject to w's limit.
+func (w *Buffer) WriteByte(c byte) error {
+	if !w.fits(1) {
+		return ErrLimit
+	}
+	return w.Buffer.WriteByte(c)
+}
+
+// WriteRune appends the UTF-8 encoding of r to w, subject to w's limit.
+func (w *Buffer) WriteRune(r rune) (int, error) {
+	if !w.fits(utf8.RuneLen(r)) {
+		return 0, ErrLimit
+	}
+	return w.Buffer.WriteRune(r)
+}
+
+// Grow grows w's capacity to guarantee space for n more bytes, but never
+// beyond w's limit.
+func (w *Buffer) Grow(n int) {
+	w.checkFrozen()
+	if w.limit > 0 && w.Len()+n > w.limit {
+		n = max(w.limit-w.Len(), 0)
+	}
+	w.Buffer.Grow(n)
+}
+
+// ReadFrom reads data from r until EOF and appends it to w. If w has a limit
+// and r holds more data than fits, the data read so far is kept and ErrLimit
+// is returned.
+func (w *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	w.checkFrozen()
+	if w.limit == 0 {
+		return w.Buffer.ReadFrom(r)
+	}
+	start := w.Len()
+	room := int64(max(w.limit-start, 0))
+	n, err := w.Buffer.ReadFrom(io.LimitReader(r, room+1))
+	if n > room {
+		w.Truncate(start + int(room))
+		w.exceeded = true
+		w.rejects++
+		return room, ErrLimit
+	}
+	return n, err
+}
+
+// ReadFromN is like ReadFrom but reads at most max bytes from r. more reports
+// whether r had data beyond max; detecting it consumes one extra byte from r,
+// which is discarded. Reaching max is not an error. If w has a limit smaller
+// than max, reaching the limit with data remaining returns ErrLimit.
+func (w *Buffer) ReadFromN(r io.Reader, max int64) (n int64, more bool, err error) {
+	w.checkFrozen()
+	var limitErr error
+	if w.limit > 0 {
+		room := int64(w.limit - w.Len())
+		if room < 0 {
+			room = 0 // the limit was set below Len.
+		}
+		if room < max {
+			max, limitErr = room, ErrLimit
+		}
+	}
+	for n < max {
+		w.Grow(int(min(max-n, bytes.MinRead)))
+		p := w.AvailableBuffer()
+		p = p[:min(int64(cap(p)), max-n)]
+		m, err := r.Read(p)
+		if m < 0 {
+			panic("pools: reader returned negative count from Read")
+		}
+		w.Buffer.Write(p[:m])
+		n += int64(m)
+		if err == io.EOF {
+			return n, false, nil
+		}
+		if err != nil {
+			return n, false, err
+		}
+	}
+
+	// Probe for more data.
+	var b [1]byte
+	for {
+		m, err := r.Read(b[:])
+		if m > 0 {
+			if limitErr != nil {
+				w.exceeded = true
+				w.rejects++
+			}
+			return n, true, limitErr
+		}
+		if err == io.EOF {
+			return n, false, nil
+		}
+		if err != nil {
+			return n, false, err
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package pools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuffer_SetLimit(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.SetLimit(8)
+
+	if _, err := w.WriteString("12345"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString("6789"); err != ErrLimit {
+		t.Fatalf("want ErrLimit, got %v", err)
+	}
+	expect(t, "12345", w.String())
+	expect(t, ErrLimit, w.Err())
+
+	w.WriteInt(678)
+	expect(t, "12345678", w.String())
+	if err := w.WriteByte('9'); err != ErrLimit {
+		t.Fatalf("want ErrLimit, got %v", err)
+	}
+
+	w.Reset()
+	expect(t, nil, w.Err())
+	n, err := w.ReadFrom(strings.NewReader("abcdefghij"))
+	expect(t, ErrLimit, err)
+	expect(t, int64(8), n)
+	expect(t, "abcdefgh", w.String())
+}
+
+func TestBuffer_SetLimitGrow(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.SetLimit(16)
+	w.Grow(1 << 20)
+	if w.Cap() >= 1<<20 {
+		t.Fatalf("Grow exceeded the limit: cap %d", w.Cap())
+	}
+}
+
+func TestBuffer_SetLimitBelowLen(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.WriteString("0123456789")
+	w.SetLimit(4)
+	w.Grow(100) // mustn't panic.
+
+	n, err := w.ReadFrom(strings.NewReader("abc"))
+	expect(t, ErrLimit, err)
+	expect(t, int64(0), n)
+	expect(t, "0123456789", w.String())
+
+	n, _, err = w.ReadFromN(strings.NewReader("abc"), 2)
+	expect(t, int64(0), n)
+	expect(t, ErrLimit, err)
+}
+
+func TestBuffer_ReadFromN(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+
+	n, more, err := w.ReadFromN(strings.NewReader("abcdef"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, int64(4), n)
+	expect(t, true, more)
+	expect(t, "abcd", w.String())
+
+	w.Reset()
+	n, more, err = w.ReadFromN(strings.NewReader("abcd"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, int64(4), n)
+	expect(t, false, more)
+
+	w.Reset()
+	w.SetLimit(2)
+	_, more, err = w.ReadFromN(strings.NewReader("abcd"), 4)
+	expect(t, ErrLimit, err)
+	expect(t, true, more)
+	expect(t, "ab", w.String())
+}
+
+func TestMaxOutstanding(t *testing.T) {
+	defer SetMaxOutstanding(0)
+	n := int(bufferStats.outstanding.Load())
+	if n < 0 {
+		t.Skip("more Buffers returned than taken")
+	}
+	SetMaxOutstanding(n + 1)
+
+	b := GetBuffer()
+	func() {
+		defer func() {
+			expect(t, ErrOutstanding, recover())
+		}()
+		GetBuffer()
+	}()
+	_, err := TryGetBuffer()
+	expect(t, ErrOutstanding, err)
+	PutBuffer(b)
+	b, err = TryGetBuffer()
+	expect(t, nil, err)
+	PutBuffer(b)
+}
+
+func TestBuffer_SetLimitGroups(t *testing.T) {
+	var w Buffer
+	w.WriteString("x")
+	w.SetLimit(30)
+	expect(t, ErrLimit, w.WriteGroups(7001, 6, 3))
+	expect(t, ErrLimit, w.WriteInterval(7001, 7010, 3))
+	expect(t, ErrLimit, w.WriteIntervalStep(7001, 7010, 1, 3))
+	expect(t, "x", w.String())
+
+	// Nothing from the rejected writes may have been cached for their shapes;
+	// verification panics if the output doesn't match the arguments.
+	defer SetVerify(false)
+	SetVerify(true)
+	var u Buffer
+	expect(t, nil, u.WriteGroups(7001, 6, 3))
+	expect(t, nil, u.WriteInterval(7001, 7010, 3))
+	expect(t, nil, u.WriteIntervalStep(7001, 7010, 1, 3))
+}
+
+func TestTryGetBuilder(t *testing.T) {
+	defer SetMaxOutstanding(0)
+	n := int(builderStats.outstanding.Load())
+	if n < 0 {
+		t.Skip("more Builders returned than taken")
+	}
+	SetMaxOutstanding(n + 1)
+
+	b, err := TryGetBuilder()
+	expect(t, nil, err)
+	_, err = TryGetBuilder()
+	expect(t, ErrOutstanding, err)
+	PutBuilder(b)
+}
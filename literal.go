@@ -0,0 +1,267 @@
+package pools
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetLiterals switches w into literal mode. While args is non-nil,
+// WriteGroups and WriteInterval write the SQL literal for args[n-1] wherever
+// they would have written placeholder n, quoted and escaped according to w's
+// Dialect. This is meant for systems that don't support bind parameters,
+// like some analytics databases. Calling SetLiterals(nil) returns w to
+// writing placeholders.
+//
+// For dialects whose placeholders aren't numbered, like MySQL, the values are
+// still selected by the index the placeholder would have had.
+func (w *Buffer) SetLiterals(args []interface{}) {
+	w.literals = args
+}
+
+// writeLiteralAt writes the literal for placeholder n.
+func (w *Buffer) writeLiteralAt(n int) error {
+	if n < 1 || n > len(w.literals) {
+		return fmt.Errorf("pools: no literal for placeholder %d", n)
+	}
+	return w.WriteLiteral(w.literals[n-1])
+}
+
+// writeLiteralGroup is writeGroup in literal mode.
+func (w *Buffer) writeLiteralGroup(prefix []int, offset, groupLen int) error {
+	w.WriteString(" (")
+	for _, v := range prefix {
+		if err := w.writeLiteralAt(v); err != nil {
+			return err
+		}
+		w.WriteString(", ")
+	}
+	for i := 0; i < groupLen; i++ {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		if err := w.writeLiteralAt(offset + i); err != nil {
+			return err
+		}
+	}
+	w.WriteByte(')')
+	return nil
+}
+
+// writeLiteralGroups is WriteGroups in literal mode. Nothing is written if an
+// error is returned.
+func (w *Buffer) writeLiteralGroups(offset, groupLen, groups int, prefix []int) error {
+	mark := w.Len()
+	for i := 0; i < groups; i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := w.writeLiteralGroup(prefix, offset, groupLen); err != nil {
+			w.Truncate(mark)
+			return err
+		}
+		offset += groupLen
+	}
+	return nil
+}
+
+// writeLiteralInterval is WriteInterval in literal mode. Nothing is written if
+// an error is returned.
+func (w *Buffer) writeLiteralInterval(start, end, num int) error {
+	mark := w.Len()
+	for i := 0; i < num; i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := w.writeLiteralGroup(nil, start, end-start+1); err != nil {
+			w.Truncate(mark)
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteLiteral writes v as a SQL literal in w's Dialect. nil, nil pointers,
+// and invalid sql.Null* values are written as NULL. Strings are quoted and
+// escaped, []byte is written as a binary literal, and time.Time as a quoted
+// timestamp. Other driver.Valuers are written using the value they return.
+// Strings containing NUL bytes, NaN and infinite floats, and values of other
+// types are rejected with an error.
+func (w *Buffer) WriteLiteral(v interface{}) error {
+	v, err := nullable(v)
+	if err != nil {
+		return err
+	}
+	d := w.dialect
+	switch v := v.(type) {
+	case nil:
+		w.WriteString("NULL")
+	case string:
+		return w.writeQuoted(v)
+	case []byte:
+		if v == nil {
+			w.WriteString("NULL")
+			break
+		}
+		w.writeBinaryLiteral(v)
+	case bool:
+		switch {
+		case d == SQLServer && v:
+			w.WriteByte('1')
+		case d == SQLServer:
+			w.WriteByte('0')
+		case v:
+			w.WriteString("TRUE")
+		default:
+			w.WriteString("FALSE")
+		}
+	case int:
+		w.WriteInt(v)
+	case int8:
+		w.WriteInt64(int64(v))
+	case int16:
+		w.WriteInt64(int64(v))
+	case int32:
+		w.WriteInt64(int64(v))
+	case int64:
+		w.WriteInt64(v)
+	case uint:
+		w.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		w.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		w.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		w.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		w.WriteString(strconv.FormatUint(v, 10))
+	case float32:
+		return w.writeFloat(float64(v), 32)
+	case float64:
+		return w.writeFloat(v, 64)
+	case time.Time:
+		w.writeTimeLiteral(v)
+	default:
+		return fmt.Errorf("pools: unsupported literal type %T", v)
+	}
+	return nil
+}
+
+func (w *Buffer) writeFloat(f float64, bits int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errors.New("pools: NaN and infinite floats have no SQL literal")
+	}
+	w.WriteString(strconv.FormatFloat(f, 'g', -1, bits))
+	return nil
+}
+
+// mysqlEscapes and clickHouseEscapes escape string literals for dialects that
+// treat backslash as an escape character. MySQL's escapes assume the
+// NO_BACKSLASH_ESCAPES SQL mode is off.
+var (
+	mysqlEscapes = strings.NewReplacer(
+		`\`, `\\`,
+		`'`, `\'`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\x1a", `\Z`,
+	)
+	clickHouseEscapes = strings.NewReplacer(
+		`\`, `\\`,
+		`'`, `\'`,
+		"\n", `\n`,
+		"\r", `\r`,
+	)
+)
+
+// writeQuoted writes s as a quoted string literal.
+func (w *Buffer) writeQuoted(s string) error {
+	if strings.IndexByte(s, 0) >= 0 {
+		return errors.New("pools: string literal contains NUL")
+	}
+	switch w.dialect {
+	case MySQL:
+		w.WriteByte('\'')
+		mysqlEscapes.WriteString(w, s)
+		w.WriteByte('\'')
+		return nil
+	case ClickHouse:
+		w.WriteByte('\'')
+		clickHouseEscapes.WriteString(w, s)
+		w.WriteByte('\'')
+		return nil
+	case SQLServer:
+		// N'' so non-ASCII text survives non-Unicode collations.
+		w.WriteByte('N')
+	case Postgres:
+		// Use an escape string when s contains a backslash so the result
+		// doesn't depend on standard_conforming_strings.
+		if strings.IndexByte(s, '\\') >= 0 {
+			w.WriteByte('E')
+			w.WriteByte('\'')
+			for i := 0; i < len(s); i++ {
+				switch s[i] {
+				case '\\', '\'':
+					w.WriteByte(s[i])
+				}
+				w.WriteByte(s[i])
+			}
+			w.WriteByte('\'')
+			return nil
+		}
+	}
+	w.WriteByte('\'')
+	for {
+		i := strings.IndexByte(s, '\'')
+		if i < 0 {
+			break
+		}
+		w.WriteString(s[:i+1])
+		w.WriteByte('\'')
+		s = s[i+1:]
+	}
+	w.WriteString(s)
+	w.WriteByte('\'')
+	return nil
+}
+
+func (w *Buffer) writeBinaryLiteral(p []byte) {
+	var pre, post string
+	switch w.dialect {
+	case Postgres:
+		// An escape string, like writeQuoted's, so the result doesn't depend
+		// on standard_conforming_strings.
+		pre, post = `E'\\x`, `'::bytea`
+	case SQLServer:
+		pre = "0x"
+	case Oracle:
+		pre, post = "HEXTORAW('", "')"
+	case ClickHouse:
+		pre, post = "unhex('", "')"
+	default:
+		pre, post = "X'", "'"
+	}
+	w.WriteString(pre)
+	w.Grow(hex.EncodedLen(len(p)) + len(post))
+	w.Write(hex.AppendEncode(w.AvailableBuffer(), p))
+	w.WriteString(post)
+}
+
+func (w *Buffer) writeTimeLiteral(t time.Time) {
+	switch w.dialect {
+	case Postgres:
+		w.WriteByte('\'')
+		w.Write(t.AppendFormat(w.AvailableBuffer(), "2006-01-02 15:04:05.999999Z07:00"))
+		w.WriteByte('\'')
+		return
+	case Athena, Oracle:
+		w.WriteString("TIMESTAMP ")
+	}
+	w.WriteByte('\'')
+	w.Write(t.UTC().AppendFormat(w.AvailableBuffer(), "2006-01-02 15:04:05.999999"))
+	w.WriteByte('\'')
+}
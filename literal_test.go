@@ -0,0 +1,106 @@
+package pools
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestBuffer_WriteGroupsLiterals(t *testing.T) {
+	args := []interface{}{1, "O'Brien", nil, 2, `a\b`, true}
+	for _, tt := range []struct {
+		d    Dialect
+		want string
+	}{
+		{Postgres, ` (1, 'O''Brien', NULL), (2, E'a\\b', TRUE)`},
+		{MySQL, ` (1, 'O\'Brien', NULL), (2, 'a\\b', TRUE)`},
+		{SQLServer, ` (1, N'O''Brien', NULL), (2, N'a\b', 1)`},
+		{Athena, ` (1, 'O''Brien', NULL), (2, 'a\b', TRUE)`},
+	} {
+		w := GetBuffer()
+		w.SetDialect(tt.d)
+		w.SetLiterals(args)
+		if err := w.WriteGroups(1, 3, 2); err != nil {
+			t.Fatal(err)
+		}
+		expect(t, tt.want, w.String())
+		PutBuffer(w)
+	}
+}
+
+func TestBuffer_WriteIntervalLiterals(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.SetLiterals([]interface{}{[]byte{0xca, 0xfe}, 1.5})
+	if err := w.WriteInterval(1, 2, 2); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, ` (E'\\xcafe'::bytea, 1.5), (E'\\xcafe'::bytea, 1.5)`, w.String())
+}
+
+func TestBuffer_WriteGroupsLiteralsInvalid(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.SetLiterals([]interface{}{"a\x00b"})
+	if err := w.WriteGroups(1, 1, 1); err == nil {
+		t.Fatal("expected an error for NUL")
+	}
+	w.SetLiterals([]interface{}{1})
+	if err := w.WriteGroups(1, 2, 1); err == nil {
+		t.Fatal("expected an error for a missing literal")
+	}
+	expect(t, 0, w.Len())
+}
+
+func TestBuffer_WriteIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		d    Dialect
+		want string
+	}{
+		{Postgres, `"we""ird]` + "`" + `"`},
+		{MySQL, "`we\"ird]```"},
+		{SQLServer, `[we"ird]]` + "`]"},
+	} {
+		w := GetBuffer()
+		w.SetDialect(tt.d)
+		if err := w.WriteIdentifier("we\"ird]`"); err != nil {
+			t.Fatal(err)
+		}
+		expect(t, tt.want, w.String())
+		PutBuffer(w)
+	}
+
+	w := GetBuffer()
+	defer PutBuffer(w)
+	if err := w.WriteIdentifier("a\x00"); err == nil {
+		t.Fatal("expected an error for NUL")
+	}
+	if err := w.WriteIdentifier(""); err == nil {
+		t.Fatal("expected an error for an empty identifier")
+	}
+	expect(t, 0, w.Len())
+}
+
+func TestBuffer_WriteNullable(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	w.WriteNullableString(sql.NullString{String: "it's", Valid: true})
+	w.WriteByte(' ')
+	w.WriteNullableString(sql.NullString{})
+	w.WriteByte(' ')
+	w.WriteNullableInt(sql.NullInt64{Int64: 42, Valid: true})
+	w.WriteByte(' ')
+	w.WriteNullableTime(sql.NullTime{})
+	expect(t, `'it''s' NULL 42 NULL`, w.String())
+}
+
+func TestBuffer_WriteLiteralPointers(t *testing.T) {
+	w := GetBuffer()
+	defer PutBuffer(w)
+	s := "x"
+	var np *int64
+	w.SetLiterals([]interface{}{&s, np, sql.NullInt64{Int64: 3, Valid: true}})
+	if err := w.WriteGroups(1, 3, 1); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, ` ('x', NULL, 3)`, w.String())
+}
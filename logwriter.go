@@ -0,0 +1,74 @@
+package pools
+
+import (
+	"io"
+	"sync"
+)
+
+// LogWriter batches log entries in a pooled Buffer and writes them to an
+// underlying io.Writer in large chunks. It satisfies zapcore.WriteSyncer and
+// zerolog's io.Writer without either package being imported: both write one
+// whole entry per call to Write, so entries are never split across writes
+// to the underlying writer.
+//
+// Zap's encoders and zerolog's events keep their own buffer pools, which
+// can't be replaced. A LogWriter copies each entry out of them straight
+// away, so batching draws on this package's Buffers rather than holding on
+// to theirs.
+type LogWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	size int
+	buf  *Buffer // nil when empty.
+}
+
+// NewLogWriter returns a LogWriter that writes to w once it holds size bytes
+// or more, or when Sync is called. Entries of size bytes or more are written
+// straight through.
+func NewLogWriter(w io.Writer, size int) *LogWriter {
+	return &LogWriter{w: w, size: size}
+}
+
+// Write copies the entry p, flushing first if it wouldn't fit.
+func (l *LogWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buf != nil && l.buf.Len()+len(p) > l.size {
+		if err := l.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if len(p) >= l.size {
+		return l.w.Write(p)
+	}
+	if l.buf == nil {
+		l.buf = GetBufferSize(l.size)
+	}
+	return l.buf.Write(p)
+}
+
+// Sync writes any buffered entries and, if the underlying writer has a Sync
+// method, like *os.File, calls it.
+func (l *LogWriter) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.flush(); err != nil {
+		return err
+	}
+	if s, ok := l.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// flush writes the buffered entries and returns the Buffer to the pool, so
+// an idle LogWriter holds no memory.
+func (l *LogWriter) flush() error {
+	if l.buf == nil {
+		return nil
+	}
+	_, err := l.buf.WriteTo(l.w)
+	PutBuffer(l.buf)
+	l.buf = nil
+	return err
+}
@@ -0,0 +1,44 @@
+package pools
+
+import (
+	"strings"
+	"testing"
+)
+
+type syncRecorder struct {
+	strings.Builder
+	writes, syncs int
+}
+
+func (s *syncRecorder) Write(p []byte) (int, error) {
+	s.writes++
+	return s.Builder.Write(p)
+}
+
+func (s *syncRecorder) Sync() error {
+	s.syncs++
+	return nil
+}
+
+func TestLogWriter(t *testing.T) {
+	var rec syncRecorder
+	l := NewLogWriter(&rec, 16)
+	l.Write([]byte("first\n"))
+	l.Write([]byte("second\n"))
+	expect(t, 0, rec.writes)
+
+	// Doesn't fit, so the first two entries are flushed together.
+	l.Write([]byte("third\n"))
+	expect(t, 1, rec.writes)
+	expect(t, "first\nsecond\n", rec.String())
+
+	l.Write([]byte("a long entry that's written through\n"))
+	expect(t, 3, rec.writes)
+
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, 1, rec.syncs)
+	expect(t, 3, rec.writes)
+	expect(t, "first\nsecond\nthird\na long entry that's written through\n", rec.String())
+}
@@ -0,0 +1,189 @@
+package pools
+
+import (
+	"io"
+	"runtime"
+)
+
+// ManualBuffer is a byte buffer whose memory is mapped outside the Go heap,
+// where supported, and released explicitly with Free. The garbage collector
+// neither scans nor counts it, so pipelines holding tens of gigabytes of
+// transient data in ManualBuffers don't inflate GC mark times or trigger
+// collections. On platforms without mmap it falls back to the heap.
+//
+// A ManualBuffer that becomes unreachable without being freed is unmapped by
+// the runtime eventually, but relying on that defeats its purpose. It also
+// means m must stay reachable while a slice returned by Bytes is in use, or
+// the memory may be unmapped under it:
+//
+//	b := m.Bytes()
+//	process(b)
+//	runtime.KeepAlive(m) // m is otherwise dead after the call to Bytes.
+//
+// Calling Free after using the slice has the same effect. A ManualBuffer is
+// not safe for concurrent use.
+type ManualBuffer struct {
+	mem     manualMem
+	r, w    int // read and write offsets.
+	cleanup runtime.Cleanup
+}
+
+type manualMem struct {
+	p      []byte
+	mapped bool
+}
+
+// NewManualBuffer returns an empty ManualBuffer with room for at least n
+// bytes. It grows as needed.
+func NewManualBuffer(n int) *ManualBuffer {
+	m := new(ManualBuffer)
+	m.remap(n)
+	return m
+}
+
+// NewManualBufferE is like NewManualBuffer but returns an error instead of
+// falling back to the heap if the memory can't be mapped. Growing the
+// ManualBuffer later may still fall back to the heap.
+func NewManualBufferE(n int) (*ManualBuffer, error) {
+	p, err := mmap(pageRound(n))
+	if err != nil {
+		return nil, err
+	}
+	m := new(ManualBuffer)
+	m.setMem(manualMem{p: p, mapped: true})
+	return m, nil
+}
+
+// pageRound rounds n up to a positive multiple of the page size.
+func pageRound(n int) int {
+	return max((n+pageSize-1)/pageSize, 1) * pageSize
+}
+
+// remap moves m's unread data into new memory with room for at least n
+// bytes.
+func (m *ManualBuffer) remap(n int) {
+	n = pageRound(n)
+	mem := manualMem{mapped: true}
+	p, err := mmap(n)
+	if err != nil {
+		p, mem.mapped = make([]byte, n), false
+	}
+	mem.p = p
+	m.setMem(mem)
+}
+
+// setMem moves m's unread data into mem.
+func (m *ManualBuffer) setMem(mem manualMem) {
+	p := mem.p
+	old := m.mem
+	m.w = copy(p, old.p[m.r:m.w])
+	m.r = 0
+	m.mem = mem
+	if old.p != nil {
+		m.cleanup.Stop()
+		freeManual(old)
+	}
+	m.cleanup = runtime.AddCleanup(m, freeManual, mem)
+}
+
+func freeManual(mem manualMem) {
+	if mem.mapped {
+		munmap(mem.p)
+	}
+}
+
+func (m *ManualBuffer) check() {
+	if m.mem.p == nil {
+		panic("pools: ManualBuffer used after Free")
+	}
+}
+
+// Free releases m's memory. Neither m nor any slice returned by Bytes may be
+// used afterward.
+func (m *ManualBuffer) Free() {
+	m.check()
+	m.cleanup.Stop()
+	freeManual(m.mem)
+	m.mem = manualMem{}
+	m.r, m.w = 0, 0
+}
+
+// grow makes room for n more bytes.
+func (m *ManualBuffer) grow(n int) {
+	m.check()
+	if m.w+n <= len(m.mem.p) {
+		return
+	}
+	// Slide the unread data down if that frees enough room without
+	// leaving the buffer mostly full.
+	if unread := m.w - m.r; unread+n <= len(m.mem.p)/2 {
+		copy(m.mem.p, m.mem.p[m.r:m.w])
+		m.r, m.w = 0, unread
+		return
+	}
+	m.remap(2*len(m.mem.p) + n)
+}
+
+func (m *ManualBuffer) Write(p []byte) (int, error) {
+	m.grow(len(p))
+	m.w += copy(m.mem.p[m.w:], p)
+	return len(p), nil
+}
+
+func (m *ManualBuffer) WriteString(s string) (int, error) {
+	m.grow(len(s))
+	m.w += copy(m.mem.p[m.w:], s)
+	return len(s), nil
+}
+
+func (m *ManualBuffer) WriteByte(c byte) error {
+	m.grow(1)
+	m.mem.p[m.w] = c
+	m.w++
+	return nil
+}
+
+// Read reads from the unread data, returning io.EOF once there is none.
+func (m *ManualBuffer) Read(p []byte) (int, error) {
+	m.check()
+	if m.r == m.w {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n := copy(p, m.mem.p[m.r:m.w])
+	m.r += n
+	return n, nil
+}
+
+// WriteTo writes the unread data to w.
+func (m *ManualBuffer) WriteTo(w io.Writer) (int64, error) {
+	m.check()
+	n, err := w.Write(m.mem.p[m.r:m.w])
+	m.r += n
+	return int64(n), err
+}
+
+// Bytes returns the unread data. The slice aliases m's memory, so it's only
+// valid until the next write or until m is freed, and only while m is
+// reachable (see ManualBuffer).
+func (m *ManualBuffer) Bytes() []byte {
+	m.check()
+	return m.mem.p[m.r:m.w:m.w]
+}
+
+// Len returns the number of unread bytes.
+func (m *ManualBuffer) Len() int {
+	return m.w - m.r
+}
+
+// Cap returns the size of m's memory.
+func (m *ManualBuffer) Cap() int {
+	return len(m.mem.p)
+}
+
+// Reset discards the unread data but keeps the memory.
+func (m *ManualBuffer) Reset() {
+	m.r, m.w = 0, 0
+}
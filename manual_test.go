@@ -0,0 +1,48 @@
+package pools
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestManualBuffer(t *testing.T) {
+	m := NewManualBuffer(10)
+	expect(t, pageSize, m.Cap())
+	want := bytes.Repeat([]byte("0123456789"), pageSize)
+	m.Write(want[:5])
+	m.Write(want[5:])
+	expect(t, len(want), m.Len())
+
+	p := make([]byte, 10)
+	io.ReadFull(m, p)
+	expect(t, "0123456789", string(p))
+	expect(t, true, bytes.Equal(want[10:], m.Bytes()))
+
+	var b bytes.Buffer
+	m.WriteTo(&b)
+	expect(t, 0, m.Len())
+	if _, err := m.Read(p); err != io.EOF {
+		t.Fatalf("want io.EOF, got %v", err)
+	}
+	m.Free()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want a panic after Free")
+		}
+	}()
+	m.WriteByte('x')
+}
+
+func TestNewManualBufferE(t *testing.T) {
+	m, err := NewManualBufferE(10)
+	if err != nil {
+		t.Skip("mmap unavailable:", err)
+	}
+	defer m.Free()
+	expect(t, true, m.mem.mapped)
+	expect(t, pageSize, m.Cap())
+	m.WriteString("mapped")
+	expect(t, "mapped", string(m.Bytes()))
+}
@@ -0,0 +1,26 @@
+package pools
+
+// Mark is a position in a Buffer, returned by Buffer.Mark.
+type Mark struct {
+	n int
+}
+
+// Mark returns the current end of w's unread data so later writes can be
+// undone with Rollback. For example, to write an optional clause:
+//
+//	m := w.Mark()
+//	w.WriteString(" WHERE ")
+//	if !writeConditions(w) {
+//		w.Rollback(m)
+//	}
+func (w *Buffer) Mark() Mark {
+	return Mark{n: w.Len()}
+}
+
+// Rollback discards everything written to w since m was taken. Marks are
+// relative to the unread data, so w must not be read from in between.
+func (w *Buffer) Rollback(m Mark) {
+	if m.n < w.Len() {
+		w.Truncate(m.n)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build darwin || linux
+
+package pools
+
+import "syscall"
+
+// mlock locks p into RAM, reporting whether it succeeded. Locking fails when
+// RLIMIT_MEMLOCK is exhausted.
+func mlock(p []byte) bool {
+	return syscall.Mlock(p) == nil
+}
+
+func munlock(p []byte) {
+	syscall.Munlock(p)
+}
@@ -0,0 +1,12 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package pools
+
+import "errors"
+
+// mmap always fails on this platform, so callers fall back to the Go heap.
+func mmap(n int) ([]byte, error) {
+	return nil, errors.New("pools: mmap is not supported on this platform")
+}
+
+func munmap(p []byte) {}
@@ -0,0 +1,14 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package pools
+
+import "syscall"
+
+// mmap maps n bytes of anonymous memory outside the Go heap.
+func mmap(n int) ([]byte, error) {
+	return syscall.Mmap(-1, 0, n, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+func munmap(p []byte) {
+	syscall.Munmap(p)
+}
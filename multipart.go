@@ -0,0 +1,50 @@
+package pools
+
+import (
+	"mime/multipart"
+	"sync"
+)
+
+var multipartPool = sync.Pool{
+	New: func() interface{} {
+		return &MultipartWriter{Writer: new(multipart.Writer)}
+	},
+}
+
+// MultipartWriter is a multipart.Writer that writes to a pooled Buffer.
+type MultipartWriter struct {
+	*multipart.Writer
+	buf *Buffer
+}
+
+// GetMultipartWriter returns a pooled MultipartWriter. Each one gets a new
+// random boundary, so bodies built by different MultipartWriters never
+// share one. Return it with PutMultipartWriter.
+func GetMultipartWriter() *MultipartWriter {
+	w := multipartPool.Get().(*MultipartWriter)
+	w.buf = GetBuffer()
+	// multipart.Writer has no Reset, so overwrite the pooled one.
+	*w.Writer = *multipart.NewWriter(w.buf)
+	return w
+}
+
+// PutMultipartWriter returns w and its Buffer to their pools. Slices
+// returned by Bytes may not be used afterward.
+func PutMultipartWriter(w *MultipartWriter) {
+	PutBuffer(w.buf)
+	w.buf = nil
+	*w.Writer = multipart.Writer{}
+	multipartPool.Put(w)
+}
+
+// Bytes returns the body written so far. Call Close first to write the final
+// boundary. The slice is only valid until the next call to a method of w.
+func (w *MultipartWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// Len returns the length of the body written so far, for use as a
+// Content-Length.
+func (w *MultipartWriter) Len() int {
+	return w.buf.Len()
+}
@@ -0,0 +1,38 @@
+package pools
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"testing"
+)
+
+func TestMultipartWriter(t *testing.T) {
+	w := GetMultipartWriter()
+	boundary := w.Boundary()
+	w.WriteField("key", "value")
+	fw, err := w.CreateFormFile("file", "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(fw, "contents")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := multipart.NewReader(bytes.NewReader(w.Bytes()), boundary)
+	f, err := r.ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "value", f.Value["key"][0])
+	expect(t, "a.txt", f.File["file"][0].Filename)
+	PutMultipartWriter(w)
+
+	w = GetMultipartWriter()
+	defer PutMultipartWriter(w)
+	if w.Boundary() == boundary {
+		t.Fatal("boundary was reused")
+	}
+	expect(t, 0, w.Len())
+}
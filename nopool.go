@@ -0,0 +1,18 @@
+package pools
+
+import "sync/atomic"
+
+var noPooling atomic.Bool
+
+// DisablePooling turns off, or back on, the reuse of Buffers, Builders, and
+// GetBytes slices. While off, every Get allocates a new object and every Put
+// resets, poisons in debug builds, and drops the object it's given, so tests
+// and fuzzers hunting aliasing bugs behave deterministically and the race
+// detector never sees memory handed from one goroutine to another by the
+// pool. Bookkeeping such as ReadStats, hooks, and TrackLeaks still works.
+// Pools created with NewPinnedPool and the like are unaffected.
+//
+// It can also be turned on with POOLSDEBUG=nopool=1.
+func DisablePooling(disable bool) {
+	noPooling.Store(disable)
+}
@@ -0,0 +1,37 @@
+package pools
+
+import "testing"
+
+func TestDisablePooling(t *testing.T) {
+	DisablePooling(true)
+	defer DisablePooling(false)
+
+	for range 10 {
+		a := GetBuffer()
+		PutBuffer(a)
+		b := GetBuffer()
+		PutBuffer(b)
+		if a == b {
+			t.Fatal("want a new Buffer every time")
+		}
+
+		f := GetBuilder()
+		PutBuilder(f)
+		g := GetBuilder()
+		PutBuilder(g)
+		if f == g {
+			t.Fatal("want a new Builder every time")
+		}
+
+		p := GetBytes(100)
+		PutBytes(p)
+		if q := GetBytes(100); &q[0] == &p[0] {
+			t.Fatal("want a new slice every time")
+		}
+
+		// Slab slices are rounded up to their class.
+		if ps := GetSlices(2, 100); cap(ps[0]) != 100 || cap(ps[1]) != 100 {
+			t.Fatal("want slices allocated on their own")
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package pools
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"time"
+)
+
+// WriteNullableString writes NULL if s is not valid and s.String as a quoted
+// literal in w's Dialect otherwise.
+func (w *Buffer) WriteNullableString(s sql.NullString) error {
+	if !s.Valid {
+		w.WriteString("NULL")
+		return nil
+	}
+	return w.writeQuoted(s.String)
+}
+
+// WriteNullableInt writes NULL if i is not valid and i.Int64 otherwise.
+func (w *Buffer) WriteNullableInt(i sql.NullInt64) {
+	if !i.Valid {
+		w.WriteString("NULL")
+		return
+	}
+	w.WriteInt64(i.Int64)
+}
+
+// WriteNullableTime writes NULL if t is not valid and t.Time as a timestamp
+// literal in w's Dialect otherwise.
+func (w *Buffer) WriteNullableTime(t sql.NullTime) {
+	if !t.Valid {
+		w.WriteString("NULL")
+		return
+	}
+	w.writeTimeLiteral(t.Time)
+}
+
+// nullable resolves pointers and driver.Valuers (like sql.NullString) to the
+// value they represent so the literal and COPY writers only need to handle
+// plain values. Nil pointers and invalid sql.Null* values become nil.
+func nullable(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case nil, string, []byte, bool, int, int64, float64, time.Time:
+		// Fast path for the common cases.
+		return v, nil
+	case driver.Valuer:
+		if rv := reflect.ValueOf(x); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, nil
+		}
+		return x.Value()
+	case *string:
+		if x == nil {
+			return nil, nil
+		}
+		return *x, nil
+	case *[]byte:
+		if x == nil {
+			return nil, nil
+		}
+		return *x, nil
+	case *bool:
+		if x == nil {
+			return nil, nil
+		}
+		return *x, nil
+	case *int:
+		if x == nil {
+			return nil, nil
+		}
+		return *x, nil
+	case *int32:
+		if x == nil {
+			return nil, nil
+		}
+		return *x, nil
+	case *int64:
+		if x == nil {
+			return nil, nil
+		}
+		return *x, nil
+	case *float64:
+		if x == nil {
+			return nil, nil
+		}
+		return *x, nil
+	case *time.Time:
+		if x == nil {
+			return nil, nil
+		}
+		return *x, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,36 @@
+package pools
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrOutstanding is returned by TryGetBuffer and TryGetBuilder, and is the
+// value GetBuffer and GetBuilder panic with, when more objects are checked
+// out than allowed by SetMaxOutstanding.
+var ErrOutstanding = errors.New("pools: too many objects checked out")
+
+var maxOutstanding atomic.Int64
+
+// SetMaxOutstanding limits the number of Buffers, and separately the number
+// of Builders, that can be checked out of the package's pools at once. Once
+// the limit is reached, TryGetBuffer and TryGetBuilder return ErrOutstanding,
+// so callers can shed load, and GetBuffer, GetBuilder, and the functions
+// built on them panic with it, so that a leak or runaway fan-out fails loudly
+// instead of silently growing the heap. Code that must keep running under
+// the limit should use the Try functions. A n <= 0 removes the limit, which
+// is the default.
+func SetMaxOutstanding(n int) {
+	maxOutstanding.Store(int64(max(n, 0)))
+}
+
+// reserveOutstanding adds delta to count and returns the sum, or, if that
+// exceeds the limit, undoes the addition and returns ErrOutstanding.
+func reserveOutstanding(count *atomic.Int64, delta int64) (int64, error) {
+	n := count.Add(delta)
+	if m := maxOutstanding.Load(); m > 0 && n > m {
+		count.Add(-delta)
+		return 0, ErrOutstanding
+	}
+	return n, nil
+}
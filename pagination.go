@@ -0,0 +1,69 @@
+package pools
+
+// WritePagination writes the clause that limits a query to limit rows after
+// skipping offset rows, using the syntax of dialect:
+//
+//	Postgres, MySQL, ClickHouse:  LIMIT 10 OFFSET 20
+//	Athena:                       OFFSET 20 LIMIT 10
+//	SQL Server, Oracle:           OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY
+//
+// Either part is omitted if it's <= 0, and nothing is written if both are,
+// except that MySQL, which has no OFFSET without LIMIT, is given its largest
+// LIMIT. SQL Server only permits OFFSET after an ORDER BY clause. The values
+// are always inlined as integer literals, never as placeholders, regardless
+// of w's literal mode (see SetLiterals); use Fragment.AppendPagination to
+// bind them as arguments instead.
+func (w *Buffer) WritePagination(limit, offset int, dialect Dialect) {
+	writePagination(w, limit, offset, dialect, w.WriteInt)
+}
+
+// AppendPagination is like Buffer.WritePagination but binds limit and offset
+// as arguments.
+func (f *Fragment) AppendPagination(limit, offset int, dialect Dialect) {
+	writePagination(&f.sql, limit, offset, dialect, func(n int) {
+		f.marks = append(f.marks, f.sql.Len())
+		f.args = append(f.args, n)
+	})
+}
+
+// mysqlMaxLimit is the largest LIMIT MySQL accepts, which its documentation
+// recommends for an OFFSET with no limit.
+const mysqlMaxLimit = "18446744073709551615"
+
+func writePagination(w *Buffer, limit, offset int, d Dialect, value func(int)) {
+	switch d {
+	case SQLServer, Oracle:
+		if limit <= 0 && offset <= 0 {
+			return
+		}
+		// FETCH requires OFFSET.
+		w.WriteString(" OFFSET ")
+		value(max(offset, 0))
+		w.WriteString(" ROWS")
+		if limit > 0 {
+			w.WriteString(" FETCH NEXT ")
+			value(limit)
+			w.WriteString(" ROWS ONLY")
+		}
+	case Athena:
+		if offset > 0 {
+			w.WriteString(" OFFSET ")
+			value(offset)
+		}
+		if limit > 0 {
+			w.WriteString(" LIMIT ")
+			value(limit)
+		}
+	default:
+		if limit > 0 {
+			w.WriteString(" LIMIT ")
+			value(limit)
+		} else if offset > 0 && d == MySQL {
+			w.WriteString(" LIMIT " + mysqlMaxLimit)
+		}
+		if offset > 0 {
+			w.WriteString(" OFFSET ")
+			value(offset)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package pools
+
+import "sync"
+
+// NewPinnedPool returns a Pool that retains up to n idle Buffers in a free
+// list. Unlike a sync.Pool, the free list isn't emptied by the GC, which
+// avoids the burst of allocations that follows each GC in programs sensitive
+// to tail latency. The cost is that up to n Buffers stay in memory for as
+// long as the Pool does. NewPinnedPool panics if n <= 0.
+func NewPinnedPool(n int, opts ...Option) *Pool {
+	if n <= 0 {
+		panic("pools: non-positive size for NewPinnedPool")
+	}
+	return newPool(opts, func(func() *Buffer) freeList {
+		return &pinned{free: make([]*Buffer, 0, n)}
+	})
+}
+
+type pinned struct {
+	mu   sync.Mutex
+	free []*Buffer
+}
+
+func (p *pinned) get() *Buffer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.free)
+	if n == 0 {
+		return nil
+	}
+	b := p.free[n-1]
+	p.free[n-1] = nil
+	p.free = p.free[:n-1]
+	return b
+}
+
+func (p *pinned) put(b *Buffer) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.free) == cap(p.free) {
+		return false
+	}
+	p.free = append(p.free, b)
+	return true
+}
@@ -0,0 +1,42 @@
+package pools
+
+import "strconv"
+
+// writePlaceholder writes the nth placeholder in w's Dialect.
+func (w *Buffer) writePlaceholder(n int) {
+	if room := len(w.dialect.prefix()) + maxIntWidth; w.Available() < room {
+		w.Grow(room)
+	}
+	w.Write(w.dialect.appendPlaceholder(w.AvailableBuffer(), n))
+}
+
+// appendPlaceholder appends the nth placeholder in d to dst.
+func (d Dialect) appendPlaceholder(dst []byte, n int) []byte {
+	dst = append(dst, d.prefix()...)
+	if !d.numbered() {
+		return dst
+	}
+	if n < 0 {
+		return strconv.AppendInt(dst, int64(n), 10)
+	}
+	var dec decimal
+	dec.set(uint(n))
+	return append(dst, dec.bytes()...)
+}
+
+// digits returns the number of decimal digits in n.
+func digits(n uint) int {
+	d := 1
+	for ; n >= 10000; n /= 10000 {
+		d += 4
+	}
+	switch {
+	case n >= 1000:
+		return d + 3
+	case n >= 100:
+		return d + 2
+	case n >= 10:
+		return d + 1
+	}
+	return d
+}
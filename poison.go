@@ -0,0 +1,19 @@
+package pools
+
+// poisonByte fills memory returned to the pool in debug builds. Data read
+// from a Buffer or Builder after it was returned, like a slice obtained from
+// Bytes before PutBuffer, shows up as a run of 0xDB instead of plausible,
+// silently corrupted data.
+const poisonByte = 0xDB
+
+// poison overwrites p, up to its capacity, with poisonByte if debug checks
+// are enabled.
+func poison(p []byte) {
+	if !debug {
+		return
+	}
+	p = p[:cap(p)]
+	for i := range p {
+		p[i] = poisonByte
+	}
+}
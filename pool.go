@@ -0,0 +1,331 @@
+package pools
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Pool is a pool of Buffers with a specific backend, for programs that need
+// different behavior than the package-level GetBuffer and PutBuffer, which
+// are backed by a sync.Pool. Pools don't contribute to the package's
+// ReadStats, but do call the OnGet, OnPut, and OnEvict hooks.
+//
+// A Pool is safe for concurrent use.
+type Pool struct {
+	opts    options
+	classes []int      // ascending capacities; {0} without WithClasses.
+	free    []freeList // one per class.
+
+	gets, puts, news, discards atomic.Uint64 // see WithMetrics.
+	rateLimited                atomic.Uint64 // see WithRateLimit.
+	epoch                      atomic.Uint32 // see Invalidate.
+	limit                      *tokenBucket  // nil without WithRateLimit.
+}
+
+// freeList is a Pool's backend.
+type freeList interface {
+	// get returns an idle Buffer, or nil if there are none.
+	get() *Buffer
+	// put adds b to the list and reports whether there was room for it.
+	put(b *Buffer) bool
+}
+
+// Option configures a Pool.
+type Option func(*options)
+
+type options struct {
+	maxSize int
+	metrics bool
+	new     func() *Buffer
+	reset   func(*Buffer)
+	classes []int
+	rate    float64
+	burst   int
+}
+
+// WithMaxSize causes Buffers whose capacity exceeds n to be dropped instead
+// of returned to the Pool.
+func WithMaxSize(n int) Option {
+	return func(o *options) { o.maxSize = n }
+}
+
+// WithMetrics causes the Pool to count Gets, Puts, allocations, and discards.
+// See Pool.ReadStats.
+func WithMetrics() Option {
+	return func(o *options) { o.metrics = true }
+}
+
+// WithNew sets the function the Pool uses to allocate Buffers.
+func WithNew(new func() *Buffer) Option {
+	return func(o *options) { o.new = new }
+}
+
+// WithResetHook sets a function that's called with each Buffer returned to
+// the Pool, after it has been reset, for example to restore a Dialect or
+// limit the Pool's users expect.
+func WithResetHook(reset func(*Buffer)) Option {
+	return func(o *options) { o.reset = reset }
+}
+
+// WithClasses causes the Pool to keep a separate free list for each of the
+// given capacities, each with the size given to the Pool's constructor.
+// Buffers are allocated with at least their class's capacity, GetSize takes
+// from the smallest class that fits, and returned Buffers go to the largest
+// class they fit, or, in pools of a fixed size such as NewChanPool's, back
+// to the class they were allocated for. WithClasses panics if a size is
+// negative.
+func WithClasses(sizes ...int) Option {
+	sizes = slices.Clone(sizes)
+	slices.Sort(sizes)
+	sizes = slices.Compact(sizes)
+	if len(sizes) > 0 && sizes[0] < 0 {
+		panic("pools: negative size class")
+	}
+	return func(o *options) { o.classes = sizes }
+}
+
+// WithRateLimit limits Gets from the Pool to perSecond on average, in bursts
+// of up to burst. Get and GetSize calls over the limit are given a newly
+// allocated Buffer instead of an idle one, and GetCtx and GetTimeout calls
+// fail with ErrRateLimited, so a caller spinning on Get and Put can't starve
+// others of a bounded Pool. Throttled Gets are counted in the RateLimited
+// field of Pool.ReadStats. WithRateLimit panics if perSecond or burst is
+// not positive.
+func WithRateLimit(perSecond float64, burst int) Option {
+	if !(perSecond > 0) || burst <= 0 {
+		panic("pools: non-positive rate limit")
+	}
+	return func(o *options) { o.rate, o.burst = perSecond, burst }
+}
+
+// newPool creates a Pool with a free list for each size class created by
+// newList. alloc allocates a Buffer for the list's class.
+func newPool(opts []Option, newList func(alloc func() *Buffer) freeList) *Pool {
+	p := new(Pool)
+	for _, o := range opts {
+		o(&p.opts)
+	}
+	if p.opts.rate > 0 {
+		p.limit = newTokenBucket(p.opts.rate, p.opts.burst)
+	}
+	p.classes = p.opts.classes
+	if len(p.classes) == 0 {
+		p.classes = []int{0}
+	}
+	p.free = make([]freeList, len(p.classes))
+	for i := range p.classes {
+		p.free[i] = newList(func() *Buffer { return p.alloc(i) })
+	}
+	return p
+}
+
+// alloc allocates a Buffer for the class, which can hold at least the
+// class's size in bytes.
+func (p *Pool) alloc(class int) *Buffer {
+	if p.opts.metrics {
+		p.news.Add(1)
+	}
+	var b *Buffer
+	if p.opts.new != nil {
+		b = p.opts.new()
+	} else {
+		b = newBuffer()
+	}
+	if size := p.classes[class]; b.Cap() < size {
+		b.Grow(size)
+	}
+	b.epoch = p.epoch.Load()
+	b.class = class
+	return b
+}
+
+// Get returns a Buffer from the pool, allocating one if the pool is empty.
+func (p *Pool) Get() *Buffer {
+	return p.get(0)
+}
+
+// GetSize is like Get but the returned Buffer can hold at least n bytes
+// without reallocating.
+func (p *Pool) GetSize(n int) *Buffer {
+	i, _ := slices.BinarySearch(p.classes, n)
+	b := p.get(min(i, len(p.classes)-1))
+	if b.Cap() < n {
+		b.Grow(n)
+	}
+	return b
+}
+
+func (p *Pool) get(class int) *Buffer {
+	p.countGet()
+	if !p.allow() {
+		return p.alloc(class)
+	}
+	return p.take(class)
+}
+
+// take returns an idle Buffer of the class, or allocates one.
+func (p *Pool) take(class int) *Buffer {
+	if b := p.free[class].get(); b != nil {
+		return p.current(b, class)
+	}
+	return p.alloc(class)
+}
+
+func (p *Pool) countGet() {
+	getHooks.call(KindBuffer)
+	if p.opts.metrics {
+		p.gets.Add(1)
+	}
+}
+
+// allow reports whether a Get is within the Pool's rate limit, counting it
+// if not.
+func (p *Pool) allow() bool {
+	if p.limit == nil || p.limit.take() {
+		return true
+	}
+	p.rateLimited.Add(1)
+	return false
+}
+
+// current returns b, or, if b was allocated before the Pool was last
+// invalidated, discards it and returns a new Buffer for the class.
+func (p *Pool) current(b *Buffer, class int) *Buffer {
+	if b.epoch == p.epoch.Load() {
+		return b
+	}
+	p.discard(b, EvictInvalidated)
+	return p.alloc(class)
+}
+
+// Invalidate causes every Buffer the Pool handed out or retained before the
+// call to be dropped instead of reused: idle Buffers are discarded by Get
+// and checked out ones by Put, each with EvictInvalidated. Pools created
+// with a fixed number of Buffers, such as by NewChanPool, allocate
+// replacements so they keep their size. Invalidate is useful when a change
+// in configuration, such as switching to PutZeroed, must apply to every
+// Buffer the Pool holds. It also revokes every Handle from the Pool's
+// GetHandle.
+func (p *Pool) Invalidate() {
+	p.epoch.Add(1)
+}
+
+// GetCtx is like Get but, for pools whose size is fixed, such as those
+// created by NewChanPool, it waits for a Buffer to be returned instead of
+// allocating one. It returns ctx.Err() if ctx is done first, letting callers
+// apply backpressure when overloaded. See also WithRateLimit.
+func (p *Pool) GetCtx(ctx context.Context) (*Buffer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !p.allow() {
+		return nil, ErrRateLimited
+	}
+	w, ok := p.free[0].(waiter)
+	if !ok {
+		p.countGet()
+		return p.take(0), nil
+	}
+	b, err := w.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.countGet()
+	return p.current(b, 0), nil
+}
+
+// GetTimeout is like GetCtx but waits for at most d. It reports false if no
+// Buffer became available in time, letting the caller allocate one itself or
+// shed load instead.
+func (p *Pool) GetTimeout(d time.Duration) (*Buffer, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	b, err := p.GetCtx(ctx)
+	return b, err == nil
+}
+
+// waiter is implemented by backends with a fixed number of Buffers.
+type waiter interface {
+	// wait returns an idle Buffer, waiting for one if there are none.
+	wait(ctx context.Context) (*Buffer, error)
+}
+
+// Put resets b and returns it to the pool. If the pool is full, b is left to
+// the GC.
+func (p *Pool) Put(b *Buffer) {
+	p.put(b, false)
+}
+
+// PutZeroed is like Put but first overwrites all of b's memory with zeros.
+// See PutBufferZeroed.
+func (p *Pool) PutZeroed(b *Buffer) {
+	p.put(b, true)
+}
+
+func (p *Pool) put(b *Buffer, zero bool) {
+	checkUnsafe(b)
+	putHooks.call(KindBuffer)
+	if p.opts.metrics {
+		p.puts.Add(1)
+	}
+	// Pools of a fixed size replace the Buffers they drop, and return each
+	// to the class it was allocated for, so that no class loses a slot.
+	_, fixed := p.free[0].(waiter)
+	class := p.class(b)
+	if fixed {
+		class = min(b.class, len(p.classes)-1)
+	}
+	if b.epoch != p.epoch.Load() {
+		p.discard(b, EvictInvalidated)
+		if !fixed {
+			return
+		}
+		b = p.alloc(class)
+	}
+	if p.opts.maxSize > 0 && b.Cap() > p.opts.maxSize {
+		p.discard(b, EvictOversize)
+		if !fixed {
+			return
+		}
+		b = p.alloc(class)
+	}
+	recycle(b, zero)
+	if p.opts.reset != nil {
+		p.opts.reset(b)
+	}
+	if !p.free[class].put(b) {
+		p.discard(b, EvictFull)
+	}
+}
+
+// class returns the largest class b fits.
+func (p *Pool) class(b *Buffer) int {
+	i, ok := slices.BinarySearch(p.classes, b.Cap())
+	if !ok {
+		i = max(i-1, 0)
+	}
+	return i
+}
+
+func (p *Pool) discard(b *Buffer, reason string) {
+	if p.opts.metrics {
+		p.discards.Add(1)
+	}
+	evictBuffer(b, reason)
+}
+
+// ReadStats populates the Gets, Puts, News, Discards, and RateLimited
+// fields of s with the Pool's statistics. All but RateLimited are only
+// counted if the Pool was created with WithMetrics.
+func (p *Pool) ReadStats(s *Stats) {
+	*s = Stats{
+		Gets:     p.gets.Load(),
+		Puts:     p.puts.Load(),
+		News:     p.news.Load(),
+		Discards: p.discards.Load(),
+
+		RateLimited: p.rateLimited.Load(),
+	}
+}
@@ -0,0 +1,300 @@
+package pools
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPinnedPool(t *testing.T) {
+	p := NewPinnedPool(2)
+	a, b, c := p.Get(), p.Get(), p.Get()
+	a.WriteString("a")
+	p.Put(a)
+	p.Put(b)
+	p.Put(c) // dropped
+
+	runtime.GC()
+	runtime.GC()
+	got := map[*Buffer]bool{p.Get(): true, p.Get(): true}
+	if !got[a] || !got[b] {
+		t.Fatal("want the retained Buffers to survive GC")
+	}
+	expect(t, 0, a.Len())
+	if d := p.Get(); d == a || d == b || d == c {
+		t.Fatal("want a new Buffer from an empty pool")
+	}
+}
+
+func TestChanPool(t *testing.T) {
+	p := NewChanPool(1, false)
+	a, b := p.Get(), p.Get()
+	if a == b {
+		t.Fatal("want a new Buffer from an empty pool")
+	}
+	p.Put(a)
+	p.Put(b) // dropped
+	if p.Get() != a {
+		t.Fatal("want the retained Buffer")
+	}
+
+	p = NewChanPool(1, true)
+	a = p.Get()
+	done := make(chan *Buffer)
+	go func() { done <- p.Get() }()
+	select {
+	case <-done:
+		t.Fatal("Get didn't block on an empty pool")
+	case <-time.After(10 * time.Millisecond):
+	}
+	p.Put(a)
+	if got := <-done; got != a {
+		t.Fatal("want the returned Buffer")
+	}
+}
+
+func TestRingPool(t *testing.T) {
+	p := NewRingPool(3) // rounded up to 4
+	bs := make([]*Buffer, 5)
+	for i := range bs {
+		bs[i] = p.Get()
+	}
+	for _, b := range bs {
+		p.Put(b) // the last is dropped
+	}
+	for _, want := range bs[:4] {
+		if got := p.Get(); got != want {
+			t.Fatal("want Buffers in FIFO order")
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				b := p.Get()
+				b.WriteString("x")
+				if b.Len() != 1 {
+					t.Errorf("Buffer shared between goroutines: %q", b)
+				}
+				p.Put(b)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestShardedPool(t *testing.T) {
+	// With one P there's a single shard, so retention is predictable.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	p := NewShardedPool(1)
+	a, b := p.Get(), p.Get()
+	p.Put(a)
+	p.Put(b) // dropped
+	runtime.GC()
+	if p.Get() != a {
+		t.Fatal("want the retained Buffer")
+	}
+	runtime.GOMAXPROCS(8)
+
+	// Retention is split exactly, even over fewer Buffers than shards.
+	for _, n := range []int{3, 8, 13} {
+		sp := NewShardedPool(n)
+		total := 0
+		for i := range sp.free[0].(*sharded).shards {
+			total += cap(sp.free[0].(*sharded).shards[i].free)
+		}
+		expect(t, n, total)
+	}
+
+	p = NewShardedPool(64)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				b := p.Get()
+				b.WriteString("x")
+				if b.Len() != 1 {
+					t.Errorf("Buffer shared between goroutines: %q", b)
+				}
+				p.Put(b)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIntrusivePool(t *testing.T) {
+	p := NewIntrusivePool(2)
+	a, b, c := p.Get(), p.Get(), p.Get()
+	p.Put(a)
+	p.Put(b)
+	p.Put(c) // dropped
+	runtime.GC()
+	if p.Get() != b || p.Get() != a {
+		t.Fatal("want the retained Buffers in LIFO order")
+	}
+	if a.next != nil || b.next != nil {
+		t.Fatal("want Buffers unlinked from the list")
+	}
+	if d := p.Get(); d == a || d == b || d == c {
+		t.Fatal("want a new Buffer from an empty pool")
+	}
+
+	if n := testing.AllocsPerRun(100, func() { p.Put(p.Get()) }); n != 0 {
+		t.Fatalf("want 0 allocations, got %v", n)
+	}
+}
+
+func TestGetCtx(t *testing.T) {
+	p := NewChanPool(1, false)
+	a, err := p.GetCtx(context.Background())
+	expect(t, nil, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = p.GetCtx(ctx)
+	expect(t, context.DeadlineExceeded, err)
+
+	go p.Put(a)
+	b, err := p.GetCtx(context.Background())
+	expect(t, nil, err)
+	if b != a {
+		t.Fatal("want the returned Buffer")
+	}
+}
+
+func TestGetTimeout(t *testing.T) {
+	p := NewChanPool(1, true)
+	a, ok := p.GetTimeout(time.Millisecond)
+	expect(t, true, ok)
+	b, ok := p.GetTimeout(time.Millisecond)
+	expect(t, false, ok)
+	if b != nil {
+		t.Fatal("want a nil Buffer")
+	}
+	p.Put(a)
+}
+
+func TestPoolOptions(t *testing.T) {
+	var resets int
+	p := NewPinnedPool(4,
+		WithMaxSize(1<<10),
+		WithMetrics(),
+		WithNew(func() *Buffer {
+			b := new(Buffer)
+			b.SetDialect(MySQL)
+			return b
+		}),
+		WithResetHook(func(b *Buffer) {
+			resets++
+			b.SetDialect(MySQL)
+		}),
+		WithClasses(512, 64),
+	)
+
+	small := p.Get()
+	expect(t, MySQL, small.Dialect())
+	if small.Cap() < 64 {
+		t.Fatalf("want cap >= 64, got %d", small.Cap())
+	}
+	big := p.GetSize(100)
+	if big.Cap() < 512 {
+		t.Fatalf("want cap >= 512, got %d", big.Cap())
+	}
+	huge := p.GetSize(1 << 20)
+	p.Put(small)
+	p.Put(big)
+	p.Put(huge) // too big
+	expect(t, 2, resets)
+	expect(t, MySQL, small.Dialect())
+
+	if p.GetSize(512) != big || p.Get() != small {
+		t.Fatal("want Buffers back from their classes")
+	}
+
+	var s Stats
+	p.ReadStats(&s)
+	expect(t, Stats{Gets: 5, Puts: 3, News: 3, Discards: 1}, s)
+}
+
+func TestPoolInvalidate(t *testing.T) {
+	p := NewPinnedPool(2, WithMetrics())
+	a, b := p.Get(), p.Get()
+	p.Put(a)
+	p.Invalidate()
+	p.Put(b) // allocated before Invalidate
+	if c := p.Get(); c == a || c == b {
+		t.Fatal("want a new Buffer after Invalidate")
+	}
+	var s Stats
+	p.ReadStats(&s)
+	expect(t, uint64(2), s.Discards)
+
+	// Fixed-size pools keep their Buffers.
+	cp := NewChanPool(1, true)
+	a = cp.Get()
+	cp.Invalidate()
+	cp.Put(a)
+	if b := cp.Get(); b == a {
+		t.Fatal("want a replacement Buffer after Invalidate")
+	}
+}
+
+func TestChanPoolKeepsSize(t *testing.T) {
+	// An oversize Buffer is replaced.
+	p := NewChanPool(1, false, WithMaxSize(64))
+	a := p.Get()
+	a.Grow(1 << 10)
+	p.Put(a)
+	b, ok := p.GetTimeout(10 * time.Millisecond)
+	expect(t, true, ok)
+	if b == a {
+		t.Fatal("want a replacement for the oversize Buffer")
+	}
+
+	// A grown Buffer goes back to its own class, not the full one it now fits.
+	p = NewChanPool(1, false, WithClasses(0, 512))
+	a = p.Get()
+	a.Grow(1 << 10)
+	p.Put(a)
+	b, ok = p.GetTimeout(10 * time.Millisecond)
+	expect(t, true, ok)
+	if b != a {
+		t.Fatal("want the Buffer back in its class")
+	}
+	if c := p.GetSize(512); c.Cap() < 512 {
+		t.Fatalf("want cap >= 512, got %d", c.Cap())
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	p := NewPinnedPool(1, WithRateLimit(1e-9, 2))
+	a := p.Get()
+	p.Put(a)
+	if p.Get() != a {
+		t.Fatal("want the retained Buffer within the limit")
+	}
+	p.Put(a)
+	if p.Get() == a {
+		t.Fatal("want a new Buffer over the limit")
+	}
+	_, err := p.GetCtx(context.Background())
+	expect(t, ErrRateLimited, err)
+
+	var s Stats
+	p.ReadStats(&s)
+	expect(t, uint64(2), s.RateLimited)
+
+	b := newTokenBucket(1000, 1)
+	expect(t, true, b.take())
+	expect(t, false, b.take())
+	b.last = b.last.Add(-time.Millisecond)
+	expect(t, true, b.take())
+}
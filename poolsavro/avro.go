@@ -0,0 +1,134 @@
+// Package poolsavro encodes Avro records and object container files using
+// the pools package's Buffers.
+package poolsavro
+
+import (
+	"sync"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/sermodigital/pools"
+)
+
+// writerBufSize is the size of an avro.Writer's own buffer.
+const writerBufSize = 512
+
+// Writer is an avro.Writer that writes to a pooled Buffer.
+type Writer struct {
+	*avro.Writer
+	buf *pools.Buffer
+}
+
+var writerPool = sync.Pool{
+	New: func() interface{} {
+		return &Writer{Writer: avro.NewWriter(nil, writerBufSize)}
+	},
+}
+
+func GetWriter() *Writer {
+	w := writerPool.Get().(*Writer)
+	w.buf = pools.GetBuffer()
+	w.Reset(w.buf)
+	return w
+}
+
+// PutWriter returns w and its Buffer to their pools. Slices returned by
+// Bytes may not be used afterward.
+func PutWriter(w *Writer) {
+	pools.PutBuffer(w.buf)
+	w.buf = nil
+	w.Reset(nil)
+	w.Error = nil
+	writerPool.Put(w)
+}
+
+// Bytes flushes w and returns what has been written so far. The slice is
+// only valid until the next call to a method of w.
+func (w *Writer) Bytes() ([]byte, error) {
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// Marshal returns the Avro binary encoding of v, built in a pooled Buffer.
+// See Buffer.UnsafeBytes for the lifetime of the result.
+func Marshal(schema avro.Schema, v interface{}) ([]byte, error) {
+	w := writerPool.Get().(*Writer)
+	b := pools.GetBuffer()
+	w.Reset(b)
+	w.WriteVal(schema, v)
+	err := w.Flush()
+	w.Reset(nil)
+	w.Error = nil
+	writerPool.Put(w)
+	if err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	return b.UnsafeBytes(), nil
+}
+
+// OCFEncoder is an ocf.Encoder that writes an object container file to a
+// pooled Buffer. Its block buffers are reused along with it.
+type OCFEncoder struct {
+	*ocf.Encoder
+	buf  *pools.Buffer
+	pool *sync.Pool
+}
+
+// ocfPools maps schema fingerprints to pools of OCFEncoders.
+var ocfPools sync.Map
+
+// GetOCFEncoder returns an OCFEncoder for schema that has written the file's
+// header.
+func GetOCFEncoder(schema avro.Schema) (*OCFEncoder, error) {
+	key := schema.Fingerprint()
+	p, ok := ocfPools.Load(key)
+	if !ok {
+		p, _ = ocfPools.LoadOrStore(key, new(sync.Pool))
+	}
+	pool := p.(*sync.Pool)
+
+	buf := pools.GetBuffer()
+	if x := pool.Get(); x != nil {
+		e := x.(*OCFEncoder)
+		if err := e.Reset(buf); err != nil {
+			pools.PutBuffer(buf)
+			return nil, err
+		}
+		e.buf = buf
+		return e, nil
+	}
+	enc, err := ocf.NewEncoderWithSchema(schema, buf)
+	if err != nil {
+		pools.PutBuffer(buf)
+		return nil, err
+	}
+	return &OCFEncoder{Encoder: enc, buf: buf, pool: pool}, nil
+}
+
+// PutOCFEncoder returns e and its Buffer to their pools. Records encoded but
+// not flushed are discarded, and slices returned by Bytes may not be used
+// afterward.
+func PutOCFEncoder(e *OCFEncoder) {
+	// Flush now, while e.buf is still e's, so that the next Reset has
+	// nothing to write.
+	if err := e.Flush(); err != nil {
+		// e's state is unknown; leave it to the GC.
+		pools.PutBuffer(e.buf)
+		return
+	}
+	pools.PutBuffer(e.buf)
+	e.buf = nil
+	e.pool.Put(e)
+}
+
+// Bytes flushes e and returns the file written so far. The slice is only
+// valid until the next call to a method of e.
+func (e *OCFEncoder) Bytes() ([]byte, error) {
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
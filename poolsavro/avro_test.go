@@ -0,0 +1,85 @@
+package poolsavro
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+)
+
+type user struct {
+	ID   int64  `avro:"id"`
+	Name string `avro:"name"`
+}
+
+var schema = avro.MustParse(`{
+	"type": "record",
+	"name": "user",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "name", "type": "string"}
+	]
+}`)
+
+func TestMarshal(t *testing.T) {
+	in := user{ID: 1, Name: "gopher"}
+	want, err := avro.Marshal(schema, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Marshal(schema, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("want %x, got %x", want, got)
+	}
+
+	w := GetWriter()
+	defer PutWriter(w)
+	w.WriteVal(schema, in)
+	p, err := w.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, p) {
+		t.Fatalf("want %x, got %x", want, p)
+	}
+}
+
+func TestOCFEncoder(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		e, err := GetOCFEncoder(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := []user{{1, "a"}, {2, "b"}}
+		for _, u := range in {
+			if err := e.Encode(u); err != nil {
+				t.Fatal(err)
+			}
+		}
+		p, err := e.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dec, err := ocf.NewDecoder(bytes.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []user
+		for dec.HasNext() {
+			var u user
+			if err := dec.Decode(&u); err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, u)
+		}
+		if len(out) != 2 || out[0] != in[0] || out[1] != in[1] {
+			t.Fatalf("want %v, got %v", in, out)
+		}
+		PutOCFEncoder(e)
+	}
+}
@@ -0,0 +1,133 @@
+// Package poolsbench provides benchmark drivers for comparing Buffer pool
+// backends, such as the package-level sync.Pool and those created by
+// pools.NewShardedPool and pools.NewRingPool, under the same workload.
+//
+//	func BenchmarkSharded(b *testing.B) {
+//		poolsbench.Run(b, pools.NewShardedPool(1024), poolsbench.Config{
+//			Sizes:      poolsbench.Uniform(64, 64<<10),
+//			Goroutines: 64,
+//			Hold:       10 * time.Microsecond,
+//		})
+//	}
+//
+// Besides ns/op and allocs/op, Run reports the number of GC cycles per
+// million operations and the 50th, 99th, and 99.9th percentile latency of a
+// Get, write, and Put.
+package poolsbench
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sermodigital/pools"
+)
+
+// Config describes a benchmark's workload.
+type Config struct {
+	// Sizes returns the number of bytes to write to each Buffer. If nil,
+	// 1KB is written every time.
+	Sizes func(r *rand.Rand) int
+
+	// Goroutines is how many goroutines share the pool. If zero, it's
+	// GOMAXPROCS.
+	Goroutines int
+
+	// Hold is how long each Buffer is held before it's returned, to model
+	// work done with it. While held, other goroutines' Gets find the pool
+	// emptier.
+	Hold time.Duration
+}
+
+// Fixed returns a size distribution that always returns n.
+func Fixed(n int) func(*rand.Rand) int {
+	return func(*rand.Rand) int { return n }
+}
+
+// Uniform returns a size distribution uniform over [lo, hi].
+func Uniform(lo, hi int) func(*rand.Rand) int {
+	return func(r *rand.Rand) int { return lo + r.IntN(hi-lo+1) }
+}
+
+// Bimodal returns a size distribution that returns large with probability
+// p and small otherwise, modeling mostly small messages with the occasional
+// large one, which is hard on pools that retain whatever they're given.
+func Bimodal(small, large int, p float64) func(*rand.Rand) int {
+	return func(r *rand.Rand) int {
+		if r.Float64() < p {
+			return large
+		}
+		return small
+	}
+}
+
+// Run runs b.N Gets, writes, and Puts against p spread over the configured
+// goroutines and reports their allocations, GC cycles, and latency
+// percentiles.
+func Run(b *testing.B, p pools.BufferPool, cfg Config) {
+	sizes := cfg.Sizes
+	if sizes == nil {
+		sizes = Fixed(1 << 10)
+	}
+	g := cfg.Goroutines
+	if g <= 0 {
+		g = runtime.GOMAXPROCS(0)
+	}
+	data := make([]byte, 1<<20)
+	lat := make([][]time.Duration, g)
+	for i := range lat {
+		lat[i] = make([]time.Duration, 0, b.N/g+1)
+	}
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	gcs := ms.NumGC
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for i := range g {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewPCG(uint64(i), 0))
+			for next.Add(1) <= int64(b.N) {
+				n := min(sizes(r), len(data))
+				start := time.Now()
+				buf := p.Get()
+				buf.Write(data[:n])
+				if cfg.Hold > 0 {
+					time.Sleep(cfg.Hold)
+				}
+				p.Put(buf)
+				lat[i] = append(lat[i], time.Since(start)-cfg.Hold)
+			}
+		}()
+	}
+	wg.Wait()
+
+	b.StopTimer()
+	runtime.ReadMemStats(&ms)
+	b.ReportMetric(float64(ms.NumGC-gcs)*1e6/float64(b.N), "GCs/1M-ops")
+	all := slices.Concat(lat...)
+	slices.Sort(all)
+	for _, q := range []struct {
+		p    float64
+		unit string
+	}{{0.5, "p50-ns"}, {0.99, "p99-ns"}, {0.999, "p99.9-ns"}} {
+		b.ReportMetric(float64(percentile(all, q.p)), q.unit)
+	}
+}
+
+// percentile returns the p'th percentile of the sorted durations ds.
+func percentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	return ds[min(int(float64(len(ds))*p), len(ds)-1)]
+}
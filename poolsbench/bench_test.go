@@ -0,0 +1,61 @@
+package poolsbench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sermodigital/pools"
+)
+
+func TestRun(t *testing.T) {
+	r := testing.Benchmark(func(b *testing.B) {
+		Run(b, pools.Buffers, Config{Sizes: Uniform(1, 100), Goroutines: 4})
+	})
+	for _, unit := range []string{"GCs/1M-ops", "p50-ns", "p99-ns", "p99.9-ns"} {
+		if _, ok := r.Extra[unit]; !ok {
+			t.Errorf("missing metric %s", unit)
+		}
+	}
+	if r.Extra["p50-ns"] > r.Extra["p99.9-ns"] {
+		t.Errorf("percentiles out of order: %v", r.Extra)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	ds := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if got := percentile(ds, 0.5); got != 6 {
+		t.Fatalf("want 6, got %d", got)
+	}
+	if got := percentile(ds, 0.999); got != 10 {
+		t.Fatalf("want 10, got %d", got)
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Fatalf("want 0, got %d", got)
+	}
+}
+
+func benchmarkBackends(b *testing.B, cfg Config) {
+	for _, bc := range []struct {
+		name string
+		p    pools.BufferPool
+	}{
+		{"SyncPool", pools.Buffers},
+		{"Sharded", pools.NewShardedPool(1024)},
+		{"Ring", pools.NewRingPool(1024)},
+		{"Pinned", pools.NewPinnedPool(1024)},
+	} {
+		b.Run(bc.name, func(b *testing.B) { Run(b, bc.p, cfg) })
+	}
+}
+
+func BenchmarkSmall(b *testing.B) {
+	benchmarkBackends(b, Config{Sizes: Uniform(16, 512)})
+}
+
+func BenchmarkBimodal(b *testing.B) {
+	benchmarkBackends(b, Config{Sizes: Bimodal(256, 256<<10, 0.01), Goroutines: 64})
+}
+
+func BenchmarkHold(b *testing.B) {
+	benchmarkBackends(b, Config{Goroutines: 256, Hold: 10 * time.Microsecond})
+}
@@ -0,0 +1,51 @@
+// Package poolsbson builds BSON documents in the pools package's Buffers,
+// for use with the mongo-driver's bsoncore append APIs.
+package poolsbson
+
+import (
+	"github.com/sermodigital/pools"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore"
+)
+
+// MarshalAppend appends the BSON encoding of v, which must encode as a
+// document, to b.
+func MarshalAppend(b *pools.Buffer, v interface{}) error {
+	return bson.NewEncoder(bson.NewDocumentWriter(b)).Encode(v)
+}
+
+// Marshal returns the BSON encoding of v, built in a pooled Buffer.
+// See Buffer.UnsafeBytes for the lifetime of the result.
+func Marshal(v interface{}) (bsoncore.Document, error) {
+	b := pools.GetBuffer()
+	if err := MarshalAppend(b, v); err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	return b.UnsafeBytes(), nil
+}
+
+// BuildDocument builds a document in a pooled Buffer. build appends the
+// document's elements to dst using bsoncore's Append functions and returns
+// the extended slice. For example:
+//
+//	doc, err := poolsbson.BuildDocument(func(dst []byte) ([]byte, error) {
+//		dst = bsoncore.AppendStringElement(dst, "name", name)
+//		return bsoncore.AppendInt32Element(dst, "age", age), nil
+//	})
+//
+// See Buffer.UnsafeBytes for the lifetime of the result.
+func BuildDocument(build func(dst []byte) ([]byte, error)) (bsoncore.Document, error) {
+	b := pools.GetBuffer()
+	idx, dst := bsoncore.AppendDocumentStart(b.AvailableBuffer())
+	dst, err := build(dst)
+	if err == nil {
+		dst, err = bsoncore.AppendDocumentEnd(dst, idx)
+	}
+	if err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	b.Write(dst)
+	return b.UnsafeBytes(), nil
+}
@@ -0,0 +1,31 @@
+package poolsbson
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/x/bsonx/bsoncore"
+)
+
+func TestMarshal(t *testing.T) {
+	doc, err := Marshal(bson.D{{Key: "name", Value: "gopher"}, {Key: "age", Value: int32(10)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := BuildDocument(func(dst []byte) ([]byte, error) {
+		dst = bsoncore.AppendStringElement(dst, "name", "gopher")
+		return bsoncore.AppendInt32Element(dst, "age", 10), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := built.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if string(doc) != string(built) {
+		t.Fatalf("want %v, got %v", doc, built)
+	}
+	if _, err := Marshal(42); err == nil {
+		t.Fatal("want an error for a non-document")
+	}
+}
@@ -0,0 +1,42 @@
+// Package poolscapnp pools Cap'n Proto messages the way the pools package
+// pools flatbuffers Builders.
+package poolscapnp
+
+import (
+	"sync"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+var messagePool = sync.Pool{
+	New: func() interface{} {
+		return new(capnp.Message)
+	},
+}
+
+// GetMessage returns an empty Message from the pool and its first segment,
+// ready for a new root. Its arena draws its memory from capnp's own free
+// list of segments.
+//
+//	msg, seg := poolscapnp.GetMessage()
+//	defer poolscapnp.PutMessage(msg)
+//	u, err := NewRootUser(seg)
+//	...
+//	err = capnp.NewEncoder(w).Encode(msg)
+func GetMessage() (*capnp.Message, *capnp.Segment) {
+	m := messagePool.Get().(*capnp.Message)
+	seg, err := m.Reset(capnp.SingleSegment(nil))
+	if err != nil {
+		// A SingleSegment arena can always allocate its first segment.
+		panic("poolscapnp: " + err.Error())
+	}
+	return m, seg
+}
+
+// PutMessage resets m, which releases its arena and capability table, and
+// returns it to the pool. Neither m nor anything read from or written to it
+// may be used afterward.
+func PutMessage(m *capnp.Message) {
+	m.Release()
+	messagePool.Put(m)
+}
@@ -0,0 +1,37 @@
+package poolscapnp
+
+import (
+	"testing"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+func TestMessage(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		msg, seg := GetMessage()
+		text, err := capnp.NewText(seg, "hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := msg.SetRoot(text.ToPtr()); err != nil {
+			t.Fatal(err)
+		}
+		p, err := msg.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := capnp.Unmarshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, err := got.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := root.Text(); s != "hello" {
+			t.Fatalf("want %q, got %q", "hello", s)
+		}
+		PutMessage(msg)
+	}
+}
@@ -0,0 +1,80 @@
+// Package poolseasyjson runs easyjson marshalers and unmarshalers against
+// the pools package's Buffers.
+package poolseasyjson
+
+import (
+	"io"
+	"sync"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+	"github.com/sermodigital/pools"
+)
+
+var writerPool = sync.Pool{
+	New: func() interface{} {
+		return new(jwriter.Writer)
+	},
+}
+
+func GetWriter() *jwriter.Writer {
+	return writerPool.Get().(*jwriter.Writer)
+}
+
+// PutWriter releases w's chunks to easyjson's own pool and returns w to the
+// pool.
+func PutWriter(w *jwriter.Writer) {
+	w.DumpTo(io.Discard)
+	*w = jwriter.Writer{}
+	writerPool.Put(w)
+}
+
+// WriteTo marshals v into b.
+func WriteTo(b *pools.Buffer, v easyjson.Marshaler) error {
+	w := GetWriter()
+	defer PutWriter(w)
+	v.MarshalEasyJSON(w)
+	if w.Error != nil {
+		return w.Error
+	}
+	b.Grow(w.Size())
+	_, err := w.DumpTo(b)
+	return err
+}
+
+// Marshal returns the JSON encoding of v, built in a pooled Buffer.
+// See Buffer.UnsafeBytes for the lifetime of the result.
+func Marshal(v easyjson.Marshaler) ([]byte, error) {
+	b := pools.GetBuffer()
+	if err := WriteTo(b, v); err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	return b.UnsafeBytes(), nil
+}
+
+var lexerPool = sync.Pool{
+	New: func() interface{} {
+		return new(jlexer.Lexer)
+	},
+}
+
+// Unmarshal reads all of r into a pooled Buffer and unmarshals it into v.
+// The Buffer is returned to the pool before Unmarshal returns, so v may not
+// keep slices of its input, as it does for fields of type
+// easyjson.RawMessage.
+func Unmarshal(r io.Reader, v easyjson.Unmarshaler) error {
+	b := pools.GetBuffer()
+	defer pools.PutBuffer(b)
+	if _, err := b.ReadFrom(r); err != nil {
+		return err
+	}
+	l := lexerPool.Get().(*jlexer.Lexer)
+	*l = jlexer.Lexer{Data: b.Bytes()}
+	v.UnmarshalEasyJSON(l)
+	err := l.Error()
+	*l = jlexer.Lexer{}
+	lexerPool.Put(l)
+	return err
+}
@@ -0,0 +1,64 @@
+package poolseasyjson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// user implements easyjson's interfaces by hand, as easyjson would generate.
+type user struct {
+	ID   int
+	Name string
+}
+
+func (u user) MarshalEasyJSON(w *jwriter.Writer) {
+	w.RawString(`{"id":`)
+	w.Int(u.ID)
+	w.RawString(`,"name":`)
+	w.String(u.Name)
+	w.RawByte('}')
+}
+
+func (u *user) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	l.Delim('{')
+	for !l.IsDelim('}') {
+		key := l.UnsafeFieldName(false)
+		l.WantColon()
+		switch key {
+		case "id":
+			u.ID = l.Int()
+		case "name":
+			u.Name = l.String()
+		default:
+			l.SkipRecursive()
+		}
+		l.WantComma()
+	}
+	l.Delim('}')
+}
+
+func TestMarshal(t *testing.T) {
+	p, err := Marshal(user{1, "gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"id":1,"name":"gopher"}`
+	if string(p) != want {
+		t.Fatalf("want %s, got %s", want, p)
+	}
+
+	var u user
+	if err := Unmarshal(strings.NewReader(want), &u); err != nil {
+		t.Fatal(err)
+	}
+	if u != (user{1, "gopher"}) {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+
+	if err := Unmarshal(strings.NewReader(`{"id":"x"}`), &u); err == nil {
+		t.Fatal("want an error for invalid input")
+	}
+}
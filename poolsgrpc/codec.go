@@ -0,0 +1,136 @@
+// Package poolsgrpc provides gRPC codecs that marshal messages into the pools
+// package's Buffers and Builders and return them to their pools once the
+// transport has written them.
+package poolsgrpc
+
+import (
+	"fmt"
+	"sync"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/sermodigital/pools"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ProtoCodec marshals protocol buffers into pooled Buffers. Register it
+	// with encoding.RegisterCodecV2 to replace gRPC's default codec.
+	ProtoCodec encoding.CodecV2 = codec{name: "proto"}
+
+	// FlatbuffersCodec sends flatbuffers Builders, which it returns to the
+	// pool once they've been written, so Builders given to it must come from
+	// pools.GetBuilder and may not be used afterward. Received messages
+	// must implement Init(buf []byte, i flatbuffers.UOffsetT), like the
+	// types generated by flatc.
+	FlatbuffersCodec encoding.CodecV2 = codec{name: "flatbuffers"}
+)
+
+type codec struct {
+	name string
+}
+
+func (c codec) Name() string {
+	return c.name
+}
+
+func (c codec) Marshal(v interface{}) (mem.BufferSlice, error) {
+	switch v := v.(type) {
+	case *flatbuffers.Builder:
+		r := getRelease()
+		r.builder = v
+		r.p = v.FinishedBytes()
+		return r.slice(), nil
+	case proto.Message:
+		var opts proto.MarshalOptions
+		b := pools.GetBufferSize(opts.Size(v))
+		opts.UseCachedSize = true
+		p, err := opts.MarshalAppend(b.AvailableBuffer(), v)
+		if err != nil {
+			pools.PutBuffer(b)
+			return nil, err
+		}
+		b.Write(p)
+		r := getRelease()
+		r.buffer = b
+		r.p = b.Bytes()
+		return r.slice(), nil
+	default:
+		return nil, fmt.Errorf("poolsgrpc: can't marshal %T", v)
+	}
+}
+
+type flatbuffersInit interface {
+	Init(buf []byte, i flatbuffers.UOffsetT)
+}
+
+func (c codec) Unmarshal(data mem.BufferSlice, v interface{}) error {
+	switch v := v.(type) {
+	case flatbuffersInit:
+		// v keeps referring to the data, which gRPC frees after Unmarshal.
+		p := data.Materialize()
+		v.Init(p, flatbuffers.GetUOffsetT(p))
+		return nil
+	case proto.Message:
+		if len(data) == 1 {
+			return proto.Unmarshal(data[0].ReadOnlyData(), v)
+		}
+		b := pools.GetBufferSize(data.Len())
+		defer pools.PutBuffer(b)
+		for _, d := range data {
+			b.Write(d.ReadOnlyData())
+		}
+		return proto.Unmarshal(b.Bytes(), v)
+	default:
+		return fmt.Errorf("poolsgrpc: can't unmarshal into %T", v)
+	}
+}
+
+// release is a mem.BufferPool for a single marshaled message. gRPC calls
+// Put once the message has been written, which returns the Buffer or Builder
+// holding it to its pool.
+type release struct {
+	p       []byte
+	buffer  *pools.Buffer
+	builder *flatbuffers.Builder
+}
+
+var releasePool = sync.Pool{
+	New: func() interface{} {
+		return new(release)
+	},
+}
+
+func getRelease() *release {
+	return releasePool.Get().(*release)
+}
+
+// slice returns r's message as a BufferSlice that releases r once gRPC is
+// done with it.
+func (r *release) slice() mem.BufferSlice {
+	if mem.IsBelowBufferPoolingThreshold(len(r.p)) {
+		// gRPC doesn't reference count small buffers, so it would never
+		// call Put.
+		p := make([]byte, len(r.p))
+		copy(p, r.p)
+		r.Put(nil)
+		return mem.BufferSlice{mem.SliceBuffer(p)}
+	}
+	return mem.BufferSlice{mem.NewBuffer(&r.p, r)}
+}
+
+func (r *release) Get(int) *[]byte {
+	panic("poolsgrpc: release.Get called")
+}
+
+func (r *release) Put(*[]byte) {
+	if r.buffer != nil {
+		pools.PutBuffer(r.buffer)
+	}
+	if r.builder != nil {
+		pools.PutBuilder(r.builder)
+	}
+	*r = release{}
+	releasePool.Put(r)
+}
@@ -0,0 +1,83 @@
+package poolsgrpc
+
+import (
+	"strings"
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/sermodigital/pools"
+	"google.golang.org/grpc/mem"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtoCodec(t *testing.T) {
+	in := wrapperspb.String("hello")
+	data, err := ProtoCodec.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := proto.Marshal(in)
+	if got := data.Materialize(); string(got) != string(want) {
+		t.Fatalf("want %x, got %x", want, got)
+	}
+
+	var out wrapperspb.StringValue
+	if err := ProtoCodec.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	data.Free()
+	if out.GetValue() != "hello" {
+		t.Fatalf("want %q, got %q", "hello", out.GetValue())
+	}
+
+	// Split across buffers.
+	split := mem.BufferSlice{mem.SliceBuffer(want[:2]), mem.SliceBuffer(want[2:])}
+	out.Reset()
+	if err := ProtoCodec.Unmarshal(split, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.GetValue() != "hello" {
+		t.Fatalf("want %q, got %q", "hello", out.GetValue())
+	}
+}
+
+type table struct {
+	buf []byte
+	pos flatbuffers.UOffsetT
+}
+
+func (t *table) Init(buf []byte, i flatbuffers.UOffsetT) {
+	t.buf, t.pos = buf, i
+}
+
+func TestFlatbuffersCodec(t *testing.T) {
+	for _, s := range []string{"hello", strings.Repeat("x", 4096)} {
+		testFlatbuffersCodec(t, s)
+	}
+}
+
+func testFlatbuffersCodec(t *testing.T, s string) {
+	b := pools.GetBuilder()
+	b.Finish(b.CreateString(s))
+	want := string(b.FinishedBytes())
+
+	var before, after pools.BuilderStats
+	pools.ReadBuilderStats(&before)
+	data, err := FlatbuffersCodec.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out table
+	if err := FlatbuffersCodec.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	data.Free()
+	pools.ReadBuilderStats(&after)
+	if after.Puts != before.Puts+1 {
+		t.Fatal("want the Builder returned to the pool")
+	}
+	if string(out.buf) != want {
+		t.Fatalf("want %x, got %x", want, out.buf)
+	}
+}
@@ -0,0 +1,123 @@
+// Package poolsjsoniter connects jsoniter's Streams and Iterators to the
+// pools package's Buffers, so programs using jsoniter have one pool of
+// memory instead of two.
+package poolsjsoniter
+
+import (
+	"io"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/sermodigital/pools"
+)
+
+// Stream is a jsoniter.Stream, borrowed from its API's pool, that flushes to
+// a pooled Buffer.
+type Stream struct {
+	*jsoniter.Stream
+	api jsoniter.API
+	buf *pools.Buffer
+}
+
+var streamPool = sync.Pool{
+	New: func() interface{} {
+		return new(Stream)
+	},
+}
+
+// GetStream returns a Stream configured by api, or jsoniter.ConfigDefault if
+// api is nil.
+func GetStream(api jsoniter.API) *Stream {
+	if api == nil {
+		api = jsoniter.ConfigDefault
+	}
+	s := streamPool.Get().(*Stream)
+	s.api = api
+	s.buf = pools.GetBuffer()
+	s.Stream = api.BorrowStream(s.buf)
+	return s
+}
+
+// PutStream returns s, its jsoniter.Stream, and its Buffer to their pools.
+// Slices returned by Bytes may not be used afterward.
+func PutStream(s *Stream) {
+	pools.PutBuffer(s.buf)
+	releaseStream(s)
+}
+
+// releaseStream returns s and its jsoniter.Stream, but not its Buffer, to
+// their pools.
+func releaseStream(s *Stream) {
+	s.Stream.Reset(nil)
+	s.api.ReturnStream(s.Stream)
+	*s = Stream{}
+	streamPool.Put(s)
+}
+
+// Bytes flushes s and returns what has been written so far. The slice is
+// only valid until the next call to a method of s.
+func (s *Stream) Bytes() ([]byte, error) {
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+	return s.buf.Bytes(), s.Error
+}
+
+// Iterator is a jsoniter.Iterator, borrowed from its API's pool, that reads
+// from a pooled Buffer.
+type Iterator struct {
+	*jsoniter.Iterator
+	api jsoniter.API
+	buf *pools.Buffer
+}
+
+var iteratorPool = sync.Pool{
+	New: func() interface{} {
+		return new(Iterator)
+	},
+}
+
+// GetIterator reads all of r into a pooled Buffer and returns an Iterator
+// over it, configured by api, or jsoniter.ConfigDefault if api is nil.
+func GetIterator(api jsoniter.API, r io.Reader) (*Iterator, error) {
+	if api == nil {
+		api = jsoniter.ConfigDefault
+	}
+	b := pools.GetBuffer()
+	if _, err := b.ReadFrom(r); err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	it := iteratorPool.Get().(*Iterator)
+	it.api = api
+	it.buf = b
+	it.Iterator = api.BorrowIterator(b.Bytes())
+	return it, nil
+}
+
+// PutIterator returns it, its jsoniter.Iterator, and its Buffer to their
+// pools. Nothing read from it that refers to its input, such as values
+// decoded into a jsoniter.RawMessage, may be used afterward.
+func PutIterator(it *Iterator) {
+	it.api.ReturnIterator(it.Iterator)
+	pools.PutBuffer(it.buf)
+	*it = Iterator{}
+	iteratorPool.Put(it)
+}
+
+// Marshal returns the JSON encoding of v, built in a pooled Buffer.
+// See Buffer.UnsafeBytes for the lifetime of the result.
+func Marshal(api jsoniter.API, v interface{}) ([]byte, error) {
+	s := GetStream(api)
+	s.WriteVal(v)
+	if err := s.Flush(); err != nil || s.Error != nil {
+		if err == nil {
+			err = s.Error
+		}
+		PutStream(s)
+		return nil, err
+	}
+	b := s.buf
+	releaseStream(s)
+	return b.UnsafeBytes(), nil
+}
@@ -0,0 +1,53 @@
+package poolsjsoniter
+
+import (
+	"strings"
+	"testing"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestStream(t *testing.T) {
+	s := GetStream(nil)
+	defer PutStream(s)
+	s.WriteVal(user{1, "gopher"})
+	p, err := s.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != `{"id":1,"name":"gopher"}` {
+		t.Fatalf("unexpected JSON: %s", p)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	p, err := Marshal(nil, user{1, "gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != `{"id":1,"name":"gopher"}` {
+		t.Fatalf("unexpected JSON: %s", p)
+	}
+	if _, err := Marshal(nil, func() {}); err == nil {
+		t.Fatal("want an error for an unsupported type")
+	}
+}
+
+func TestIterator(t *testing.T) {
+	it, err := GetIterator(nil, strings.NewReader(`{"id":1,"name":"gopher"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer PutIterator(it)
+	var u user
+	it.ReadVal(&u)
+	if it.Error != nil {
+		t.Fatal(it.Error)
+	}
+	if u != (user{1, "gopher"}) {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+}
@@ -0,0 +1,96 @@
+// Package poolsmsgpack encodes and decodes msgpack using the pools package's
+// Buffers and readers.
+package poolsmsgpack
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/sermodigital/pools"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoder is a msgpack.Encoder that writes to a pooled Buffer.
+type Encoder struct {
+	*msgpack.Encoder
+	buf *pools.Buffer
+}
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		return new(Encoder)
+	},
+}
+
+func GetEncoder() *Encoder {
+	e := encoderPool.Get().(*Encoder)
+	e.buf = pools.GetBuffer()
+	e.Encoder = msgpack.GetEncoder()
+	e.Encoder.Reset(e.buf)
+	return e
+}
+
+// PutEncoder returns e and its Buffer to their pools. Slices returned by
+// Bytes may not be used afterward.
+func PutEncoder(e *Encoder) {
+	msgpack.PutEncoder(e.Encoder)
+	pools.PutBuffer(e.buf)
+	e.Encoder, e.buf = nil, nil
+	encoderPool.Put(e)
+}
+
+// Bytes returns what has been encoded so far. The slice is only valid until
+// the next call to a method of e.
+func (e *Encoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// Decoder is a msgpack.Decoder that reads from a pooled bytes.Reader.
+type Decoder struct {
+	*msgpack.Decoder
+	r *bytes.Reader
+}
+
+var decoderPool = sync.Pool{
+	New: func() interface{} {
+		return new(Decoder)
+	},
+}
+
+// GetDecoder returns a Decoder reading p.
+func GetDecoder(p []byte) *Decoder {
+	d := decoderPool.Get().(*Decoder)
+	d.r = pools.GetReaderBytes(p)
+	d.Decoder = msgpack.GetDecoder()
+	d.Decoder.Reset(d.r)
+	return d
+}
+
+func PutDecoder(d *Decoder) {
+	msgpack.PutDecoder(d.Decoder)
+	pools.PutReaderBytes(d.r)
+	d.Decoder, d.r = nil, nil
+	decoderPool.Put(d)
+}
+
+// Marshal returns the msgpack encoding of v, built in a pooled Buffer.
+// See Buffer.UnsafeBytes for the lifetime of the result.
+func Marshal(v interface{}) ([]byte, error) {
+	b := pools.GetBuffer()
+	enc := msgpack.GetEncoder()
+	enc.Reset(b)
+	err := enc.Encode(v)
+	msgpack.PutEncoder(enc)
+	if err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	return b.UnsafeBytes(), nil
+}
+
+// Unmarshal decodes the msgpack-encoded p into v.
+func Unmarshal(p []byte, v interface{}) error {
+	d := GetDecoder(p)
+	defer PutDecoder(d)
+	return d.Decode(v)
+}
@@ -0,0 +1,58 @@
+package poolsmsgpack
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+func TestMarshal(t *testing.T) {
+	in := user{ID: 1, Name: "gopher"}
+	want, err := msgpack.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("want %x, got %x", want, got)
+	}
+
+	var out user
+	if err := Unmarshal(got, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Fatalf("want %+v, got %+v", in, out)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	e := GetEncoder()
+	defer PutEncoder(e)
+	if err := e.EncodeString("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.EncodeInt(7); err != nil {
+		t.Fatal(err)
+	}
+
+	d := GetDecoder(e.Bytes())
+	defer PutDecoder(d)
+	s, err := d.DecodeString()
+	if err != nil || s != "a" {
+		t.Fatalf("want %q, got %q (%v)", "a", s, err)
+	}
+	n, err := d.DecodeInt()
+	if err != nil || n != 7 {
+		t.Fatalf("want 7, got %d (%v)", n, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package poolspgx connects the pools package to pgx batches and COPY.
+package poolspgx
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sermodigital/pools"
+)
+
+// QueueGroups queues query followed by one group of placeholders per row on
+// batch, binding the rows' values in order. The statement is built in a
+// pooled Buffer. Every row must have the same, non-zero number of values.
+//
+//	poolspgx.QueueGroups(batch, "INSERT INTO t (a, b) VALUES", rows)
+func QueueGroups(batch *pgx.Batch, query string, rows [][]interface{}) (*pgx.QueuedQuery, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, errors.New("poolspgx: QueueGroups called without values")
+	}
+	n := len(rows[0])
+	args := make([]interface{}, 0, n*len(rows))
+	for _, row := range rows {
+		if len(row) != n {
+			return nil, errors.New("poolspgx: QueueGroups rows have different lengths")
+		}
+		args = append(args, row...)
+	}
+
+	b := pools.GetBuffer()
+	b.WriteString(query)
+	b.WriteGroups(1, n, len(rows))
+	// The batch holds on to the query until it's sent.
+	stmt := b.String()
+	pools.PutBuffer(b)
+
+	return batch.Queue(stmt, args...), nil
+}
+
+var sourcePool = sync.Pool{
+	New: func() interface{} {
+		return new(CopySource)
+	},
+}
+
+// CopySource is a pgx.CopyFromSource whose rows are stored in a single pooled
+// slice.
+//
+//	src := poolspgx.GetCopySource(2)
+//	defer poolspgx.PutCopySource(src)
+//	for _, u := range users {
+//		src.Append(u.ID, u.Name)
+//	}
+//	conn.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"id", "name"}, src)
+type CopySource struct {
+	width int
+	vals  []interface{}
+	row   int // index of the next row, plus one.
+}
+
+// GetCopySource returns a CopySource for rows with width values.
+func GetCopySource(width int) *CopySource {
+	s := sourcePool.Get().(*CopySource)
+	s.width = width
+	return s
+}
+
+// PutCopySource returns s to the pool. s must not be used afterward.
+func PutCopySource(s *CopySource) {
+	clear(s.vals)
+	s.vals = s.vals[:0]
+	s.row = 0
+	sourcePool.Put(s)
+}
+
+// Append adds a row to s. An error is returned if values doesn't have the
+// width s was created with.
+func (s *CopySource) Append(values ...interface{}) error {
+	if len(values) != s.width {
+		return errors.New("poolspgx: row has the wrong number of values")
+	}
+	s.vals = append(s.vals, values...)
+	return nil
+}
+
+// Len returns the number of rows in s.
+func (s *CopySource) Len() int {
+	if s.width == 0 {
+		return 0
+	}
+	return len(s.vals) / s.width
+}
+
+// Next implements pgx.CopyFromSource.
+func (s *CopySource) Next() bool {
+	if s.row >= s.Len() {
+		return false
+	}
+	s.row++
+	return true
+}
+
+// Values implements pgx.CopyFromSource. The returned slice aliases s.
+func (s *CopySource) Values() ([]interface{}, error) {
+	i := (s.row - 1) * s.width
+	return s.vals[i : i+s.width : i+s.width], nil
+}
+
+// Err implements pgx.CopyFromSource.
+func (s *CopySource) Err() error { return nil }
+
+// Rewind allows s to be read again from the first row.
+func (s *CopySource) Rewind() { s.row = 0 }
+
+var _ pgx.CopyFromSource = (*CopySource)(nil)
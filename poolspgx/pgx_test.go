@@ -0,0 +1,43 @@
+package poolspgx
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestQueueGroups(t *testing.T) {
+	var batch pgx.Batch
+	q, err := QueueGroups(&batch, "INSERT INTO t (a, b) VALUES", [][]interface{}{{1, "x"}, {2, "y"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "INSERT INTO t (a, b) VALUES ($1, $2), ($3, $4)"; q.SQL != want {
+		t.Fatalf("want %q, got %q", want, q.SQL)
+	}
+	if len(q.Arguments) != 4 {
+		t.Fatalf("want 4 arguments, got %d", len(q.Arguments))
+	}
+}
+
+func TestCopySource(t *testing.T) {
+	s := GetCopySource(2)
+	defer PutCopySource(s)
+	s.Append(1, "a")
+	s.Append(2, "b")
+	if err := s.Append(3); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var n int
+	for s.Next() {
+		v, _ := s.Values()
+		n++
+		if v[0] != n {
+			t.Fatalf("row %d: got %v", n, v)
+		}
+	}
+	if n != 2 {
+		t.Fatalf("want 2 rows, got %d", n)
+	}
+}
@@ -0,0 +1,42 @@
+// Package poolsproto marshals and unmarshals protocol buffers using the
+// pools package's Buffers.
+package poolsproto
+
+import (
+	"io"
+
+	"github.com/sermodigital/pools"
+	"google.golang.org/protobuf/proto"
+)
+
+// Marshal returns the wire-format encoding of m, built in a pooled Buffer.
+// See Buffer.UnsafeBytes for the lifetime of the result.
+func Marshal(m proto.Message) ([]byte, error) {
+	return MarshalWith(proto.MarshalOptions{}, m)
+}
+
+// MarshalWith is like Marshal but uses opts.
+func MarshalWith(opts proto.MarshalOptions, m proto.Message) ([]byte, error) {
+	b := pools.GetBufferSize(opts.Size(m))
+	// Size cached the size of every sub-message.
+	opts.UseCachedSize = true
+	p, err := opts.MarshalAppend(b.AvailableBuffer(), m)
+	if err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	b.Write(p)
+	return b.UnsafeBytes(), nil
+}
+
+// UnmarshalReader reads all of r into a pooled Buffer and unmarshals it into
+// m. The Buffer is returned to the pool before UnmarshalReader returns, since
+// proto.Unmarshal copies what it keeps.
+func UnmarshalReader(r io.Reader, m proto.Message) error {
+	b := pools.GetBuffer()
+	defer pools.PutBuffer(b)
+	if _, err := b.ReadFrom(r); err != nil {
+		return err
+	}
+	return proto.Unmarshal(b.Bytes(), m)
+}
@@ -0,0 +1,42 @@
+package poolsproto
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestMarshal(t *testing.T) {
+	m, err := structpb.NewStruct(map[string]interface{}{
+		"name": "gopher",
+		"tags": []interface{}{"a", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Map fields are encoded in random order unless marshaling is
+	// deterministic.
+	opts := proto.MarshalOptions{Deterministic: true}
+	want, err := opts.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := MarshalWith(opts, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("want %x, got %x", want, got)
+	}
+
+	var m2 structpb.Struct
+	if err := UnmarshalReader(bytes.NewReader(got), &m2); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(m, &m2) {
+		t.Fatalf("want %v, got %v", m, &m2)
+	}
+}
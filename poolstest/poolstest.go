@@ -0,0 +1,79 @@
+// Package poolstest helps tests check that they return every Buffer and
+// Builder they take from package pools.
+package poolstest
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sermodigital/pools"
+)
+
+// VerifyNoLeaks fails t if any Buffers or Builders are checked out of the
+// package's pools. Defer it at the start of a test:
+//
+//	defer poolstest.VerifyNoLeaks(t)
+//
+// Objects released by finalizers, such as Buffers whose UnsafeBytes became
+// unreachable, are given a moment to return. The failure includes the
+// output of pools.Report, which lists where the leaked objects were
+// acquired if pools.TrackLeaks was on during the test and the package was
+// built with the poolsdebug tag. Tests that run in parallel with others
+// that use the pools can't be checked this way; use VerifyTestMain.
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	if report := leaks(); report != "" {
+		t.Error(report)
+	}
+}
+
+// VerifyTestMain runs the package's tests and, if they pass, fails the run
+// if they left any Buffers or Builders checked out. Call it from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		poolstest.VerifyTestMain(m)
+//	}
+//
+// It turns on pools.TrackLeaks for the run so that, in debug builds, the
+// report includes where the leaked objects were acquired.
+func VerifyTestMain(m *testing.M) {
+	pools.TrackLeaks(true)
+	code := m.Run()
+	if code == 0 {
+		if report := leaks(); report != "" {
+			fmt.Fprint(os.Stderr, report)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// maxRetries is how many times leaks collects garbage and waits for
+// finalizers before reporting objects as leaked.
+const maxRetries = 20
+
+// leaks returns pools.Report's description of the objects checked out of
+// the package's pools, or "" if there are none.
+func leaks() string {
+	for i := 0; ; i++ {
+		var s pools.Stats
+		var bs pools.BuilderStats
+		pools.ReadStats(&s)
+		pools.ReadBuilderStats(&bs)
+		if s.Outstanding == 0 && bs.Outstanding == 0 {
+			return ""
+		}
+		if i == maxRetries {
+			break
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond << min(i, 5))
+	}
+	var b strings.Builder
+	pools.Report(&b)
+	return b.String()
+}
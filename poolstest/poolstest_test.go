@@ -0,0 +1,35 @@
+package poolstest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sermodigital/pools"
+)
+
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...interface{}) {
+	r.errs = append(r.errs, args[0].(string))
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	var r recorder
+	pools.PutBuffer(pools.GetBuffer())
+	VerifyNoLeaks(&r)
+	if len(r.errs) != 0 {
+		t.Fatalf("want no leaks, got %q", r.errs)
+	}
+
+	b := pools.GetBuffer()
+	VerifyNoLeaks(&r)
+	if len(r.errs) != 1 || !strings.Contains(r.errs[0], "1 Buffers") {
+		t.Fatalf("want a leaked Buffer reported, got %q", r.errs)
+	}
+	pools.PutBuffer(b)
+}
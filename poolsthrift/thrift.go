@@ -0,0 +1,92 @@
+// Package poolsthrift provides Thrift transports backed by the pools
+// package's Buffers.
+package poolsthrift
+
+import (
+	"context"
+	"sync"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/sermodigital/pools"
+)
+
+// Transport is an in-memory thrift.TTransport, like thrift.TMemoryBuffer,
+// that reads from and writes to a pooled Buffer. It also implements
+// thrift.TRichTransport, so protocols use it without wrapping it.
+type Transport struct {
+	*pools.Buffer
+}
+
+var _ thrift.TRichTransport = (*Transport)(nil)
+
+var transportPool = sync.Pool{
+	New: func() interface{} {
+		return new(Transport)
+	},
+}
+
+func GetTransport() *Transport {
+	t := transportPool.Get().(*Transport)
+	t.Buffer = pools.GetBuffer()
+	return t
+}
+
+// PutTransport returns t and its Buffer to their pools. Slices returned by
+// Bytes may not be used afterward.
+func PutTransport(t *Transport) {
+	pools.PutBuffer(t.Buffer)
+	t.Buffer = nil
+	transportPool.Put(t)
+}
+
+// IsOpen always returns true.
+func (t *Transport) IsOpen() bool {
+	return true
+}
+
+// Open does nothing.
+func (t *Transport) Open() error {
+	return nil
+}
+
+// Close discards the Transport's contents. It does not return the Transport
+// to the pool.
+func (t *Transport) Close() error {
+	t.Reset()
+	return nil
+}
+
+// Flush does nothing.
+func (t *Transport) Flush(ctx context.Context) error {
+	return nil
+}
+
+// RemainingBytes returns the number of unread bytes.
+func (t *Transport) RemainingBytes() uint64 {
+	return uint64(t.Len())
+}
+
+// Marshal returns the encoding of s in the protocol built by f, for
+// instance thrift.NewTCompactProtocolFactoryConf(nil). See
+// Buffer.UnsafeBytes for the lifetime of the result.
+func Marshal(ctx context.Context, f thrift.TProtocolFactory, s thrift.TStruct) ([]byte, error) {
+	b := pools.GetBuffer()
+	p := f.GetProtocol(&Transport{Buffer: b})
+	if err := s.Write(ctx, p); err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	if err := p.Flush(ctx); err != nil {
+		pools.PutBuffer(b)
+		return nil, err
+	}
+	return b.UnsafeBytes(), nil
+}
+
+// Unmarshal decodes data into s using the protocol built by f.
+func Unmarshal(ctx context.Context, f thrift.TProtocolFactory, data []byte, s thrift.TStruct) error {
+	t := GetTransport()
+	defer PutTransport(t)
+	t.Write(data)
+	return s.Read(ctx, f.GetProtocol(t))
+}
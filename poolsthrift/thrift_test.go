@@ -0,0 +1,97 @@
+package poolsthrift
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// point implements thrift.TStruct by hand, as the Thrift compiler would
+// generate.
+type point struct {
+	X, Y int32
+}
+
+func (pt *point) Write(ctx context.Context, p thrift.TProtocol) error {
+	if err := p.WriteStructBegin(ctx, "point"); err != nil {
+		return err
+	}
+	for i, v := range []int32{pt.X, pt.Y} {
+		if err := p.WriteFieldBegin(ctx, "", thrift.I32, int16(i+1)); err != nil {
+			return err
+		}
+		if err := p.WriteI32(ctx, v); err != nil {
+			return err
+		}
+		if err := p.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return p.WriteStructEnd(ctx)
+}
+
+func (pt *point) Read(ctx context.Context, p thrift.TProtocol) error {
+	if _, err := p.ReadStructBegin(ctx); err != nil {
+		return err
+	}
+	for {
+		_, typ, id, err := p.ReadFieldBegin(ctx)
+		if err != nil {
+			return err
+		}
+		if typ == thrift.STOP {
+			break
+		}
+		v, err := p.ReadI32(ctx)
+		if err != nil {
+			return err
+		}
+		switch id {
+		case 1:
+			pt.X = v
+		case 2:
+			pt.Y = v
+		}
+		if err := p.ReadFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	return p.ReadStructEnd(ctx)
+}
+
+func TestMarshal(t *testing.T) {
+	ctx := context.Background()
+	for _, f := range []thrift.TProtocolFactory{
+		thrift.NewTBinaryProtocolFactoryConf(nil),
+		thrift.NewTCompactProtocolFactoryConf(nil),
+	} {
+		p, err := Marshal(ctx, f, &point{1, -2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got point
+		if err := Unmarshal(ctx, f, p, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != (point{1, -2}) {
+			t.Fatalf("unexpected point: %+v", got)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	tr := GetTransport()
+	defer PutTransport(tr)
+	tr.WriteString("abc")
+	if n := tr.RemainingBytes(); n != 3 {
+		t.Fatalf("want 3 remaining bytes, got %d", n)
+	}
+	tr.Close()
+	if n := tr.RemainingBytes(); n != 0 {
+		t.Fatalf("want 0 remaining bytes after Close, got %d", n)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build race
+
+package pools
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// raceEnabled reports whether the race detector is on.
+const raceEnabled = true
+
+// raceRecycle calls put, which returns an object to a pool, on another
+// goroutine that first writes to the object's size bytes at obj and to mem.
+// The caller waits for it without synchronizing with it, as far as the race
+// detector is concerned, so any use of the object after it was put is
+// reported as a race with those writes. Otherwise such use would only be
+// reported if the object happened to be reused by another goroutine. The
+// next Get synchronizes with the pool's Put as usual, so it doesn't report
+// a race.
+func raceRecycle(obj unsafe.Pointer, size uintptr, mem []byte, put func()) {
+	done := make(chan struct{})
+	go func() {
+		if obj != nil {
+			runtime.RaceWriteRange(obj, int(size))
+		}
+		if mem = mem[:cap(mem)]; len(mem) > 0 {
+			runtime.RaceWriteRange(unsafe.Pointer(&mem[0]), len(mem))
+		}
+		put()
+		close(done)
+	}()
+	runtime.RaceDisable()
+	<-done
+	runtime.RaceEnable()
+}
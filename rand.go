@@ -0,0 +1,25 @@
+package pools
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+var randPool = sync.Pool{
+	New: func() interface{} {
+		return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	},
+}
+
+// GetRand returns a pooled *rand.Rand. Each one has its own source, seeded
+// randomly when it's created, so callers don't contend on a shared source.
+// It is not safe for concurrent use and must not be used for anything
+// security-sensitive. Return it with PutRand.
+func GetRand() *rand.Rand {
+	return randPool.Get().(*rand.Rand)
+}
+
+// PutRand returns r to the pool. r must not be used afterward.
+func PutRand(r *rand.Rand) {
+	randPool.Put(r)
+}
@@ -0,0 +1,15 @@
+package pools
+
+import "testing"
+
+func TestRand(t *testing.T) {
+	r := GetRand()
+	defer PutRand(r)
+	seen := make(map[uint64]bool)
+	for i := 0; i < 100; i++ {
+		seen[r.Uint64()] = true
+	}
+	if len(seen) < 100 {
+		t.Fatalf("want 100 distinct values, got %d", len(seen))
+	}
+}
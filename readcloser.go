@@ -0,0 +1,45 @@
+package pools
+
+import (
+	"errors"
+	"io"
+)
+
+// errReadAfterClose is returned by a Buffer's ReadCloser after Close.
+var errReadAfterClose = errors.New("pools: read from a Buffer after Close")
+
+// ReadCloser returns an io.ReadCloser that reads from w and returns it to
+// the pool with PutBuffer when closed, so a pooled payload can be handed to
+// an API that closes the bodies it's given, such as http.Request.Body, and
+// recycled once it's consumed. w mustn't be used after calling ReadCloser.
+// Closing more than once does nothing.
+func (w *Buffer) ReadCloser() io.ReadCloser {
+	return &bufferReadCloser{b: w}
+}
+
+type bufferReadCloser struct {
+	b *Buffer
+}
+
+func (r *bufferReadCloser) Read(p []byte) (int, error) {
+	if r.b == nil {
+		return 0, errReadAfterClose
+	}
+	return r.b.Read(p)
+}
+
+// WriteTo lets io.Copy write the Buffer without an intermediate buffer.
+func (r *bufferReadCloser) WriteTo(w io.Writer) (int64, error) {
+	if r.b == nil {
+		return 0, errReadAfterClose
+	}
+	return r.b.WriteTo(w)
+}
+
+func (r *bufferReadCloser) Close() error {
+	if r.b != nil {
+		PutBuffer(r.b)
+		r.b = nil
+	}
+	return nil
+}
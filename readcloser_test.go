@@ -0,0 +1,34 @@
+package pools
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadCloser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	before := Snapshot()
+	b := GetBuffer()
+	b.WriteString("payload")
+	resp, err := http.Post(srv.URL, "text/plain", b.ReadCloser())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	expect(t, "payload", string(body))
+	expect(t, 0, Snapshot().Diff(before).Outstanding)
+
+	rc := GetBuffer().ReadCloser()
+	rc.Close()
+	rc.Close()
+	if _, err := rc.Read(make([]byte, 1)); err == nil {
+		t.Fatal("want an error reading after Close")
+	}
+}
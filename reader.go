@@ -0,0 +1,47 @@
+package pools
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+var bytesReaderPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Reader)
+	},
+}
+
+// GetReaderBytes returns a pooled bytes.Reader reading from p.
+func GetReaderBytes(p []byte) *bytes.Reader {
+	r := bytesReaderPool.Get().(*bytes.Reader)
+	r.Reset(p)
+	return r
+}
+
+// PutReaderBytes returns r to the pool. It drops r's reference to its slice
+// so the pool doesn't keep it alive.
+func PutReaderBytes(r *bytes.Reader) {
+	r.Reset(nil)
+	bytesReaderPool.Put(r)
+}
+
+var stringsReaderPool = sync.Pool{
+	New: func() interface{} {
+		return new(strings.Reader)
+	},
+}
+
+// GetReaderString returns a pooled strings.Reader reading from s.
+func GetReaderString(s string) *strings.Reader {
+	r := stringsReaderPool.Get().(*strings.Reader)
+	r.Reset(s)
+	return r
+}
+
+// PutReaderString returns r to the pool. It drops r's reference to its string
+// so the pool doesn't keep it alive.
+func PutReaderString(r *strings.Reader) {
+	r.Reset("")
+	stringsReaderPool.Put(r)
+}
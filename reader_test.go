@@ -0,0 +1,57 @@
+package pools
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGetReaderBytes(t *testing.T) {
+	r := GetReaderBytes([]byte("abc"))
+	p, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "abc", string(p))
+	PutReaderBytes(r)
+
+	r = GetReaderBytes([]byte("de"))
+	defer PutReaderBytes(r)
+	expect(t, 2, r.Len())
+}
+
+func TestGetReaderString(t *testing.T) {
+	r := GetReaderString("hello")
+	defer PutReaderString(r)
+	p, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "hello", string(p))
+}
+
+// onlyReader hides any io.WriterTo implementation so Copy uses its buffer.
+type onlyReader struct{ io.Reader }
+
+func TestCopy(t *testing.T) {
+	src := strings.Repeat("x", defaultCopyBufSize*2+1)
+	var dst strings.Builder
+	n, err := Copy(&dst, onlyReader{strings.NewReader(src)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, int64(len(src)), n)
+	expect(t, src, dst.String())
+}
+
+func TestCopyBuf(t *testing.T) {
+	p := GetCopyBuf()
+	expect(t, defaultCopyBufSize, len(p))
+	PutCopyBuf(p)
+
+	SetCopyBufSize(1024)
+	defer SetCopyBufSize(defaultCopyBufSize)
+	p = GetCopyBuf()
+	expect(t, 1024, len(p))
+	PutCopyBuf(p)
+}
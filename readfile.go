@@ -0,0 +1,43 @@
+package pools
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+)
+
+// ReadFile is like os.ReadFile but reads the named file into a Buffer from
+// the pool, sized from the file's length when it's known. Put the Buffer
+// back with PutBuffer once its contents are no longer needed. If reading
+// fails the Buffer is put back and nil is returned with the error.
+func ReadFile(name string) (*Buffer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	size := 0
+	if fi, err := f.Stat(); err == nil && fi.Size() < math.MaxInt-bytes.MinRead {
+		size = int(fi.Size())
+	}
+	// ReadFrom grows the Buffer whenever less than MinRead bytes are free,
+	// so leave room for the read that sees EOF.
+	return readAll(GetBufferSize(size+bytes.MinRead), f)
+}
+
+// ReadAll is like io.ReadAll but reads r into a Buffer from the pool. Put the
+// Buffer back with PutBuffer once its contents are no longer needed. If
+// reading fails the Buffer is put back and nil is returned with the error.
+func ReadAll(r io.Reader) (*Buffer, error) {
+	return readAll(GetBuffer(), r)
+}
+
+func readAll(b *Buffer, r io.Reader) (*Buffer, error) {
+	if _, err := b.ReadFrom(r); err != nil {
+		PutBuffer(b)
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,44 @@
+package pools
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadFile(t *testing.T) {
+	want := bytes.Repeat([]byte("0123456789"), 1000)
+	name := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(name, want, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, true, bytes.Equal(want, b.Bytes()))
+	expect(t, true, b.Cap() < 2*len(want)) // not grown while reading.
+	PutBuffer(b)
+
+	if _, err := ReadFile(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Fatalf("want a not-exist error, got %v", err)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	b, err := ReadAll(iotest.OneByteReader(bytes.NewReader([]byte("hello"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "hello", b.String())
+	PutBuffer(b)
+
+	errBoom := errors.New("boom")
+	b, err = ReadAll(io.MultiReader(bytes.NewReader([]byte("x")), iotest.ErrReader(errBoom)))
+	expect(t, errBoom, err)
+	expect(t, (*Buffer)(nil), b)
+}
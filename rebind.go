@@ -0,0 +1,37 @@
+package pools
+
+import "strings"
+
+// Rebind rewrites each '?' placeholder in query to the numbered form used by
+// dialect, starting at 1. Queries without placeholders and queries for
+// dialects that use '?' are returned unchanged.
+//
+//	Rebind("SELECT * FROM t WHERE a = ? AND b = ?", Postgres)
+//	// SELECT * FROM t WHERE a = $1 AND b = $2
+//
+// The result is built in a pooled Buffer and returned via UnsafeString, so
+// the Buffer goes back into the pool once the string is no longer reachable.
+func Rebind(query string, dialect Dialect) string {
+	if !dialect.numbered() {
+		return query
+	}
+	i := strings.IndexByte(query, '?')
+	if i < 0 {
+		return query
+	}
+
+	prefix := dialect.prefix()
+	n := strings.Count(query[i:], "?")
+
+	b := GetBuffer()
+	b.Grow(len(query) + n*len(prefix) + totalWidth(n, 0) - n)
+	for j := 1; i >= 0; j++ {
+		b.WriteString(query[:i])
+		b.WriteString(prefix)
+		b.WriteInt(j)
+		query = query[i+1:]
+		i = strings.IndexByte(query, '?')
+	}
+	b.WriteString(query)
+	return b.UnsafeString()
+}
@@ -0,0 +1,22 @@
+package pools
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	const q = "SELECT * FROM t WHERE a = ? AND b IN (?, ?)"
+	for _, tt := range []struct {
+		d    Dialect
+		want string
+	}{
+		{Postgres, "SELECT * FROM t WHERE a = $1 AND b IN ($2, $3)"},
+		{MySQL, q},
+		{Oracle, "SELECT * FROM t WHERE a = :1 AND b IN (:2, :3)"},
+		{SQLServer, "SELECT * FROM t WHERE a = @p1 AND b IN (@p2, @p3)"},
+	} {
+		expect(t, tt.want, Rebind(q, tt.d))
+	}
+}
+
+func TestRebindNoPlaceholders(t *testing.T) {
+	expect(t, "SELECT 1", Rebind("SELECT 1", Postgres))
+}
@@ -0,0 +1,32 @@
+package pools
+
+import "regexp"
+
+// RegexpReplaceAll is like re.ReplaceAllString: it returns a copy of src with
+// the matches of re replaced by repl, in which $ signs are expanded as by
+// re.Expand. The result is built in a pooled Buffer, so the only allocation
+// beyond finding the matches is the returned string, and src itself is
+// returned if nothing matches.
+func RegexpReplaceAll(re *regexp.Regexp, src, repl string) string {
+	matches := re.FindAllStringSubmatchIndex(src, -1)
+	if matches == nil {
+		return src
+	}
+	b := GetBuffer()
+	defer PutBuffer(b)
+	last := 0
+	for _, m := range matches {
+		b.WriteString(src[last:m[0]])
+		ExpandInto(b, re, repl, src, m)
+		last = m[1]
+	}
+	b.WriteString(src[last:])
+	return b.String()
+}
+
+// ExpandInto is like re.ExpandString but appends the expansion of template
+// to b. match is a result of re.FindStringSubmatchIndex or one of the
+// elements of re.FindAllStringSubmatchIndex for src.
+func ExpandInto(b *Buffer, re *regexp.Regexp, template, src string, match []int) {
+	b.Write(re.ExpandString(b.AvailableBuffer(), template, src, match))
+}
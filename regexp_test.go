@@ -0,0 +1,22 @@
+package pools
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRegexpReplaceAll(t *testing.T) {
+	for _, tt := range []struct {
+		re, src, repl string
+	}{
+		{`a(x*)b`, "-ab-axxb-", "${1}W"},
+		{`a(x*)b`, "-ab-axxb-", "$1"},
+		{`x*`, "abc", "-"},
+		{`(?P<word>\w+)@`, "user@example.com", "<$word>@"},
+		{`secret=\S+`, "id=1 secret=hunter2 ok", "secret=xxx"},
+		{`nomatch`, "unchanged", "x"},
+	} {
+		re := regexp.MustCompile(tt.re)
+		expect(t, re.ReplaceAllString(tt.src, tt.repl), RegexpReplaceAll(re, tt.src, tt.repl))
+	}
+}
@@ -0,0 +1,58 @@
+package pools
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// RenderJSON writes the JSON encoding of v to w as the body of a response
+// with the given status code. v is encoded into a pooled Buffer first, so if
+// encoding fails nothing has been written and the caller may still send an
+// error response.
+//
+//	func serveUser(w http.ResponseWriter, r *http.Request) {
+//		u, err := lookup(r)
+//		...
+//		pools.RenderJSON(w, http.StatusOK, u)
+//	}
+func RenderJSON(w http.ResponseWriter, status int, v interface{}) error {
+	b := GetBuffer()
+	defer PutBuffer(b)
+	if err := json.NewEncoder(b).Encode(v); err != nil {
+		return err
+	}
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("Content-Length", strconv.Itoa(b.Len()))
+	w.WriteHeader(status)
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// ExecuteTemplate executes t with data into a pooled Buffer and, if that
+// succeeds, copies the result to w. Unlike t.Execute, an error partway
+// through leaves w untouched, so a handler can still respond with an error
+// instead of half a page.
+func ExecuteTemplate(w io.Writer, t *template.Template, data interface{}) error {
+	b := GetBuffer()
+	defer PutBuffer(b)
+	if err := t.Execute(b, data); err != nil {
+		return err
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// RenderHTML executes the template in t with the given name and returns
+// the result. See Buffer.UnsafeBytes for the lifetime of the result.
+func RenderHTML(t *template.Template, name string, data interface{}) ([]byte, error) {
+	b := GetBuffer()
+	if err := t.ExecuteTemplate(b, name, data); err != nil {
+		PutBuffer(b)
+		return nil, err
+	}
+	return b.UnsafeBytes(), nil
+}
@@ -0,0 +1,53 @@
+package pools
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenderJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := RenderJSON(rec, http.StatusCreated, map[string]int{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, http.StatusCreated, rec.Code)
+	expect(t, "application/json", rec.Header().Get("Content-Type"))
+	expect(t, "9", rec.Header().Get("Content-Length"))
+	expect(t, "{\"id\":1}\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	if err := RenderJSON(rec, http.StatusOK, func() {}); err == nil {
+		t.Fatal("want an error for an unencodable value")
+	}
+	expect(t, false, rec.Flushed || rec.Body.Len() > 0 || len(rec.Header()) > 0)
+}
+
+func TestExecuteTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse(`<p>{{.}}</p>{{if eq . "fail"}}{{call .}}{{end}}`))
+	var sb strings.Builder
+	if err := ExecuteTemplate(&sb, tmpl, "<gopher>"); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "<p>&lt;gopher&gt;</p>", sb.String())
+
+	sb.Reset()
+	if err := ExecuteTemplate(&sb, tmpl, "fail"); err == nil {
+		t.Fatal("want an error")
+	}
+	expect(t, 0, sb.Len())
+}
+
+func TestRenderHTML(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse(`{{define "item"}}<li>{{.}}</li>{{end}}`))
+	p, err := RenderHTML(tmpl, "item", "a&b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "<li>a&amp;b</li>", string(p))
+	if _, err := RenderHTML(tmpl, "missing", nil); err == nil {
+		t.Fatal("want an error for a missing template")
+	}
+}
@@ -0,0 +1,366 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by ResourcePool.Get after Close.
+var ErrPoolClosed = errors.New("pools: pool is closed")
+
+// ResourceConfig configures a ResourcePool. Only New is required.
+type ResourceConfig[T any] struct {
+	// New opens a resource, for example by dialing a server.
+	New func(ctx context.Context) (T, error)
+
+	// Destroy, if not nil, closes a resource the pool no longer needs.
+	Destroy func(T)
+
+	// Check, if not nil, is called on an idle resource before Get returns
+	// it. If it returns an error the resource is destroyed and Get tries
+	// the next one.
+	Check func(T) error
+
+	// MaxIdle is the most idle resources the pool keeps. Zero means no
+	// limit.
+	MaxIdle int
+
+	// MaxOpen is the most resources, idle or checked out, that may be open
+	// at once. When the limit is reached Get waits for a resource to be
+	// returned. Zero means no limit.
+	MaxOpen int
+
+	// IdleTimeout is how long a resource may sit idle before it's
+	// destroyed. Zero means forever.
+	IdleTimeout time.Duration
+
+	// MaxAttempts is how many times Get calls New before returning its
+	// error. Zero means once.
+	MaxAttempts int
+
+	// Backoff is how long Get waits before retrying New, doubling after
+	// each attempt up to MaxBackoff. Each wait is chosen at random between
+	// zero and the current backoff so that many callers retrying at once
+	// don't stampede. Zero means 10ms.
+	Backoff, MaxBackoff time.Duration
+
+	// Retry, if not nil, reports whether an error from New is transient
+	// and worth retrying. If nil every error is retried.
+	Retry func(error) bool
+}
+
+// ResourcePool is a pool of long-lived resources, such as network client
+// handles, that are expensive to open, may go bad while idle, and must be
+// closed when discarded. It's database/sql's connection pool for any type:
+// unlike a sync.Pool its contents survive GC, and it bounds how many
+// resources are open and idle and for how long.
+//
+// A ResourcePool is safe for concurrent use.
+type ResourcePool[T any] struct {
+	cfg ResourceConfig[T]
+
+	mu      sync.Mutex
+	idle    []idleResource[T] // oldest first.
+	open    int
+	waiters []chan grant[T] // Gets waiting for MaxOpen, oldest first.
+	stats   ResourceStats
+	closed  bool
+	drained chan struct{} // closed once the pool is closed and empty.
+}
+
+type idleResource[T any] struct {
+	v     T
+	since time.Time
+}
+
+// grant is a resource handed to a waiting Get. If ok is false the waiter is
+// instead allowed to open a new resource, unless closed is true.
+type grant[T any] struct {
+	v      T
+	ok     bool
+	closed bool
+}
+
+// ResourceStats describes a ResourcePool. The fields mirror those of
+// database/sql's DBStats, with "Connections" dropped from their names.
+type ResourceStats struct {
+	MaxOpen int // the MaxOpen limit.
+
+	Open  int // resources both in use and idle.
+	InUse int // resources checked out by Get.
+	Idle  int // idle resources.
+
+	WaitCount         int64         // Gets that waited for a resource.
+	WaitDuration      time.Duration // total time Gets spent waiting.
+	MaxIdleClosed     int64         // resources destroyed because of MaxIdle.
+	MaxIdleTimeClosed int64         // resources destroyed because of IdleTimeout.
+}
+
+// NewResourcePool returns a ResourcePool configured by cfg. It panics if
+// cfg.New is nil.
+func NewResourcePool[T any](cfg ResourceConfig[T]) *ResourcePool[T] {
+	if cfg.New == nil {
+		panic("pools: nil New for NewResourcePool")
+	}
+	return &ResourcePool[T]{cfg: cfg, drained: make(chan struct{})}
+}
+
+// Get returns an idle resource that passes the health check, or opens a new
+// one. If MaxOpen resources are open it waits, behind any Gets already
+// waiting, for one to be returned or discarded. It returns ctx.Err() if ctx
+// is done first, ErrPoolClosed after Close, or the error from New. Return
+// the resource with Put, or with Discard if it's broken.
+func (p *ResourcePool[T]) Get(ctx context.Context) (T, error) {
+	var zero T
+	for {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return zero, ErrPoolClosed
+		}
+		expired := p.expire()
+		if n := len(p.idle); n > 0 {
+			r := p.idle[n-1]
+			p.idle[n-1] = idleResource[T]{}
+			p.idle = p.idle[:n-1]
+			p.mu.Unlock()
+			p.destroyAll(expired)
+			if p.cfg.Check != nil && p.cfg.Check(r.v) != nil {
+				p.Discard(r.v)
+				continue
+			}
+			return r.v, nil
+		}
+		if p.cfg.MaxOpen > 0 && p.open >= p.cfg.MaxOpen {
+			c := make(chan grant[T], 1)
+			p.waiters = append(p.waiters, c)
+			p.mu.Unlock()
+			p.destroyAll(expired)
+			return p.wait(ctx, c)
+		}
+		p.open++
+		p.mu.Unlock()
+		p.destroyAll(expired)
+		return p.new(ctx)
+	}
+}
+
+// new opens a resource in a slot already counted in p.open, retrying as
+// configured.
+func (p *ResourcePool[T]) new(ctx context.Context) (T, error) {
+	backoff := p.cfg.Backoff
+	if backoff <= 0 {
+		backoff = 10 * time.Millisecond
+	}
+	for attempt := 1; ; attempt++ {
+		v, err := p.cfg.New(ctx)
+		if err == nil {
+			return v, nil
+		}
+		if attempt >= p.cfg.MaxAttempts || (p.cfg.Retry != nil && !p.cfg.Retry(err)) {
+			p.release()
+			return v, err
+		}
+		t := time.NewTimer(rand.N(backoff + 1))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			p.release()
+			return v, ctx.Err()
+		}
+		backoff *= 2
+		if p.cfg.MaxBackoff > 0 {
+			backoff = min(backoff, p.cfg.MaxBackoff)
+		}
+	}
+}
+
+// wait waits for a resource or an open slot to be granted on c.
+func (p *ResourcePool[T]) wait(ctx context.Context, c chan grant[T]) (T, error) {
+	start := time.Now()
+	var g grant[T]
+	var granted bool
+	select {
+	case g = <-c:
+		granted = true
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	p.stats.WaitCount++
+	p.stats.WaitDuration += time.Since(start)
+	if granted {
+		p.mu.Unlock()
+		switch {
+		case g.ok:
+			return g.v, nil
+		case g.closed:
+			var zero T
+			return zero, ErrPoolClosed
+		}
+		return p.new(ctx)
+	}
+	i := slices.Index(p.waiters, c)
+	if i >= 0 {
+		p.waiters = slices.Delete(p.waiters, i, i+1)
+	}
+	p.mu.Unlock()
+	if i < 0 {
+		// Something was granted concurrently; pass it on.
+		if g := <-c; g.ok {
+			p.Put(g.v)
+		} else if !g.closed {
+			p.release()
+		}
+	}
+	var zero T
+	return zero, ctx.Err()
+}
+
+// nextWaiter removes and returns the oldest waiting Get, or nil if there
+// are none. p.mu must be held.
+func (p *ResourcePool[T]) nextWaiter() chan grant[T] {
+	if len(p.waiters) == 0 {
+		return nil
+	}
+	c := p.waiters[0]
+	p.waiters[0] = nil
+	p.waiters = p.waiters[1:]
+	return c
+}
+
+// release gives up an open slot, handing it to a waiting Get if there is
+// one.
+func (p *ResourcePool[T]) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c := p.nextWaiter(); c != nil {
+		c <- grant[T]{}
+		return
+	}
+	p.open--
+	p.checkDrained()
+}
+
+// checkDrained signals Close if the pool is closed and every resource has
+// been destroyed. p.mu must be held.
+func (p *ResourcePool[T]) checkDrained() {
+	if !p.closed || p.open > 0 {
+		return
+	}
+	select {
+	case <-p.drained:
+	default:
+		close(p.drained)
+	}
+}
+
+// Put returns v to the pool, handing it straight to the oldest waiting Get
+// if there is one, or destroys it if MaxIdle resources are already idle or
+// the pool is closed.
+func (p *ResourcePool[T]) Put(v T) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.Discard(v)
+		return
+	}
+	if c := p.nextWaiter(); c != nil {
+		c <- grant[T]{v: v, ok: true}
+		p.mu.Unlock()
+		return
+	}
+	expired := p.expire()
+	full := p.cfg.MaxIdle > 0 && len(p.idle) >= p.cfg.MaxIdle
+	if full {
+		p.stats.MaxIdleClosed++
+	} else {
+		p.idle = append(p.idle, idleResource[T]{v: v, since: time.Now()})
+	}
+	p.mu.Unlock()
+	p.destroyAll(expired)
+	if full {
+		p.Discard(v)
+	}
+}
+
+// Discard destroys v, a resource from Get that's broken or no longer
+// needed, making room for a new one.
+func (p *ResourcePool[T]) Discard(v T) {
+	p.release()
+	if p.cfg.Destroy != nil {
+		p.cfg.Destroy(v)
+	}
+}
+
+// Close destroys the pool's idle resources, fails waiting and future Gets
+// with ErrPoolClosed, and waits for the resources that are checked out to be
+// returned, destroying them too. If ctx is done first Close returns
+// ctx.Err(); the remaining resources are still destroyed as they're
+// returned. It's safe to call Close more than once.
+func (p *ResourcePool[T]) Close(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	for c := p.nextWaiter(); c != nil; c = p.nextWaiter() {
+		c <- grant[T]{closed: true}
+	}
+	p.checkDrained()
+	p.mu.Unlock()
+	p.destroyAll(idle)
+
+	select {
+	case <-p.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReadStats populates s with the pool's statistics.
+func (p *ResourcePool[T]) ReadStats(s *ResourceStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	*s = p.stats
+	s.MaxOpen = p.cfg.MaxOpen
+	s.Open = p.open
+	s.Idle = len(p.idle)
+	s.InUse = p.open - len(p.idle)
+}
+
+// expire removes and returns the resources that have been idle for longer
+// than IdleTimeout. p.mu must be held.
+func (p *ResourcePool[T]) expire() []idleResource[T] {
+	if p.cfg.IdleTimeout <= 0 || len(p.idle) == 0 {
+		return nil
+	}
+	deadline := time.Now().Add(-p.cfg.IdleTimeout)
+	n := 0
+	for n < len(p.idle) && p.idle[n].since.Before(deadline) {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	p.stats.MaxIdleTimeClosed += int64(n)
+	expired := append([]idleResource[T](nil), p.idle[:n]...)
+	rest := copy(p.idle, p.idle[n:])
+	clear(p.idle[rest:])
+	p.idle = p.idle[:rest]
+	return expired
+}
+
+func (p *ResourcePool[T]) destroyAll(rs []idleResource[T]) {
+	for _, r := range rs {
+		p.Discard(r.v)
+	}
+}
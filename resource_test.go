@@ -0,0 +1,223 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testResource struct {
+	id        int
+	healthy   bool
+	destroyed bool
+}
+
+func TestResourcePool(t *testing.T) {
+	var opened int
+	p := NewResourcePool(ResourceConfig[*testResource]{
+		New: func(context.Context) (*testResource, error) {
+			opened++
+			return &testResource{id: opened, healthy: true}, nil
+		},
+		Destroy: func(r *testResource) { r.destroyed = true },
+		Check: func(r *testResource) error {
+			if !r.healthy {
+				return errors.New("unhealthy")
+			}
+			return nil
+		},
+		MaxIdle: 1,
+		MaxOpen: 2,
+	})
+	ctx := context.Background()
+
+	a, err := p.Get(ctx)
+	expect(t, nil, err)
+	b, err := p.Get(ctx)
+	expect(t, nil, err)
+	short, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	_, err = p.Get(short)
+	expect(t, context.DeadlineExceeded, err)
+
+	p.Put(a)
+	p.Put(b) // over MaxIdle
+	expect(t, true, b.destroyed)
+	c, err := p.Get(ctx)
+	expect(t, nil, err)
+	expect(t, a, c)
+
+	a.healthy = false
+	p.Put(a)
+	c, err = p.Get(ctx)
+	expect(t, nil, err)
+	expect(t, true, a.destroyed)
+	expect(t, 3, c.id)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = p.Get(canceled)
+	expect(t, context.Canceled, err)
+}
+
+func TestResourcePoolIdleTimeout(t *testing.T) {
+	p := NewResourcePool(ResourceConfig[*testResource]{
+		New: func(context.Context) (*testResource, error) {
+			return new(testResource), nil
+		},
+		Destroy:     func(r *testResource) { r.destroyed = true },
+		IdleTimeout: time.Millisecond,
+	})
+	a, _ := p.Get(context.Background())
+	p.Put(a)
+	time.Sleep(5 * time.Millisecond)
+	b, _ := p.Get(context.Background())
+	expect(t, true, a.destroyed)
+	if a == b {
+		t.Fatal("want a new resource after the idle timeout")
+	}
+}
+
+func TestResourcePoolWait(t *testing.T) {
+	var opened atomic.Int64
+	p := NewResourcePool(ResourceConfig[int]{
+		New: func(context.Context) (int, error) {
+			return int(opened.Add(1)), nil
+		},
+		MaxOpen: 1,
+	})
+	ctx := context.Background()
+	a, _ := p.Get(ctx)
+
+	// Waiters are served in order: the first gets a returned resource and
+	// the second a slot freed by Discard.
+	got := make(chan int)
+	go func() {
+		v, _ := p.Get(ctx)
+		got <- v
+	}()
+	waitFor(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return len(p.waiters) == 1
+	})
+	go func() {
+		v, _ := p.Get(ctx)
+		got <- v
+	}()
+	waitFor(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return len(p.waiters) == 2
+	})
+
+	p.Put(a)
+	expect(t, a, <-got)
+	p.Discard(a)
+	expect(t, 2, <-got)
+
+	var s ResourceStats
+	p.ReadStats(&s)
+	expect(t, 1, s.MaxOpen)
+	expect(t, 1, s.Open)
+	expect(t, 1, s.InUse)
+	expect(t, int64(2), s.WaitCount)
+	if s.WaitDuration <= 0 {
+		t.Fatal("want a positive WaitDuration")
+	}
+}
+
+// waitFor polls cond until it's true, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestResourcePoolRetry(t *testing.T) {
+	transient, fatal := errors.New("transient"), errors.New("fatal")
+	var errs []error
+	p := NewResourcePool(ResourceConfig[int]{
+		New: func(context.Context) (int, error) {
+			if len(errs) == 0 {
+				return 1, nil
+			}
+			err := errs[0]
+			errs = errs[1:]
+			return 0, err
+		},
+		MaxAttempts: 3,
+		Backoff:     time.Microsecond,
+		Retry:       func(err error) bool { return err == transient },
+		MaxOpen:     1,
+	})
+	ctx := context.Background()
+
+	errs = []error{transient, transient}
+	v, err := p.Get(ctx)
+	expect(t, nil, err)
+	expect(t, 1, v)
+	p.Discard(v)
+
+	errs = []error{transient, transient, transient}
+	_, err = p.Get(ctx)
+	expect(t, transient, err)
+	expect(t, 0, len(errs))
+
+	errs = []error{fatal, transient}
+	_, err = p.Get(ctx)
+	expect(t, fatal, err)
+	expect(t, 1, len(errs))
+
+	// Failed attempts give up their slot.
+	errs = nil
+	v, err = p.Get(ctx)
+	expect(t, nil, err)
+	expect(t, 1, v)
+}
+
+func TestResourcePoolClose(t *testing.T) {
+	var destroyed atomic.Int64
+	p := NewResourcePool(ResourceConfig[int]{
+		New:     func(context.Context) (int, error) { return 1, nil },
+		Destroy: func(int) { destroyed.Add(1) },
+		MaxOpen: 2,
+	})
+	ctx := context.Background()
+	a, _ := p.Get(ctx)
+	p.Put(a)
+	expect(t, nil, p.Close(ctx))
+	expect(t, int64(1), destroyed.Load())
+
+	destroyed.Store(0)
+	p = NewResourcePool(p.cfg)
+	a, _ = p.Get(ctx)
+	b, _ := p.Get(ctx)
+	waiting := make(chan error)
+	go func() {
+		_, err := p.Get(ctx)
+		waiting <- err
+	}()
+	waitFor(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return len(p.waiters) == 1
+	})
+
+	short, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	expect(t, context.DeadlineExceeded, p.Close(short))
+	expect(t, ErrPoolClosed, <-waiting)
+	_, err := p.Get(ctx)
+	expect(t, ErrPoolClosed, err)
+
+	p.Put(a)
+	p.Discard(b)
+	expect(t, nil, p.Close(ctx))
+	expect(t, int64(2), destroyed.Load())
+}
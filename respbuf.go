@@ -0,0 +1,138 @@
+package pools
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Streamer is implemented by handlers that stream their responses and
+// should not be wrapped by BufferMiddleware.
+type Streamer interface {
+	// Streams reports whether the handler streams its responses.
+	Streams() bool
+}
+
+// BufferMiddleware returns an http.Handler that holds next's response in a
+// pooled Buffer and sends it, with a Content-Length, once next returns. Until
+// then, nothing has been sent, so next or a middleware it's wrapped in can
+// inspect and rewrite the response through the ResponseBuffer it's given:
+//
+//	func serve(w http.ResponseWriter, r *http.Request) {
+//		if err := render(w, r); err != nil {
+//			rb := w.(*pools.ResponseBuffer)
+//			rb.Reset()
+//			http.Error(rb, err.Error(), http.StatusInternalServerError)
+//		}
+//	}
+//
+// If next implements Streamer and Streams returns true, it is called with
+// the original ResponseWriter. Handlers can also switch to streaming partway
+// through a response by calling ResponseBuffer.Stream or flushing it.
+func BufferMiddleware(next http.Handler) http.Handler {
+	if s, ok := next.(Streamer); ok && s.Streams() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rb := &ResponseBuffer{
+			ResponseWriter: w,
+			buf:            GetBuffer(),
+			status:         http.StatusOK,
+		}
+		defer rb.finish()
+		next.ServeHTTP(rb, r)
+	})
+}
+
+// ResponseBuffer is the http.ResponseWriter BufferMiddleware passes to its
+// handler.
+type ResponseBuffer struct {
+	http.ResponseWriter
+	buf    *Buffer // nil once streaming.
+	status int
+}
+
+// WriteHeader records the status code to send. It may be called more than
+// once before the response is sent; the last call wins.
+func (w *ResponseBuffer) WriteHeader(status int) {
+	if w.buf == nil {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+}
+
+func (w *ResponseBuffer) Write(p []byte) (int, error) {
+	if w.buf == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.buf.Write(p)
+}
+
+// Status returns the status code that will be sent.
+func (w *ResponseBuffer) Status() int {
+	return w.status
+}
+
+// Body returns the response body written so far. It returns nil once the
+// ResponseBuffer is streaming.
+func (w *ResponseBuffer) Body() []byte {
+	if w.buf == nil {
+		return nil
+	}
+	return w.buf.Bytes()
+}
+
+// Reset discards the status code and body written so far. It does not reset
+// the header. Reset does nothing once the ResponseBuffer is streaming.
+func (w *ResponseBuffer) Reset() {
+	if w.buf != nil {
+		w.buf.Reset()
+		w.status = http.StatusOK
+	}
+}
+
+// ETag returns a strong entity tag derived from the body written so far, or
+// "" once the ResponseBuffer is streaming.
+func (w *ResponseBuffer) ETag() string {
+	if w.buf == nil {
+		return ""
+	}
+	return `"` + strconv.FormatUint(w.buf.Sum64(), 16) + `"`
+}
+
+// Stream sends the header and the body written so far, after which writes
+// go straight to the underlying ResponseWriter.
+func (w *ResponseBuffer) Stream() {
+	if w.buf == nil {
+		return
+	}
+	b := w.buf
+	w.buf = nil
+	w.ResponseWriter.WriteHeader(w.status)
+	if b.Len() > 0 {
+		w.ResponseWriter.Write(b.Bytes())
+	}
+	PutBuffer(b)
+}
+
+// Flush calls Stream and flushes the underlying ResponseWriter.
+func (w *ResponseBuffer) Flush() {
+	w.Stream()
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap allows http.ResponseController to reach the underlying
+// ResponseWriter.
+func (w *ResponseBuffer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *ResponseBuffer) finish() {
+	if w.buf == nil {
+		return
+	}
+	if h := w.Header(); h.Get("Content-Length") == "" {
+		h.Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	}
+	w.Stream()
+}
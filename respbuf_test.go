@@ -0,0 +1,57 @@
+package pools
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type streamingHandler struct{ http.HandlerFunc }
+
+func (streamingHandler) Streams() bool { return true }
+
+func TestBufferMiddleware(t *testing.T) {
+	h := BufferMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rb := w.(*ResponseBuffer)
+		io.WriteString(w, "partial")
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == "/fail" {
+			rb.Reset()
+			http.Error(rb, "failed", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", rb.ETag())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	expect(t, http.StatusOK, w.Code)
+	expect(t, "partial", w.Body.String())
+	expect(t, "7", w.Header().Get("Content-Length"))
+	if etag := w.Header().Get("ETag"); len(etag) < 3 || etag[0] != '"' {
+		t.Fatalf("bad ETag %q", etag)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+	expect(t, http.StatusInternalServerError, w.Code)
+	expect(t, "failed\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	BufferMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "a")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "b")
+	})).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	expect(t, true, w.Flushed)
+	expect(t, "ab", w.Body.String())
+	expect(t, "", w.Header().Get("Content-Length"))
+
+	s := streamingHandler{func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(*ResponseBuffer); ok {
+			t.Error("streaming handler was buffered")
+		}
+	}}
+	BufferMiddleware(s).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -0,0 +1,85 @@
+package pools
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// NewRingPool returns a Pool backed by a bounded, lock-free ring that
+// retains up to n idle Buffers, rounded up to a power of two. Like
+// NewPinnedPool its contents survive GC, but Get and Put never block each
+// other, which keeps latency predictable when many goroutines share the
+// pool. NewRingPool panics if n <= 0.
+func NewRingPool(n int, opts ...Option) *Pool {
+	if n <= 0 {
+		panic("pools: non-positive size for NewRingPool")
+	}
+	size := uint64(1) << bits.Len(uint(n-1))
+	return newPool(opts, func(func() *Buffer) freeList {
+		r := &ring{mask: size - 1, slots: make([]ringSlot, size)}
+		for i := range r.slots {
+			r.slots[i].seq.Store(uint64(i))
+		}
+		return r
+	})
+}
+
+// ring is Dmitry Vyukov's bounded multi-producer, multi-consumer queue. Each
+// slot's sequence number says whether it's ready to be written (seq == pos)
+// or read (seq == pos+1) by the goroutine holding position pos.
+type ring struct {
+	_    [64]byte // keep head and tail on separate cache lines.
+	head atomic.Uint64
+	_    [56]byte
+	tail atomic.Uint64
+	_    [56]byte
+
+	mask  uint64
+	slots []ringSlot
+}
+
+type ringSlot struct {
+	seq atomic.Uint64
+	b   *Buffer
+}
+
+func (r *ring) get() *Buffer {
+	pos := r.head.Load()
+	for {
+		s := &r.slots[pos&r.mask]
+		switch d := int64(s.seq.Load() - (pos + 1)); {
+		case d == 0:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				b := s.b
+				s.b = nil
+				s.seq.Store(pos + r.mask + 1)
+				return b
+			}
+			pos = r.head.Load()
+		case d < 0:
+			return nil // empty
+		default:
+			pos = r.head.Load()
+		}
+	}
+}
+
+func (r *ring) put(b *Buffer) bool {
+	pos := r.tail.Load()
+	for {
+		s := &r.slots[pos&r.mask]
+		switch d := int64(s.seq.Load() - pos); {
+		case d == 0:
+			if r.tail.CompareAndSwap(pos, pos+1) {
+				s.b = b
+				s.seq.Store(pos + 1)
+				return true
+			}
+			pos = r.tail.Load()
+		case d < 0:
+			return false // full
+		default:
+			pos = r.tail.Load()
+		}
+	}
+}
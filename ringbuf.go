@@ -0,0 +1,133 @@
+package pools
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+	"sync"
+)
+
+// ErrRingBufferFull is returned by writes that don't fit in a RingBuffer.
+var ErrRingBufferFull = errors.New("pools: RingBuffer is full")
+
+var ringBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(RingBuffer)
+	},
+}
+
+// RingBuffer is a fixed-capacity FIFO of bytes that wraps around instead of
+// compacting, for parsing a protocol incrementally as bytes arrive on a
+// connection. Its capacity is a power of two. It is not safe for concurrent
+// use.
+type RingBuffer struct {
+	p    []byte // from GetBytes; len(p) is a power of two.
+	r, w uint   // read and write counts; their difference is the length.
+}
+
+// GetRingBuffer returns an empty RingBuffer from the pool that holds at
+// least n bytes. Return it with PutRingBuffer.
+func GetRingBuffer(n int) *RingBuffer {
+	rb := ringBufferPool.Get().(*RingBuffer)
+	p := GetBytes(n)
+	// GetBytes doesn't round up sizes it doesn't pool, or any size while
+	// pooling is disabled, but mask needs a power of two.
+	if c := cap(p); c == 0 || c&(c-1) != 0 {
+		p = make([]byte, 1<<bits.Len(uint(max(n, 1)-1)))
+	}
+	rb.p = p[:cap(p)]
+	return rb
+}
+
+// PutRingBuffer returns rb and its memory to their pools.
+func PutRingBuffer(rb *RingBuffer) {
+	PutBytes(rb.p)
+	rb.p = nil
+	rb.r, rb.w = 0, 0
+	ringBufferPool.Put(rb)
+}
+
+func (rb *RingBuffer) mask(i uint) int {
+	return int(i & uint(len(rb.p)-1))
+}
+
+// Len returns the number of unread bytes.
+func (rb *RingBuffer) Len() int {
+	return int(rb.w - rb.r)
+}
+
+// Cap returns the most bytes rb can hold.
+func (rb *RingBuffer) Cap() int {
+	return len(rb.p)
+}
+
+// Free returns the number of bytes that can be written before rb is full.
+func (rb *RingBuffer) Free() int {
+	return rb.Cap() - rb.Len()
+}
+
+// Reset discards the unread bytes.
+func (rb *RingBuffer) Reset() {
+	rb.r, rb.w = 0, 0
+}
+
+// Write writes as much of p as fits. If that isn't all of it, it returns
+// ErrRingBufferFull.
+func (rb *RingBuffer) Write(p []byte) (int, error) {
+	n := min(len(p), rb.Free())
+	i := rb.mask(rb.w)
+	c := copy(rb.p[i:], p[:n])
+	copy(rb.p, p[c:n])
+	rb.w += uint(n)
+	if n < len(p) {
+		return n, ErrRingBufferFull
+	}
+	return n, nil
+}
+
+// Peek copies unread bytes into p without consuming them, returning how many
+// were copied.
+func (rb *RingBuffer) Peek(p []byte) int {
+	n := min(len(p), rb.Len())
+	i := rb.mask(rb.r)
+	c := copy(p[:n], rb.p[i:])
+	copy(p[c:n], rb.p)
+	return n
+}
+
+// Read reads and consumes unread bytes, returning io.EOF if there are none.
+func (rb *RingBuffer) Read(p []byte) (int, error) {
+	if rb.Len() == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+	n := rb.Peek(p)
+	rb.r += uint(n)
+	return n, nil
+}
+
+// Discard consumes up to n unread bytes, returning how many were discarded.
+func (rb *RingBuffer) Discard(n int) int {
+	n = min(max(n, 0), rb.Len())
+	rb.r += uint(n)
+	return n
+}
+
+// Fill makes a single call to r.Read into rb's free space, as a
+// bufio.Reader does when it's empty. It returns ErrRingBufferFull if there's
+// no free space.
+func (rb *RingBuffer) Fill(r io.Reader) (int, error) {
+	if rb.Free() == 0 {
+		return 0, ErrRingBufferFull
+	}
+	if rb.Len() == 0 {
+		// Start from the beginning to get the most room.
+		rb.r, rb.w = 0, 0
+	}
+	i, end := rb.mask(rb.w), len(rb.p)
+	if j := rb.mask(rb.r); j > i {
+		end = j
+	}
+	n, err := r.Read(rb.p[i:end])
+	rb.w += uint(n)
+	return n, err
+}
@@ -0,0 +1,74 @@
+package pools
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRingBuffer(t *testing.T) {
+	rb := GetRingBuffer(100)
+	defer PutRingBuffer(rb)
+	expect(t, 128, rb.Cap())
+
+	p := make([]byte, 100)
+	// Wrap around a few times.
+	for i := 0; i < 5; i++ {
+		want := strings.Repeat(string(rune('a'+i)), 90)
+		if n, err := io.WriteString(rb, want); n != 90 || err != nil {
+			t.Fatalf("write: %d, %v", n, err)
+		}
+		n, _ := rb.Read(p)
+		expect(t, want, string(p[:n]))
+	}
+
+	rb.Write(make([]byte, 100))
+	if n, err := rb.Write(make([]byte, 100)); n != 28 || err != ErrRingBufferFull {
+		t.Fatalf("want 28 and ErrRingBufferFull, got %d and %v", n, err)
+	}
+	expect(t, 0, rb.Free())
+	expect(t, 128, rb.Discard(200))
+	if _, err := rb.Read(p); err != io.EOF {
+		t.Fatalf("want io.EOF, got %v", err)
+	}
+
+	rb.Reset()
+	rb.Write(make([]byte, 120))
+	rb.Discard(120)
+	rb.Write([]byte("abc"))
+	n, err := rb.Fill(strings.NewReader("defghijklmnop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n = rb.Peek(p)
+	expect(t, "abcdefghijklmnop"[:n], string(p[:n]))
+	expect(t, true, n > 3)
+}
+
+func TestRingBufferUnpooled(t *testing.T) {
+	DisablePooling(true)
+	defer DisablePooling(false)
+
+	for _, n := range []int{100, 3 << 20} {
+		rb := GetRingBuffer(n)
+		if c := rb.Cap(); c < n || c&(c-1) != 0 {
+			t.Fatalf("GetRingBuffer(%d): want a power of two cap, got %d", n, c)
+		}
+		// Wrap around the end.
+		rb.Write(make([]byte, rb.Cap()-10))
+		rb.Discard(rb.Cap() - 10)
+		want := strings.Repeat("a", 50) + strings.Repeat("b", 50)
+		io.WriteString(rb, want[:50])
+		io.WriteString(rb, want[50:])
+		p := make([]byte, 100)
+		m, _ := rb.Read(p)
+		expect(t, want, string(p[:m]))
+		PutRingBuffer(rb)
+	}
+}
+
+func TestRingBufferLarge(t *testing.T) {
+	rb := GetRingBuffer(3 << 20)
+	defer PutRingBuffer(rb)
+	expect(t, 4<<20, rb.Cap())
+}
@@ -0,0 +1,49 @@
+package pools
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// scanBufSize is the size of the slice a Scanner starts with. It matches
+// bufio.Scanner's.
+const scanBufSize = 4 << 10
+
+var scannerPool = sync.Pool{
+	New: func() interface{} {
+		return &Scanner{Scanner: new(bufio.Scanner)}
+	},
+}
+
+// Scanner is a bufio.Scanner whose buffer comes from GetBytes.
+type Scanner struct {
+	*bufio.Scanner
+	buf []byte
+}
+
+// GetScanner returns a pooled Scanner reading from r that splits lines, like
+// bufio.NewScanner. Tokens may be up to maxToken bytes long; if maxToken <= 0,
+// bufio.MaxScanTokenSize is used. Return it with PutScanner.
+func GetScanner(r io.Reader, maxToken int) *Scanner {
+	if maxToken <= 0 {
+		maxToken = bufio.MaxScanTokenSize
+	}
+	s := scannerPool.Get().(*Scanner)
+	// bufio.Scanner has no Reset, so overwrite the pooled one.
+	*s.Scanner = *bufio.NewScanner(r)
+	s.buf = GetBytes(min(scanBufSize, maxToken))
+	s.Buffer(s.buf, maxToken)
+	return s
+}
+
+// PutScanner returns s and its buffer to their pools. Slices returned by
+// Bytes may not be used afterward.
+func PutScanner(s *Scanner) {
+	// If the Scanner outgrew its buffer, it no longer refers to it, and the
+	// larger one is left to the garbage collector.
+	PutBytes(s.buf)
+	s.buf = nil
+	*s.Scanner = bufio.Scanner{}
+	scannerPool.Put(s)
+}
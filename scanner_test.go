@@ -0,0 +1,24 @@
+package pools
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	s := GetScanner(strings.NewReader("a\nbb\nccc\n"), 0)
+	var lines []string
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	expect(t, nil, s.Err())
+	expect(t, "a,bb,ccc", strings.Join(lines, ","))
+	PutScanner(s)
+
+	s = GetScanner(strings.NewReader(strings.Repeat("x", 100)+"\n"), 10)
+	defer PutScanner(s)
+	s.Split(bufio.ScanWords)
+	expect(t, false, s.Scan())
+	expect(t, bufio.ErrTooLong, s.Err())
+}
@@ -0,0 +1,95 @@
+package pools
+
+import (
+	"context"
+	"sync"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Scope hands out pooled objects and returns all of them to their pools at
+// once, for example at the end of a request. A Scope is safe for concurrent
+// use.
+type Scope struct {
+	mu       sync.Mutex
+	buffers  []*Buffer
+	builders []*flatbuffers.Builder
+
+	bufferPool  BufferPool
+	builderPool BuilderPool
+}
+
+var scopePool = sync.Pool{
+	New: func() interface{} {
+		return new(Scope)
+	},
+}
+
+func GetScope() *Scope {
+	return GetScopeFrom(Buffers, Builders)
+}
+
+// GetScopeFrom is like GetScope but the Scope's objects come from, and are
+// returned to, the given pools.
+func GetScopeFrom(buffers BufferPool, builders BuilderPool) *Scope {
+	s := scopePool.Get().(*Scope)
+	s.bufferPool, s.builderPool = buffers, builders
+	return s
+}
+
+// PutScope returns every object handed out by s to its pool, then returns s
+// to its pool. None of the objects may be used afterward.
+func PutScope(s *Scope) {
+	s.Release()
+	s.bufferPool, s.builderPool = nil, nil
+	scopePool.Put(s)
+}
+
+// Buffer returns a Buffer from the pool that's returned when s is released.
+func (s *Scope) Buffer() *Buffer {
+	b := s.bufferPool.Get()
+	s.mu.Lock()
+	s.buffers = append(s.buffers, b)
+	s.mu.Unlock()
+	return b
+}
+
+// Builder returns a Builder from the pool that's returned when s is
+// released.
+func (s *Scope) Builder() *flatbuffers.Builder {
+	b := s.builderPool.Get()
+	s.mu.Lock()
+	s.builders = append(s.builders, b)
+	s.mu.Unlock()
+	return b
+}
+
+// Release returns every object handed out by s to its pool. s can be reused
+// afterward.
+func (s *Scope) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, b := range s.buffers {
+		s.bufferPool.Put(b)
+		s.buffers[i] = nil
+	}
+	s.buffers = s.buffers[:0]
+	for i, b := range s.builders {
+		s.builderPool.Put(b)
+		s.builders[i] = nil
+	}
+	s.builders = s.builders[:0]
+}
+
+type scopeKey struct{}
+
+// NewContext returns a copy of ctx that carries s.
+func NewContext(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// FromContext returns the Scope carried by ctx, if any.
+func FromContext(ctx context.Context) (*Scope, bool) {
+	s, ok := ctx.Value(scopeKey{}).(*Scope)
+	return s, ok
+}
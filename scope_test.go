@@ -0,0 +1,31 @@
+package pools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScope(t *testing.T) {
+	var before, after BuilderStats
+	ReadBuilderStats(&before)
+	n := bufferStats.outstanding.Load()
+
+	s := GetScope()
+	ctx := NewContext(context.Background(), s)
+	got, ok := FromContext(ctx)
+	expect(t, true, ok)
+	expect(t, s, got)
+
+	got.Buffer().WriteString("hello")
+	got.Buffer()
+	got.Builder()
+	expect(t, n+2, bufferStats.outstanding.Load())
+
+	PutScope(s)
+	ReadBuilderStats(&after)
+	expect(t, n, bufferStats.outstanding.Load())
+	expect(t, before.Puts+1, after.Puts)
+
+	_, ok = FromContext(context.Background())
+	expect(t, false, ok)
+}
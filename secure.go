@@ -0,0 +1,172 @@
+package pools
+
+import (
+	"errors"
+	"math/bits"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// ErrSecureBufferFull is returned by writes that don't fit in a
+// SecureBuffer.
+var ErrSecureBufferFull = errors.New("pools: SecureBuffer is full")
+
+// ErrNotLocked is returned by GetSecureBufferE if a SecureBuffer's memory
+// can't be locked into RAM.
+var ErrNotLocked = errors.New("pools: SecureBuffer memory could not be locked")
+
+// SecureBuffers come in power-of-two multiples of the page size up to
+// 1<<maxSecureShift pages. Larger ones are released when they're put.
+const maxSecureShift = 7
+
+var (
+	pageSize    = os.Getpagesize()
+	securePools [maxSecureShift + 1]sync.Pool
+)
+
+// secureMem is the memory behind a SecureBuffer. Where supported it is
+// mapped outside the Go heap and locked so it is never swapped to disk.
+type secureMem struct {
+	p      []byte
+	mapped bool
+	locked bool
+}
+
+// SecureBuffer is a fixed-size buffer for key material, credentials and
+// other secrets. Where the platform supports it, its memory lives outside
+// the Go heap, so the garbage collector never copies it, and is locked into
+// RAM, so it's never written to swap. It never grows, since that would copy
+// its contents into new memory, and it is zeroed when it's returned to the
+// pool.
+type SecureBuffer struct {
+	mem     secureMem
+	n       int
+	class   int // index into securePools, or -1.
+	cleanup runtime.Cleanup
+}
+
+// GetSecureBuffer returns an empty SecureBuffer that can hold at least n
+// bytes. Return it with PutSecureBuffer.
+func GetSecureBuffer(n int) *SecureBuffer {
+	pages := max((n+pageSize-1)/pageSize, 1)
+	class := bits.Len(uint(pages - 1))
+	if class <= maxSecureShift {
+		if s, ok := securePools[class].Get().(*SecureBuffer); ok {
+			return s
+		}
+		pages = 1 << class
+	} else {
+		class = -1
+	}
+	s := &SecureBuffer{mem: allocSecure(pages * pageSize), class: class}
+	// Release the memory if the pool drops s.
+	s.cleanup = runtime.AddCleanup(s, freeSecure, s.mem)
+	return s
+}
+
+// GetSecureBufferE is like GetSecureBuffer but returns ErrNotLocked instead
+// of a SecureBuffer whose memory isn't locked into RAM, for callers that
+// would rather fail than risk secrets reaching swap.
+func GetSecureBufferE(n int) (*SecureBuffer, error) {
+	s := GetSecureBuffer(n)
+	if !s.Locked() {
+		s.cleanup.Stop()
+		freeSecure(s.mem)
+		return nil, ErrNotLocked
+	}
+	return s, nil
+}
+
+// PutSecureBuffer zeroes s's memory and returns it to the pool. s must not
+// be used afterward.
+func PutSecureBuffer(s *SecureBuffer) {
+	clear(s.mem.p)
+	s.n = 0
+	if s.class < 0 {
+		s.cleanup.Stop()
+		freeSecure(s.mem)
+		s.mem = secureMem{}
+		return
+	}
+	securePools[s.class].Put(s)
+}
+
+// Locked reports whether s's memory is locked into RAM.
+func (s *SecureBuffer) Locked() bool {
+	return s.mem.locked
+}
+
+// Bytes returns s's contents. The slice aliases s's memory, so it's only
+// valid until s is put, and copying it elsewhere defeats the purpose. The
+// memory is released once s is unreachable, so s must be kept alive, for
+// example by putting it afterward or with runtime.KeepAlive, for as long as
+// the slice is used.
+func (s *SecureBuffer) Bytes() []byte {
+	return s.mem.p[:s.n:s.n]
+}
+
+// Len returns the number of bytes written to s.
+func (s *SecureBuffer) Len() int {
+	return s.n
+}
+
+// Cap returns the number of bytes s can hold.
+func (s *SecureBuffer) Cap() int {
+	return len(s.mem.p)
+}
+
+// Reset zeroes s and makes it empty.
+func (s *SecureBuffer) Reset() {
+	clear(s.mem.p[:s.n])
+	s.n = 0
+}
+
+// Write appends p to s. If p doesn't fit, nothing is written and Write
+// returns ErrSecureBufferFull.
+func (s *SecureBuffer) Write(p []byte) (int, error) {
+	if len(p) > len(s.mem.p)-s.n {
+		return 0, ErrSecureBufferFull
+	}
+	s.n += copy(s.mem.p[s.n:], p)
+	return len(p), nil
+}
+
+// WriteString is like Write but writes the contents of str.
+func (s *SecureBuffer) WriteString(str string) (int, error) {
+	if len(str) > len(s.mem.p)-s.n {
+		return 0, ErrSecureBufferFull
+	}
+	s.n += copy(s.mem.p[s.n:], str)
+	return len(str), nil
+}
+
+// WriteByte appends c to s.
+func (s *SecureBuffer) WriteByte(c byte) error {
+	if s.n == len(s.mem.p) {
+		return ErrSecureBufferFull
+	}
+	s.mem.p[s.n] = c
+	s.n++
+	return nil
+}
+
+// allocSecure maps n bytes outside the Go heap and tries to lock them. If
+// the mapping fails it falls back to the heap.
+func allocSecure(n int) secureMem {
+	p, err := mmap(n)
+	if err != nil {
+		return secureMem{p: make([]byte, n)}
+	}
+	// The memory is still kept off the heap if it can't be locked.
+	return secureMem{p: p, mapped: true, locked: mlock(p)}
+}
+
+func freeSecure(m secureMem) {
+	if m.locked {
+		munlock(m.p)
+	}
+	if m.mapped {
+		munmap(m.p)
+	}
+}
@@ -0,0 +1,39 @@
+package pools
+
+import "testing"
+
+func TestSecureBuffer(t *testing.T) {
+	s := GetSecureBuffer(10)
+	if s.Cap() < 10 || s.Cap()%pageSize != 0 {
+		t.Fatalf("unexpected capacity %d", s.Cap())
+	}
+	s.WriteString("hunter")
+	s.WriteByte('2')
+	expect(t, "hunter2", string(s.Bytes()))
+	if _, err := s.Write(make([]byte, s.Cap())); err != ErrSecureBufferFull {
+		t.Fatalf("want ErrSecureBufferFull, got %v", err)
+	}
+	expect(t, 7, s.Len())
+
+	mem := s.mem.p
+	PutSecureBuffer(s)
+	for _, c := range mem {
+		if c != 0 {
+			t.Fatal("PutSecureBuffer left data behind")
+		}
+	}
+
+	big := GetSecureBuffer(pageSize<<maxSecureShift + 1)
+	expect(t, -1, big.class)
+	PutSecureBuffer(big)
+}
+
+func TestGetSecureBufferE(t *testing.T) {
+	s, err := GetSecureBufferE(16)
+	if err != nil {
+		expect(t, ErrNotLocked, err)
+		return
+	}
+	expect(t, true, s.Locked())
+	PutSecureBuffer(s)
+}
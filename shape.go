@@ -0,0 +1,51 @@
+package pools
+
+import "sync"
+
+// shape identifies the output of a single WriteGroups or WriteInterval call.
+type shape struct {
+	offset, n, num int
+	dialect        Dialect
+	interval       bool
+}
+
+const (
+	maxShapes     = 256     // maximum number of cached fragments.
+	maxShapeBytes = 1 << 16 // largest fragment worth caching.
+)
+
+// shapes memoizes placeholder fragments. Batch inserts tend to use a handful
+// of identical shapes, so copying the cached fragment is much cheaper than
+// formatting every integer again.
+var shapes = struct {
+	sync.RWMutex
+	m map[shape]string
+}{m: make(map[shape]string)}
+
+// writeShape writes the cached fragment for k to w, if any, and reports
+// whether it did so.
+func (w *Buffer) writeShape(k shape) bool {
+	shapes.RLock()
+	s, ok := shapes.m[k]
+	shapes.RUnlock()
+	if ok {
+		w.WriteString(s)
+	}
+	return ok
+}
+
+// storeShape caches a copy of p as the fragment for k. If the cache is full
+// it's emptied first so that stale shapes don't crowd out the current
+// workload.
+func storeShape(k shape, p []byte) {
+	if len(p) > maxShapeBytes {
+		return
+	}
+	s := string(p)
+	shapes.Lock()
+	if len(shapes.m) >= maxShapes {
+		shapes.m = make(map[shape]string)
+	}
+	shapes.m[k] = s
+	shapes.Unlock()
+}
@@ -0,0 +1,103 @@
+package pools
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// shardProbes is how many shards Get and Put try before giving up.
+const shardProbes = 4
+
+// NewShardedPool returns a Pool that retains up to n idle Buffers spread
+// across a shard per P (GOMAXPROCS, rounded up to a power of two), or n
+// shards if that's fewer. Each Get and Put starts at a random shard and
+// moves on from shards that are locked by another goroutine, so at very high
+// rates on many cores goroutines rarely contend with each other the way they
+// can on a sync.Pool's shared victim and overflow queues. Get only tries
+// min(4, shards) shards, so it may allocate a Buffer while others sit idle
+// in the shards it didn't try, and Put may likewise drop one. Like
+// NewPinnedPool its contents survive GC. NewShardedPool panics if n <= 0.
+func NewShardedPool(n int, opts ...Option) *Pool {
+	if n <= 0 {
+		panic("pools: non-positive size for NewShardedPool")
+	}
+	// Split n exactly, so no more than n Buffers are retained in all.
+	count := min(1<<bits.Len(uint(runtime.GOMAXPROCS(0)-1)), n)
+	return newPool(opts, func(func() *Buffer) freeList {
+		s := &sharded{shards: make([]shard, count)}
+		for i := range s.shards {
+			per := n / count
+			if i < n%count {
+				per++
+			}
+			s.shards[i].free = make([]*Buffer, 0, per)
+		}
+		return s
+	})
+}
+
+type sharded struct {
+	shards []shard
+}
+
+type shard struct {
+	mu   sync.Mutex
+	free []*Buffer
+	_    [32]byte // keep shards on separate cache lines.
+}
+
+// probe calls f on up to shardProbes shards, starting at a random one, until
+// it returns true. Shards locked by other goroutines are skipped unless all
+// of them are, in which case f waits for the first.
+func (s *sharded) probe(f func(*shard) bool) bool {
+	// rand.Uint32 reads per-thread state, so it doesn't contend either.
+	start := rand.Uint32()
+	probes := min(shardProbes, len(s.shards))
+	var locked bool
+	for i := range probes {
+		sh := &s.shards[(start+uint32(i))%uint32(len(s.shards))]
+		if !sh.mu.TryLock() {
+			continue
+		}
+		locked = true
+		ok := f(sh)
+		sh.mu.Unlock()
+		if ok {
+			return true
+		}
+	}
+	if locked {
+		return false
+	}
+	sh := &s.shards[start%uint32(len(s.shards))]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return f(sh)
+}
+
+func (s *sharded) get() *Buffer {
+	var b *Buffer
+	s.probe(func(sh *shard) bool {
+		n := len(sh.free)
+		if n == 0 {
+			return false
+		}
+		b = sh.free[n-1]
+		sh.free[n-1] = nil
+		sh.free = sh.free[:n-1]
+		return true
+	})
+	return b
+}
+
+func (s *sharded) put(b *Buffer) bool {
+	return s.probe(func(sh *shard) bool {
+		if len(sh.free) == cap(sh.free) {
+			return false
+		}
+		sh.free = append(sh.free, b)
+		return true
+	})
+}
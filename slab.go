@@ -0,0 +1,200 @@
+package pools
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+// The slice pool's classes up to 1<<maxSlabShift bytes are backed by slabs:
+// slabSize blocks carved into equal-sized objects. Objects are recycled
+// through a free list per slab, so the memory they retain is known exactly
+// and is never scattered across the heap by the GC dropping pool entries.
+// A slab none of whose objects are handed out is released, unless it's the
+// class's only such slab, so a spike in traffic doesn't pin memory for good.
+const (
+	maxSlabShift   = 12
+	slabSize       = 64 << 10
+	numSlabClasses = maxSlabShift - minBytesShift + 1
+
+	// maxSlabs bounds the slabs of each class. Past it, objects are
+	// allocated on the heap and dropped when they're put.
+	maxSlabs = 64
+)
+
+var slabs [numSlabClasses]slabClass
+
+type slabClass struct {
+	mu      sync.Mutex
+	all     []*slab // ordered by address.
+	partial []*slab // slabs with idle objects; the last is used first.
+	empty   int     // slabs with no objects handed out.
+	free    int     // idle objects.
+	inUse   int     // objects handed out.
+}
+
+type slab struct {
+	mem     []byte
+	size    int      // bytes per object.
+	free    [][]byte // idle objects.
+	partial int      // index in slabClass.partial, or -1.
+}
+
+// unused reports whether none of sl's objects are handed out.
+func (sl *slab) unused() bool {
+	return len(sl.free)*sl.size == len(sl.mem)
+}
+
+func (sl *slab) addr() uintptr {
+	return uintptr(unsafe.Pointer(unsafe.SliceData(sl.mem)))
+}
+
+// get returns an object of class c's size.
+func (s *slabClass) get(c int) []byte {
+	var p [1][]byte
+	s.getN(c, p[:])
+	return p[0]
+}
+
+// getN fills ps with objects of class c's size.
+func (s *slabClass) getN(c int, ps [][]byte) {
+	size := 1 << (c + minBytesShift)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range ps {
+		if len(s.partial) == 0 && !s.carve(size) {
+			ps[i] = make([]byte, size)
+			continue
+		}
+		sl := s.partial[len(s.partial)-1]
+		if sl.unused() {
+			s.empty--
+		}
+		ps[i] = sl.free[len(sl.free)-1]
+		sl.free[len(sl.free)-1] = nil
+		sl.free = sl.free[:len(sl.free)-1]
+		if len(sl.free) == 0 {
+			s.removePartial(sl)
+		}
+		s.free--
+		s.inUse++
+	}
+}
+
+// carve adds a slab of objects of the given size, reporting false if the
+// class already has maxSlabs.
+func (s *slabClass) carve(size int) bool {
+	if len(s.all) >= maxSlabs {
+		return false
+	}
+	sl := &slab{mem: make([]byte, slabSize), size: size}
+	for off := 0; off < slabSize; off += size {
+		sl.free = append(sl.free, sl.mem[off:off+size:off+size])
+	}
+	i, _ := slices.BinarySearchFunc(s.all, sl.addr(), func(x *slab, a uintptr) int {
+		return cmp.Compare(x.addr(), a)
+	})
+	s.all = slices.Insert(s.all, i, sl)
+	sl.partial = len(s.partial)
+	s.partial = append(s.partial, sl)
+	s.empty++
+	s.free += len(sl.free)
+	return true
+}
+
+// put returns p, whose capacity is class c's size, to the free list of its
+// slab. Objects that didn't come from a slab are dropped.
+func (s *slabClass) put(p []byte) {
+	a := uintptr(unsafe.Pointer(unsafe.SliceData(p)))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, found := slices.BinarySearchFunc(s.all, a, func(x *slab, a uintptr) int {
+		return cmp.Compare(x.addr(), a)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 || a-s.all[i].addr() >= slabSize {
+		return
+	}
+	sl := s.all[i]
+	sl.free = append(sl.free, p[:0])
+	s.free++
+	s.inUse--
+	if sl.partial < 0 {
+		sl.partial = len(s.partial)
+		s.partial = append(s.partial, sl)
+	}
+	if sl.unused() {
+		s.empty++
+		if s.empty > 1 {
+			s.release(i)
+		}
+	}
+}
+
+// release drops the slab s.all[i], which must have no objects handed out.
+func (s *slabClass) release(i int) {
+	sl := s.all[i]
+	s.removePartial(sl)
+	s.all = slices.Delete(s.all, i, i+1)
+	s.empty--
+	s.free -= len(sl.free)
+}
+
+func (s *slabClass) removePartial(sl *slab) {
+	last := s.partial[len(s.partial)-1]
+	s.partial[sl.partial] = last
+	last.partial = sl.partial
+	s.partial[len(s.partial)-1] = nil
+	s.partial = s.partial[:len(s.partial)-1]
+	sl.partial = -1
+}
+
+// drain releases every slab with no objects handed out. Slabs that still
+// have some are kept, since those objects pin their memory anyway.
+func (s *slabClass) drain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.all) - 1; i >= 0; i-- {
+		if s.all[i].unused() {
+			s.release(i)
+		}
+	}
+}
+
+// SlabStats describes the slabs backing the slice pool's small classes.
+type SlabStats struct {
+	// SlabBytes is the memory carved into slabs and Retained the bytes of
+	// idle objects waiting on the free lists.
+	SlabBytes, Retained int
+	Classes             [numSlabClasses]SlabClassStats
+}
+
+// SlabClassStats describes the slabs of one object size.
+type SlabClassStats struct {
+	Size  int // bytes per object.
+	Slabs int // slabs carved into objects of this size and not yet released.
+	Free  int // objects on the free list.
+	InUse int // objects handed out by GetBytes and not yet put.
+}
+
+// ReadSlabStats populates s with the state of the slabs.
+func ReadSlabStats(s *SlabStats) {
+	*s = SlabStats{}
+	for c := range slabs {
+		sc := &slabs[c]
+		sc.mu.Lock()
+		cs := SlabClassStats{
+			Size:  1 << (c + minBytesShift),
+			Slabs: len(sc.all),
+			Free:  sc.free,
+			InUse: sc.inUse,
+		}
+		sc.mu.Unlock()
+		s.Classes[c] = cs
+		s.SlabBytes += cs.Slabs * slabSize
+		s.Retained += cs.Free * cs.Size
+	}
+}
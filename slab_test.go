@@ -0,0 +1,64 @@
+package pools
+
+import "testing"
+
+func TestSlabs(t *testing.T) {
+	Drain()
+	var s SlabStats
+	ReadSlabStats(&s)
+	base := s.Classes[bytesClass(100)].InUse
+
+	p := GetBytes(100)
+	expect(t, 128, cap(p))
+	ReadSlabStats(&s)
+	cs := s.Classes[bytesClass(100)]
+	expect(t, 128, cs.Size)
+	expect(t, 1, cs.Slabs)
+	expect(t, base+1, cs.InUse)
+	expect(t, slabSize/128-1, cs.Free)
+	expect(t, slabSize, s.SlabBytes)
+
+	PutBytes(p)
+	ReadSlabStats(&s)
+	expect(t, slabSize, s.Retained)
+	expect(t, base, s.Classes[bytesClass(100)].InUse)
+
+	// The object just put is handed out again.
+	q := GetBytes(128)
+	expect(t, &p[:1][0], &q[:1][0])
+	PutBytes(q)
+}
+
+func TestSlabsRelease(t *testing.T) {
+	Drain()
+	const size = 4096
+	c := bytesClass(size)
+	var s SlabStats
+	ReadSlabStats(&s)
+	base := s.Classes[c]
+
+	// A spike past maxSlabs is served from the heap.
+	ps := make([][]byte, (maxSlabs+1)*slabSize/size)
+	for i := range ps {
+		ps[i] = GetBytes(size)
+	}
+	ReadSlabStats(&s)
+	expect(t, maxSlabs, s.Classes[c].Slabs)
+	expect(t, base.InUse+maxSlabs*slabSize/size, s.Classes[c].InUse)
+
+	// Draining keeps slabs whose objects are still out.
+	Drain()
+	ReadSlabStats(&s)
+	expect(t, maxSlabs, s.Classes[c].Slabs)
+
+	// Once the spike is over, only one unused slab is kept.
+	for _, p := range ps {
+		PutBytes(p)
+	}
+	ReadSlabStats(&s)
+	expect(t, base.InUse, s.Classes[c].InUse)
+	expect(t, max(base.Slabs, 1), s.Classes[c].Slabs)
+	Drain()
+	ReadSlabStats(&s)
+	expect(t, base.Slabs, s.Classes[c].Slabs)
+}
@@ -0,0 +1,501 @@
+package pools
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SlogOptions are the options for SlogHandler.
+type SlogOptions struct {
+	slog.HandlerOptions
+	// JSON selects slog.JSONHandler's format instead of slog.TextHandler's.
+	JSON bool
+	// Buffers, if not nil, supplies the Buffers records are formatted
+	// into instead of the package's pool.
+	Buffers BufferPool
+}
+
+// SlogHandler returns a slog.Handler that writes records to w in the same
+// format as slog.TextHandler or, if opts.JSON is set, slog.JSONHandler. Each
+// record is formatted into a pooled Buffer and written to w with a single
+// call to Write. A nil opts is the same as the zero SlogOptions.
+func SlogHandler(w io.Writer, opts *SlogOptions) slog.Handler {
+	h := &slogHandler{w: w, mu: new(sync.Mutex)}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Buffers == nil {
+		h.opts.Buffers = Buffers
+	}
+	return h
+}
+
+type slogHandler struct {
+	w    io.Writer
+	mu   *sync.Mutex // shared by handlers derived with WithAttrs or WithGroup.
+	opts SlogOptions
+
+	pre    []byte   // formatted attrs from WithAttrs, each with a leading separator.
+	groups []string // names from WithGroup.
+	opened int      // number of groups already opened in pre.
+}
+
+func (h *slogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	min := slog.LevelInfo
+	if h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return l >= min
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	return &h2
+}
+
+func (h *slogHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	b := h.opts.Buffers.Get()
+	defer h.opts.Buffers.Put(b)
+	// The placeholder byte makes the first attr written get a separator.
+	b.WriteByte('.')
+	b.Write(h.pre)
+	s := h.newState(b)
+	if !s.appendAttrs(h.groups[h.opened:], as) {
+		return h
+	}
+	h2 := *h
+	h2.pre = slices.Clone(b.Bytes()[1:])
+	h2.opened = len(h.groups)
+	return &h2
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	b := h.opts.Buffers.Get()
+	defer h.opts.Buffers.Put(b)
+	s := slogState{h: h, b: b}
+	if h.opts.JSON {
+		b.WriteByte('{')
+	}
+	if !r.Time.IsZero() {
+		s.appendAttr(slog.Time(slog.TimeKey, r.Time.Round(0)))
+	}
+	s.appendAttr(slog.Any(slog.LevelKey, r.Level))
+	if h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		s.appendAttr(slog.Any(slog.SourceKey, &slog.Source{
+			Function: f.Function,
+			File:     f.File,
+			Line:     f.Line,
+		}))
+	}
+	s.appendAttr(slog.String(slog.MessageKey, r.Message))
+
+	if len(h.pre) > 0 {
+		if s.atStart() {
+			b.Write(h.pre[1:])
+		} else {
+			b.Write(h.pre)
+		}
+	}
+	s.groups = h.groups[:h.opened:h.opened]
+	open := h.opened
+	if r.NumAttrs() > 0 {
+		m, n := s.openGroups(h.groups[h.opened:])
+		var wrote bool
+		r.Attrs(func(a slog.Attr) bool {
+			wrote = s.appendAttr(a) || wrote
+			return true
+		})
+		if wrote {
+			open = len(h.groups)
+		} else {
+			s.undoGroups(m, n)
+		}
+	}
+	if h.opts.JSON {
+		for range open {
+			b.WriteByte('}')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// slogState formats attrs into a Buffer.
+type slogState struct {
+	h      *slogHandler
+	b      *Buffer
+	groups []string // the groups the next attr is in.
+}
+
+func (h *slogHandler) newState(b *Buffer) slogState {
+	return slogState{h: h, b: b, groups: h.groups[:h.opened:h.opened]}
+}
+
+// atStart reports whether nothing has been written to the current record or
+// group yet.
+func (s *slogState) atStart() bool {
+	if s.h.opts.JSON {
+		p := s.b.Bytes()
+		return p[len(p)-1] == '{'
+	}
+	return s.b.Len() == 0
+}
+
+func (s *slogState) sep() {
+	if s.atStart() {
+		return
+	}
+	if s.h.opts.JSON {
+		s.b.WriteByte(',')
+	} else {
+		s.b.WriteByte(' ')
+	}
+}
+
+// appendAttrs opens groups and writes as inside them. If none of as are
+// written, neither are the groups.
+func (s *slogState) appendAttrs(groups []string, as []slog.Attr) bool {
+	m, n := s.openGroups(groups)
+	var wrote bool
+	for _, a := range as {
+		wrote = s.appendAttr(a) || wrote
+	}
+	if !wrote {
+		s.undoGroups(m, n)
+	}
+	return wrote
+}
+
+// openGroups opens groups, returning what undoGroups needs to take them
+// back.
+func (s *slogState) openGroups(groups []string) (Mark, int) {
+	m, n := s.b.Mark(), len(s.groups)
+	for _, g := range groups {
+		s.openGroup(g)
+	}
+	return m, n
+}
+
+func (s *slogState) undoGroups(m Mark, n int) {
+	s.b.Rollback(m)
+	s.groups = s.groups[:n]
+}
+
+func (s *slogState) openGroup(name string) {
+	if s.h.opts.JSON {
+		s.sep()
+		appendJSONString(s.b, name)
+		s.b.WriteString(":{")
+	}
+	s.groups = append(s.groups, name)
+}
+
+// appendAttr writes a, reporting whether it wrote anything.
+func (s *slogState) appendAttr(a slog.Attr) bool {
+	a.Value = a.Value.Resolve()
+	if rep := s.h.opts.ReplaceAttr; rep != nil && a.Value.Kind() != slog.KindGroup {
+		// Copy the groups so rep can't alias, and the handler's state
+		// doesn't escape.
+		a = rep(slices.Clone(s.groups), a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return false
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		as := a.Value.Group()
+		if a.Key == "" {
+			var wrote bool
+			for _, ga := range as {
+				wrote = s.appendAttr(ga) || wrote
+			}
+			return wrote
+		}
+		n := len(s.groups)
+		wrote := s.appendAttrs([]string{a.Key}, as)
+		if wrote && s.h.opts.JSON {
+			s.b.WriteByte('}')
+		}
+		s.groups = s.groups[:n]
+		return wrote
+	}
+	s.sep()
+	if s.h.opts.JSON {
+		appendJSONString(s.b, a.Key)
+		s.b.WriteByte(':')
+		s.appendJSONValue(a.Value)
+	} else {
+		s.appendTextKey(a.Key)
+		s.b.WriteByte('=')
+		s.appendTextValue(a.Value)
+	}
+	return true
+}
+
+func (s *slogState) appendTextKey(key string) {
+	quote := needsQuoting(key)
+	for _, g := range s.groups {
+		quote = quote || needsQuoting(g)
+	}
+	if !quote {
+		for _, g := range s.groups {
+			s.b.WriteString(g)
+			s.b.WriteByte('.')
+		}
+		s.b.WriteString(key)
+		return
+	}
+	var full string
+	for _, g := range s.groups {
+		full += g + "."
+	}
+	s.b.Write(strconv.AppendQuote(s.b.AvailableBuffer(), full+key))
+}
+
+func (s *slogState) appendTextValue(v slog.Value) {
+	switch v.Kind() {
+	case slog.KindString:
+		appendTextString(s.b, v.String())
+	case slog.KindTime:
+		s.b.Write(appendRFC3339Millis(s.b.AvailableBuffer(), v.Time()))
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case slog.Level:
+			s.b.WriteString(x.String())
+		case *slog.Source:
+			appendTextString(s.b, x.File+":"+strconv.Itoa(x.Line))
+		case encoding.TextMarshaler:
+			p, err := x.MarshalText()
+			if err != nil {
+				appendTextString(s.b, "!ERROR:"+err.Error())
+				return
+			}
+			appendTextString(s.b, string(p))
+		case []byte:
+			s.b.Write(strconv.AppendQuote(s.b.AvailableBuffer(), string(x)))
+		default:
+			appendTextString(s.b, fmt.Sprintf("%+v", x))
+		}
+	default:
+		s.appendScalar(v)
+	}
+}
+
+// appendScalar writes the numeric, boolean and duration kinds, which are
+// formatted the same way by both handlers except for durations.
+func (s *slogState) appendScalar(v slog.Value) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		s.b.WriteInt64(v.Int64())
+	case slog.KindUint64:
+		s.b.Write(strconv.AppendUint(s.b.AvailableBuffer(), v.Uint64(), 10))
+	case slog.KindFloat64:
+		s.b.Write(strconv.AppendFloat(s.b.AvailableBuffer(), v.Float64(), 'g', -1, 64))
+	case slog.KindBool:
+		s.b.Write(strconv.AppendBool(s.b.AvailableBuffer(), v.Bool()))
+	case slog.KindDuration:
+		if s.h.opts.JSON {
+			s.b.WriteInt64(int64(v.Duration()))
+		} else {
+			s.b.WriteString(v.Duration().String())
+		}
+	}
+}
+
+func (s *slogState) appendJSONValue(v slog.Value) {
+	switch v.Kind() {
+	case slog.KindString:
+		appendJSONString(s.b, v.String())
+	case slog.KindFloat64:
+		f := v.Float64()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			appendJSONString(s.b, "!ERROR:json: unsupported value: "+strconv.FormatFloat(f, 'g', -1, 64))
+			return
+		}
+		s.b.Write(appendJSONFloat(s.b.AvailableBuffer(), f))
+	case slog.KindTime:
+		s.b.WriteByte('"')
+		s.b.Write(v.Time().AppendFormat(s.b.AvailableBuffer(), time.RFC3339Nano))
+		s.b.WriteByte('"')
+	case slog.KindAny:
+		x := v.Any()
+		if l, ok := x.(slog.Level); ok {
+			appendJSONString(s.b, l.String())
+			return
+		}
+		if src, ok := x.(*slog.Source); ok {
+			// Like slog, leave out empty fields.
+			s.b.WriteByte('{')
+			if src.Function != "" {
+				s.b.WriteString(`"function":`)
+				appendJSONString(s.b, src.Function)
+			}
+			if src.File != "" {
+				s.sep()
+				s.b.WriteString(`"file":`)
+				appendJSONString(s.b, src.File)
+			}
+			if src.Line != 0 {
+				s.sep()
+				s.b.WriteString(`"line":`)
+				s.b.WriteInt(src.Line)
+			}
+			s.b.WriteByte('}')
+			return
+		}
+		if err, ok := x.(error); ok {
+			if _, ok := x.(json.Marshaler); !ok {
+				appendJSONString(s.b, err.Error())
+				return
+			}
+		}
+		m := s.b.Mark()
+		enc := json.NewEncoder(s.b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(x); err != nil {
+			s.b.Rollback(m)
+			appendJSONString(s.b, "!ERROR:"+err.Error())
+			return
+		}
+		// Drop the newline Encode adds.
+		s.b.Truncate(s.b.Len() - 1)
+	default:
+		s.appendScalar(v)
+	}
+}
+
+// appendJSONFloat formats f the way encoding/json does.
+func appendJSONFloat(p []byte, f float64) []byte {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	p = strconv.AppendFloat(p, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(p); n >= 4 && p[n-4] == 'e' && p[n-3] == '-' && p[n-2] == '0' {
+			p[n-2] = p[n-1]
+			p = p[:n-1]
+		}
+	}
+	return p
+}
+
+// appendJSONString writes s as a JSON string, escaping it the way
+// slog.JSONHandler does.
+func appendJSONString(b *Buffer, s string) {
+	const hex = "0123456789abcdef"
+	b.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= ' ' && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case '\n':
+				b.WriteString(`\n`)
+			case '\r':
+				b.WriteString(`\r`)
+			case '\t':
+				b.WriteString(`\t`)
+			default:
+				b.WriteString(`\u00`)
+				b.WriteByte(hex[c>>4])
+				b.WriteByte(hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteString(s[start:i])
+			b.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b.WriteString(s[start:i])
+			b.WriteString(`\u202`)
+			b.WriteByte(hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b.WriteString(s[start:])
+	b.WriteByte('"')
+}
+
+func appendTextString(b *Buffer, s string) {
+	if needsQuoting(s) {
+		b.Write(strconv.AppendQuote(b.AvailableBuffer(), s))
+		return
+	}
+	b.WriteString(s)
+}
+
+// needsQuoting reports whether slog.TextHandler would quote s.
+func needsQuoting(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c == ' ' || c == '=' || c == '"' || c < ' ' || c == utf8.RuneSelf-1 {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// appendRFC3339Millis formats t like time.RFC3339 with milliseconds, as
+// slog.TextHandler does.
+func appendRFC3339Millis(p []byte, t time.Time) []byte {
+	// RFC3339Nano trims trailing zeros, so add a tenth of a millisecond to
+	// always get four digits after the period, then drop the fourth.
+	const prefixLen = len("2006-01-02T15:04:05.000")
+	n := len(p)
+	p = t.Truncate(time.Millisecond).Add(time.Millisecond/10).AppendFormat(p, time.RFC3339Nano)
+	return append(p[:n+prefixLen], p[n+prefixLen+1:]...)
+}
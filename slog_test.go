@@ -0,0 +1,83 @@
+package pools
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	opts := slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "secret" {
+				return slog.String("secret", "xxx")
+			}
+			if a.Key == "drop" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}
+	log := func(l *slog.Logger) {
+		l.Info("hello, world", "n", 1, "u", uint(2), "f", 1.5, "tiny", 1e-9, "b", true)
+		l.Debug("a=b", "d", time.Second, "t", time.Date(2024, 1, 2, 3, 4, 5, 6e6, time.UTC))
+		l.Warn("quote\"d\n", "err", errors.New("boom"), "bytes", []byte("x y"), "any", []int{1, 2})
+		l.With("secret", "hunter2", "drop", 1).WithGroup("g").Error("grouped",
+			slog.Group("inner", "k", "v <&>"),
+			slog.Group("empty"),
+			slog.Group("", "inline", 1),
+			"key with space", "\u2028")
+		l.WithGroup("unused").Info("no attrs")
+		l.WithGroup("g").With("a", 1).WithGroup("h").Info("nested", "b", 2)
+		l.With(slog.Group("s", "x", 1)).Info("pre-group")
+	}
+
+	for _, json := range []bool{false, true} {
+		var want, got bytes.Buffer
+		if json {
+			log(slog.New(slog.NewJSONHandler(&want, &opts)))
+		} else {
+			log(slog.New(slog.NewTextHandler(&want, &opts)))
+		}
+		log(slog.New(SlogHandler(&got, &SlogOptions{HandlerOptions: opts, JSON: json})))
+
+		// Timestamps differ, so drop them.
+		strip := func(s string) string {
+			var lines []string
+			for _, line := range strings.Split(s, "\n") {
+				if i := strings.Index(line, "level"); i > 0 {
+					line = line[i:]
+				}
+				lines = append(lines, line)
+			}
+			return strings.Join(lines, "\n")
+		}
+		if w, g := strip(want.String()), strip(got.String()); w != g {
+			t.Fatalf("json=%t:\nwant:\n%s\ngot:\n%s", json, w, g)
+		}
+	}
+}
+
+func TestSlogHandlerTime(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var b Buffer
+	b.Write(appendRFC3339Millis(nil, tm))
+	expect(t, "2024-01-02T03:04:05.000Z", b.String())
+}
+
+func TestSlogHandlerAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool allocates under the race detector")
+	}
+	l := slog.New(SlogHandler(io.Discard, &SlogOptions{JSON: true}))
+	if n := testing.AllocsPerRun(100, func() {
+		l.Info("hello", "n", 1, "s", "x")
+	}); n != 0 {
+		t.Fatalf("want no allocations, got %v", n)
+	}
+}
@@ -0,0 +1,32 @@
+package pools
+
+import "strconv"
+
+// numSmallInts is the number of integers, starting at 0, with precomputed
+// decimal forms.
+const numSmallInts = 10000
+
+// smallInts holds the decimal forms of [0, numSmallInts) back to back;
+// smallIntEnds[i] is the end of i's digits. Placeholder indices and
+// counters are nearly always small, so this turns formatting them into a
+// copy.
+var smallInts, smallIntEnds = buildSmallInts()
+
+func buildSmallInts() (string, *[numSmallInts]uint16) {
+	var ends [numSmallInts]uint16
+	p := make([]byte, 0, 38890) // total width of [0, 9999]
+	for i := range ends {
+		p = strconv.AppendInt(p, int64(i), 10)
+		ends[i] = uint16(len(p))
+	}
+	return string(p), &ends
+}
+
+// smallInt returns the decimal form of i, which must be in [0, numSmallInts).
+func smallInt(i int) string {
+	var start uint16
+	if i > 0 {
+		start = smallIntEnds[i-1]
+	}
+	return smallInts[start:smallIntEnds[i]]
+}
@@ -0,0 +1,54 @@
+package pools
+
+// StatsSnapshot is a copy of the package's Buffer and Builder statistics
+// taken by Snapshot. Since it's a value it doesn't change as the pools are
+// used, so two snapshots can be compared with Diff.
+type StatsSnapshot struct {
+	Buffers  Stats
+	Builders BuilderStats
+}
+
+// Snapshot returns the current statistics reported by ReadStats and
+// ReadBuilderStats. For example, to check how a code path uses the pool:
+//
+//	before := pools.Snapshot()
+//	f()
+//	d := pools.Snapshot().Diff(before)
+//	if d.Gets != 1 || d.News != 0 {
+//		...
+//	}
+func Snapshot() StatsSnapshot {
+	var s StatsSnapshot
+	ReadStats(&s.Buffers)
+	ReadBuilderStats(&s.Builders)
+	return s
+}
+
+// StatsDelta is the change in the pools' counters between two snapshots.
+// Counts are signed since ResetStats may be called in between.
+type StatsDelta struct {
+	Gets, Puts, News, Discards int64 // of Buffers.
+	Outstanding                int   // change in Buffers checked out.
+
+	BuilderGets, BuilderPuts, BuilderNews, BuilderDiscards int64
+	BuilderOutstanding                                     int
+}
+
+// Diff returns how the counters changed from older to s.
+func (s StatsSnapshot) Diff(older StatsSnapshot) StatsDelta {
+	b, ob := &s.Buffers, &older.Buffers
+	f, of := &s.Builders, &older.Builders
+	return StatsDelta{
+		Gets:        int64(b.Gets - ob.Gets),
+		Puts:        int64(b.Puts - ob.Puts),
+		News:        int64(b.News - ob.News),
+		Discards:    int64(b.Discards - ob.Discards),
+		Outstanding: b.Outstanding - ob.Outstanding,
+
+		BuilderGets:        int64(f.Gets - of.Gets),
+		BuilderPuts:        int64(f.Puts - of.Puts),
+		BuilderNews:        int64(f.News - of.News),
+		BuilderDiscards:    int64(f.Discards - of.Discards),
+		BuilderOutstanding: f.Outstanding - of.Outstanding,
+	}
+}
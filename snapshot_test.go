@@ -0,0 +1,27 @@
+package pools
+
+import "testing"
+
+func TestSnapshot(t *testing.T) {
+	before := Snapshot()
+	b1, b2 := GetBuffer(), GetBuffer()
+	PutBuffer(b1)
+	fb := GetBuilder()
+	mid := Snapshot()
+	PutBuffer(b2)
+	PutBuilder(fb)
+
+	d := mid.Diff(before)
+	expect(t, int64(2), d.Gets)
+	expect(t, int64(1), d.Puts)
+	expect(t, 1, d.Outstanding)
+	expect(t, int64(1), d.BuilderGets)
+	expect(t, int64(0), d.BuilderPuts)
+	expect(t, 1, d.BuilderOutstanding)
+
+	// mid is unaffected by the Puts that followed it.
+	d = Snapshot().Diff(mid)
+	expect(t, int64(1), d.Puts)
+	expect(t, -1, d.Outstanding)
+	expect(t, int64(1), d.BuilderPuts)
+}
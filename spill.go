@@ -0,0 +1,108 @@
+package pools
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+var spillPool = sync.Pool{
+	New: func() interface{} {
+		return new(SpillBuffer)
+	},
+}
+
+// SpillBuffer holds what's written to it in a pooled Buffer until it grows
+// past a limit, then moves it to a temporary file and writes there instead.
+// It suits outputs, like archives, that are usually small but occasionally
+// too big to keep in memory.
+type SpillBuffer struct {
+	limit int
+	dir   string
+	buf   *Buffer  // nil once spilled.
+	f     *os.File // nil until spilled.
+	n     int64
+}
+
+// GetSpillBuffer returns a pooled SpillBuffer that spills to a temporary
+// file in dir, or os.TempDir if dir is empty, once more than limit bytes
+// have been written. Return it with PutSpillBuffer.
+func GetSpillBuffer(limit int, dir string) *SpillBuffer {
+	s := spillPool.Get().(*SpillBuffer)
+	s.limit, s.dir = limit, dir
+	s.buf = GetBuffer()
+	return s
+}
+
+// PutSpillBuffer returns s's Buffer to the pool, or closes and removes its
+// temporary file, and returns s to the pool. It returns the error from
+// removing the file, if any.
+func PutSpillBuffer(s *SpillBuffer) error {
+	var err error
+	if s.f != nil {
+		s.f.Close()
+		err = os.Remove(s.f.Name())
+		s.f = nil
+	}
+	if s.buf != nil {
+		PutBuffer(s.buf)
+		s.buf = nil
+	}
+	s.n = 0
+	s.dir = ""
+	spillPool.Put(s)
+	return err
+}
+
+func (s *SpillBuffer) Write(p []byte) (int, error) {
+	if s.f == nil && s.buf.Len()+len(p) > s.limit {
+		if err := s.spill(); err != nil {
+			return 0, err
+		}
+	}
+	var n int
+	var err error
+	if s.f != nil {
+		n, err = s.f.Write(p)
+	} else {
+		n, err = s.buf.Write(p)
+	}
+	s.n += int64(n)
+	return n, err
+}
+
+// spill moves the Buffer's contents to a new temporary file.
+func (s *SpillBuffer) spill() error {
+	f, err := os.CreateTemp(s.dir, "pools-spill-")
+	if err != nil {
+		return err
+	}
+	if _, err := s.buf.WriteTo(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	PutBuffer(s.buf)
+	s.buf, s.f = nil, f
+	return nil
+}
+
+// Len returns the number of bytes written to s.
+func (s *SpillBuffer) Len() int64 {
+	return s.n
+}
+
+// Spilled reports whether s has moved to a temporary file.
+func (s *SpillBuffer) Spilled() bool {
+	return s.f != nil
+}
+
+// WriteTo writes everything written to s so far to w. Unlike Buffer.WriteTo
+// it doesn't consume anything, so it may be called more than once.
+func (s *SpillBuffer) WriteTo(w io.Writer) (int64, error) {
+	if s.f == nil {
+		return bytes.NewReader(s.buf.Bytes()).WriteTo(w)
+	}
+	return io.Copy(w, io.NewSectionReader(s.f, 0, s.n))
+}
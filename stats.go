@@ -0,0 +1,186 @@
+package pools
+
+import "sync/atomic"
+
+// Stats describes the activity of the Buffer pool.
+type Stats struct {
+	Gets     uint64 // Buffers requested from the pool.
+	Puts     uint64 // Buffers returned to the pool.
+	News     uint64 // Buffers allocated because the pool was empty.
+	Discards uint64 // Buffers dropped instead of being retained.
+
+	// Outstanding is the number of Buffers currently checked out of the
+	// package's pool. Pools don't report it.
+	Outstanding int
+
+	// RateLimited counts a Pool's Gets over its rate limit. See
+	// WithRateLimit.
+	RateLimited uint64
+
+	// SizeHistogram counts the sizes of a recent sample of Buffers returned
+	// to the pool. SizeHistogram[i] counts sizes in [1<<(i-1), 1<<i); the
+	// first bucket counts empty Buffers and the last everything larger.
+	SizeHistogram [numSizeClasses]uint64
+
+	// RetainCap is the capacity above which Buffers are dropped instead of
+	// returned to the pool, learned from recent sizes. It's 0 until enough
+	// Buffers have been returned to learn from.
+	RetainCap int
+
+	// GrowHint is the capacity new Buffers are allocated with, learned from
+	// recent sizes.
+	GrowHint int
+
+	// GCScale is the fraction, in [0, 1], that RetainCap and GrowHint have
+	// been scaled by because of GC pressure. See StartGCMonitor.
+	GCScale float64
+
+	// MemoryPressure counts the times StartGCMonitor found memory near its
+	// limit and released the pools' idle objects.
+	MemoryPressure uint64
+
+	// PeakOutstanding is the most Buffers checked out at once and PeakSize
+	// the most bytes written to a single Buffer returned to the pool, since
+	// the last call to ResetStats or Drain. MaxOutstanding and MaxSize are
+	// the same but since the program started.
+	PeakOutstanding, MaxOutstanding int
+	PeakSize, MaxSize               int
+}
+
+var bufferStats struct {
+	gets, puts, news, discards atomic.Uint64
+
+	// outstanding is the number of Buffers checked out of the pool and peak
+	// its maximum since the janitor last looked. Drain doesn't reset them.
+	outstanding, peak atomic.Int64
+
+	// High-water marks since the last reset and since startup.
+	peakOutstanding, maxOutstanding atomic.Int64
+	peakSize, maxSize               atomic.Int64
+}
+
+// BuilderStats describes the activity of the Builder pool.
+type BuilderStats struct {
+	Gets        uint64 // Builders requested from the pool.
+	Puts        uint64 // Builders returned to the pool.
+	News        uint64 // Builders allocated because the pool was empty.
+	Discards    uint64 // Builders dropped instead of being retained.
+	Outstanding int    // Builders currently checked out.
+
+	// RetainedBytes estimates the bytes held by idle Builders in the pool.
+	// Since the GC may empty the pool without telling it, this is an upper
+	// bound.
+	RetainedBytes int
+
+	// SizeHistogram counts the number of bytes written to the Builders
+	// returned to the pool, bucketed like Stats.SizeHistogram.
+	SizeHistogram [numSizeClasses]uint64
+}
+
+var builderStats struct {
+	gets, puts, news, discards atomic.Uint64
+	outstanding, retained      atomic.Int64
+	hist                       [numSizeClasses]atomic.Uint64
+}
+
+// ReadBuilderStats populates s with statistics about the Builder pool.
+func ReadBuilderStats(s *BuilderStats) {
+	s.Gets = builderStats.gets.Load()
+	s.Puts = builderStats.puts.Load()
+	s.News = builderStats.news.Load()
+	s.Discards = builderStats.discards.Load()
+	s.Outstanding = int(max(builderStats.outstanding.Load(), 0))
+	s.RetainedBytes = int(max(builderStats.retained.Load(), 0))
+	for i := range s.SizeHistogram {
+		s.SizeHistogram[i] = builderStats.hist[i].Load()
+	}
+}
+
+// checkOut records that a Buffer was taken from the pool.
+func checkOut() {
+	checkOutN(1)
+}
+
+// checkOutN is checkOut for n Buffers at once.
+func checkOutN(n int64) {
+	if err := tryCheckOutN(n); err != nil {
+		panic(err)
+	}
+}
+
+// tryCheckOutN is like checkOutN but returns ErrOutstanding, without
+// recording anything, instead of panicking.
+func tryCheckOutN(n int64) error {
+	total, err := reserveOutstanding(&bufferStats.outstanding, n)
+	if err != nil {
+		return err
+	}
+	storeMax(&bufferStats.peak, total)
+	storeMax(&bufferStats.peakOutstanding, total)
+	storeMax(&bufferStats.maxOutstanding, total)
+	return nil
+}
+
+// checkIn records that b is being returned to the pool.
+func checkIn(b *Buffer) {
+	checkInN(1, int64(b.Cap()-b.Available()))
+}
+
+// checkInN records that n Buffers, the largest of which held size bytes,
+// are being returned to the pool.
+func checkInN(n, size int64) {
+	bufferStats.puts.Add(uint64(n))
+	bufferStats.outstanding.Add(-n)
+	storeMax(&bufferStats.peakSize, size)
+	storeMax(&bufferStats.maxSize, size)
+}
+
+// storeMax sets a to n if n is larger.
+func storeMax(a *atomic.Int64, n int64) {
+	for v := a.Load(); n > v; v = a.Load() {
+		if a.CompareAndSwap(v, n) {
+			return
+		}
+	}
+}
+
+// ResetStats resets the counters and the PeakOutstanding and PeakSize
+// high-water marks reported by ReadStats, and the counters and histogram
+// reported by ReadBuilderStats.
+func ResetStats() {
+	builderStats.gets.Store(0)
+	builderStats.puts.Store(0)
+	builderStats.news.Store(0)
+	builderStats.discards.Store(0)
+	for i := range builderStats.hist {
+		builderStats.hist[i].Store(0)
+	}
+
+	bufferStats.gets.Store(0)
+	bufferStats.puts.Store(0)
+	bufferStats.news.Store(0)
+	bufferStats.discards.Store(0)
+	memoryPressure.Store(0)
+	bufferStats.peakOutstanding.Store(max(bufferStats.outstanding.Load(), 0))
+	bufferStats.peakSize.Store(0)
+}
+
+// ReadStats populates s with statistics about the Buffer pool.
+func ReadStats(s *Stats) {
+	s.Gets = bufferStats.gets.Load()
+	s.Puts = bufferStats.puts.Load()
+	s.News = bufferStats.news.Load()
+	s.Discards = bufferStats.discards.Load()
+	s.Outstanding = int(max(bufferStats.outstanding.Load(), 0))
+	for i := range s.SizeHistogram {
+		s.SizeHistogram[i] = uint64(trim.hist[i].Load())
+	}
+	s.RetainCap = int(retainCap())
+	s.GrowHint = int(growHint())
+	s.GCScale = float64(gcScale.Load()) / gcScaleOne
+	s.MemoryPressure = memoryPressure.Load()
+	s.PeakOutstanding = int(bufferStats.peakOutstanding.Load())
+	s.MaxOutstanding = int(bufferStats.maxOutstanding.Load())
+	s.PeakSize = int(bufferStats.peakSize.Load())
+	s.MaxSize = int(bufferStats.maxSize.Load())
+}
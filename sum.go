@@ -0,0 +1,50 @@
+package pools
+
+import (
+	"crypto/sha256"
+	"hash"
+	"sync"
+)
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// Sum64 returns the 64-bit FNV-1a hash of the unread portion of w, the same
+// as hash/fnv's New64a, without allocating a hasher. It's meant for ETags and
+// deduplication, not for security.
+func (w *Buffer) Sum64() uint64 {
+	h := uint64(fnvOffset64)
+	for _, c := range w.Bytes() {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// sha256Pool holds idle *sha256State.
+var sha256Pool = sync.Pool{
+	New: func() interface{} {
+		return &sha256State{h: sha256.New()}
+	},
+}
+
+// sha256State is a SHA-256 hasher and room for its sum, so neither is
+// allocated per call.
+type sha256State struct {
+	h   hash.Hash
+	sum [sha256.Size]byte
+}
+
+// SumSHA256 returns the SHA-256 checksum of the unread portion of w,
+// computed with a pooled hasher.
+func (w *Buffer) SumSHA256() [sha256.Size]byte {
+	s := sha256Pool.Get().(*sha256State)
+	s.h.Reset()
+	s.h.Write(w.Bytes())
+	s.h.Sum(s.sum[:0])
+	sum := s.sum
+	sha256Pool.Put(s)
+	return sum
+}
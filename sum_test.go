@@ -0,0 +1,30 @@
+package pools
+
+import (
+	"crypto/sha256"
+	"hash/fnv"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	b := GetBuffer()
+	defer PutBuffer(b)
+	b.WriteString("skip: payload")
+	b.Next(6)
+
+	h := fnv.New64a()
+	h.Write([]byte("payload"))
+	expect(t, h.Sum64(), b.Sum64())
+	expect(t, sha256.Sum256([]byte("payload")), b.SumSHA256())
+	expect(t, sha256.Sum256(nil), new(Buffer).SumSHA256())
+
+	if raceEnabled {
+		t.Skip("sync.Pool drops objects under the race detector")
+	}
+	if n := testing.AllocsPerRun(100, func() {
+		b.Sum64()
+		b.SumSHA256()
+	}); n != 0 {
+		t.Fatalf("want no allocations, got %v", n)
+	}
+}
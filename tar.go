@@ -0,0 +1,31 @@
+package pools
+
+import (
+	"archive/tar"
+	"io"
+	"sync"
+)
+
+var tarWriterPool = sync.Pool{
+	New: func() interface{} {
+		return new(tar.Writer)
+	},
+}
+
+// GetTarWriter returns a pooled tar.Writer writing to w, which might be a
+// Buffer or, for archives that can get large, a SpillBuffer. A tar.Writer
+// carries the 512-byte block it formats headers in, so that is reused along
+// with it. Return it with PutTarWriter.
+func GetTarWriter(w io.Writer) *tar.Writer {
+	tw := tarWriterPool.Get().(*tar.Writer)
+	// tar.Writer has no Reset, so overwrite the pooled one.
+	*tw = *tar.NewWriter(w)
+	return tw
+}
+
+// PutTarWriter returns tw to the pool. It doesn't Close tw, which must be
+// done first to write the archive's trailer.
+func PutTarWriter(tw *tar.Writer) {
+	*tw = tar.Writer{}
+	tarWriterPool.Put(tw)
+}
@@ -0,0 +1,66 @@
+package pools
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTarWriter(t *testing.T) {
+	s := GetSpillBuffer(1<<10, t.TempDir())
+	defer func() {
+		if err := PutSpillBuffer(s); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	tw := GetTarWriter(s)
+	files := map[string]string{"small": "hello", "big": strings.Repeat("x", 4<<10)}
+	for _, name := range []string{"small", "big"} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name]))})
+		io.WriteString(tw, files[name])
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	PutTarWriter(tw)
+	expect(t, true, s.Spilled())
+
+	var b bytes.Buffer
+	if _, err := s.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, s.Len(), int64(b.Len()))
+	tr := tar.NewReader(&b)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, _ := io.ReadAll(tr)
+		expect(t, files[h.Name], string(p))
+		delete(files, h.Name)
+	}
+	expect(t, 0, len(files))
+}
+
+func TestSpillBuffer(t *testing.T) {
+	s := GetSpillBuffer(8, t.TempDir())
+	defer PutSpillBuffer(s)
+	s.Write([]byte("1234"))
+	expect(t, false, s.Spilled())
+	var b bytes.Buffer
+	s.WriteTo(&b)
+	s.WriteTo(&b)
+	expect(t, "12341234", b.String())
+	s.Write([]byte("56789"))
+	expect(t, true, s.Spilled())
+	b.Reset()
+	s.WriteTo(&b)
+	expect(t, "123456789", b.String())
+}
@@ -0,0 +1,64 @@
+package pools
+
+import "sync"
+
+// Task is a pooled message for Workers: a payload, metadata, and a
+// completion callback. Queues that pass every message through a Task from
+// GetTask and SubmitTask don't allocate per message.
+type Task struct {
+	// Payload holds the message body.
+	Payload *Buffer
+
+	// Meta holds the message's metadata, such as headers or a trace ID.
+	// The map is cleared, not reallocated, when the Task is reused.
+	Meta map[string]string
+
+	// Done, if not nil, is called on the worker with the Task and the
+	// error from running it, or a *PanicError if it panicked. It must not
+	// retain the Task.
+	Done func(t *Task, err error)
+}
+
+var taskPool = sync.Pool{
+	New: func() interface{} {
+		return &Task{Meta: make(map[string]string)}
+	},
+}
+
+// GetTask returns a Task with an empty Payload and Meta.
+func GetTask() *Task {
+	t := taskPool.Get().(*Task)
+	t.Payload = GetBuffer()
+	return t
+}
+
+// PutTask returns t and its Payload to their pools. Tasks passed to
+// SubmitTask are returned automatically.
+func PutTask(t *Task) {
+	PutBuffer(t.Payload)
+	t.Payload = nil
+	clear(t.Meta)
+	t.Done = nil
+	taskPool.Put(t)
+}
+
+// SubmitTask queues t to be run by a worker with run, like Submit. Once run
+// returns, t.Done is called and t is returned to its pool with PutTask. If
+// run panics and t.Done is nil the panic goes to the Workers' panic handler.
+func (w *Workers) SubmitTask(t *Task, run func(*Task) error) error {
+	return w.submit(job{task: t, run: run})
+}
+
+func (w *Workers) runTask(t *Task, run func(*Task) error) {
+	var err error
+	perr := call(func() { err = run(t) })
+	if perr != nil {
+		err = perr
+	}
+	if t.Done != nil {
+		t.Done(t, err)
+	} else if perr != nil && w.onPanic != nil {
+		w.onPanic(perr)
+	}
+	PutTask(t)
+}
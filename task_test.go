@@ -0,0 +1,40 @@
+package pools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTask(t *testing.T) {
+	var panics int
+	w := NewWorkers(1, func(*PanicError) { panics++ })
+	done := make(chan string, 1)
+
+	task := GetTask()
+	task.Payload.WriteString("hello")
+	task.Meta["id"] = "1"
+	task.Done = func(t *Task, err error) {
+		done <- t.Meta["id"] + ":" + t.Payload.String()
+	}
+	expect(t, nil, w.SubmitTask(task, func(t *Task) error {
+		t.Payload.WriteString(", world")
+		return nil
+	}))
+	expect(t, "1:hello, world", <-done)
+
+	var errs []string
+	task = GetTask()
+	task.Done = func(_ *Task, err error) { errs = append(errs, err.Error()) }
+	w.SubmitTask(task, func(*Task) error { panic("bang") })
+	w.SubmitTask(GetTask(), func(*Task) error { panic("bang") })
+	w.Close(context.Background())
+	expect(t, 1, len(errs))
+	expect(t, true, strings.Contains(errs[0], "bang"))
+	expect(t, 1, panics)
+
+	task = GetTask()
+	expect(t, 0, len(task.Meta))
+	expect(t, 0, task.Payload.Len())
+	PutTask(task)
+}
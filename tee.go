@@ -0,0 +1,91 @@
+package pools
+
+import (
+	"io"
+	"sync"
+)
+
+var teePool = sync.Pool{
+	New: func() interface{} {
+		return new(TeeWriter)
+	},
+}
+
+// TeeWriter writes to one or more downstream writers while capturing
+// everything written in a pooled Buffer, e.g. to log or cache a response
+// after it's been sent.
+type TeeWriter struct {
+	buf *Buffer
+	ws  []io.Writer
+}
+
+// Tee returns a pooled TeeWriter that writes to w. Return it with PutTee.
+func Tee(w io.Writer) *TeeWriter {
+	return Multi(w)
+}
+
+// Multi returns a pooled TeeWriter that writes to each of ws, like
+// io.MultiWriter. Return it with PutTee.
+func Multi(ws ...io.Writer) *TeeWriter {
+	t := teePool.Get().(*TeeWriter)
+	t.buf = GetBuffer()
+	t.ws = append(t.ws, ws...)
+	return t
+}
+
+// PutTee returns t and its Buffer to the pool. Neither may be used
+// afterward.
+func PutTee(t *TeeWriter) {
+	PutBuffer(t.buf)
+	t.buf = nil
+	clear(t.ws)
+	t.ws = t.ws[:0]
+	teePool.Put(t)
+}
+
+// Write writes p to each downstream writer in turn, stopping at the first
+// error, and captures p in t's Buffer. Like io.MultiWriter, a short write is
+// reported as io.ErrShortWrite.
+func (t *TeeWriter) Write(p []byte) (int, error) {
+	for _, w := range t.ws {
+		n, err := w.Write(p)
+		if err == nil && n != len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			t.buf.Write(p[:n])
+			return n, err
+		}
+	}
+	t.buf.Write(p)
+	return len(p), nil
+}
+
+// Buffer returns the Buffer holding everything written to t. It's only valid
+// until t is returned to the pool.
+func (t *TeeWriter) Buffer() *Buffer {
+	return t.buf
+}
+
+// MultiWriteTo writes w's unread contents to each of ws. Unlike WriteTo, w
+// isn't drained, and every writer is attempted even if an earlier one fails.
+// If any write fails, the returned slice holds each writer's error at the
+// writer's index; otherwise it's nil. Short writes are reported as
+// io.ErrShortWrite.
+func (w *Buffer) MultiWriteTo(ws ...io.Writer) []error {
+	var errs []error
+	p := w.Bytes()
+	for i, dst := range ws {
+		n, err := dst.Write(p)
+		if err == nil && n != len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(ws))
+			}
+			errs[i] = err
+		}
+	}
+	return errs
+}
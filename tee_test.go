@@ -0,0 +1,55 @@
+package pools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMulti(t *testing.T) {
+	var a, b strings.Builder
+	w := Multi(&a, &b)
+	defer PutTee(w)
+	w.Write([]byte("hello, "))
+	w.Write([]byte("world"))
+	expect(t, "hello, world", a.String())
+	expect(t, "hello, world", b.String())
+	expect(t, "hello, world", w.Buffer().String())
+}
+
+func TestTeeReuse(t *testing.T) {
+	var a, b strings.Builder
+	w := Tee(&a)
+	w.Write([]byte("x"))
+	PutTee(w)
+
+	w = Tee(&b)
+	defer PutTee(w)
+	w.Write([]byte("y"))
+	expect(t, "x", a.String())
+	expect(t, "y", w.Buffer().String())
+}
+
+type errWriter struct{ err error }
+
+func (e errWriter) Write(p []byte) (int, error) { return 0, e.err }
+
+func TestBuffer_MultiWriteTo(t *testing.T) {
+	b := GetBuffer()
+	defer PutBuffer(b)
+	b.WriteString("payload")
+
+	var a, c strings.Builder
+	boom := errors.New("boom")
+	errs := b.MultiWriteTo(&a, errWriter{boom}, &c)
+	expect(t, 3, len(errs))
+	expect(t, nil, errs[0])
+	expect(t, boom, errs[1])
+	expect(t, "payload", a.String())
+	expect(t, "payload", c.String())
+	expect(t, "payload", b.String())
+
+	if errs := b.MultiWriteTo(&a); errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
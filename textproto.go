@@ -0,0 +1,53 @@
+package pools
+
+import (
+	"io"
+	"net/textproto"
+	"sync"
+)
+
+var textReaderPool = sync.Pool{
+	New: func() interface{} {
+		return new(textproto.Reader)
+	},
+}
+
+// GetTextReader returns a pooled textproto.Reader reading from r through a
+// pooled bufio.Reader. Return both with PutTextReader.
+func GetTextReader(r io.Reader) *textproto.Reader {
+	tr := textReaderPool.Get().(*textproto.Reader)
+	tr.R = GetBufioReader(r)
+	return tr
+}
+
+// PutTextReader returns tr and its bufio.Reader to their pools. Any buffered
+// data is discarded.
+func PutTextReader(tr *textproto.Reader) {
+	PutBufioReader(tr.R)
+	// Zero tr so the next user doesn't inherit an unfinished dot reader.
+	*tr = textproto.Reader{}
+	textReaderPool.Put(tr)
+}
+
+var textWriterPool = sync.Pool{
+	New: func() interface{} {
+		return new(textproto.Writer)
+	},
+}
+
+// GetTextWriter returns a pooled textproto.Writer writing to w through a
+// pooled bufio.Writer. Return both with PutTextWriter.
+func GetTextWriter(w io.Writer) *textproto.Writer {
+	tw := textWriterPool.Get().(*textproto.Writer)
+	tw.W = GetBufioWriter(w)
+	return tw
+}
+
+// PutTextWriter returns tw and its bufio.Writer to their pools. PrintfLine
+// flushes as it goes, but anything written to tw.W or a DotWriter that
+// hasn't been flushed or closed is discarded.
+func PutTextWriter(tw *textproto.Writer) {
+	PutBufioWriter(tw.W)
+	*tw = textproto.Writer{}
+	textWriterPool.Put(tw)
+}
@@ -0,0 +1,30 @@
+package pools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextproto(t *testing.T) {
+	var sb strings.Builder
+	tw := GetTextWriter(&sb)
+	tw.PrintfLine("HELO %s", "example.com")
+	dw := tw.DotWriter()
+	dw.Write([]byte("line\n.dot\n"))
+	dw.Close()
+	PutTextWriter(tw)
+	expect(t, "HELO example.com\r\nline\r\n..dot\r\n.\r\n", sb.String())
+
+	tr := GetTextReader(strings.NewReader(sb.String()))
+	defer PutTextReader(tr)
+	line, err := tr.ReadLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "HELO example.com", line)
+	lines, err := tr.ReadDotLines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "line|.dot", strings.Join(lines, "|"))
+}
@@ -0,0 +1,32 @@
+package pools
+
+import (
+	"context"
+	"runtime/trace"
+	"strconv"
+	"sync/atomic"
+)
+
+var tracing atomic.Bool
+
+// SetTracing turns on, or off, logging every Get and Put to the execution
+// tracer (see runtime/trace) under the "pools" category, so the lifetimes
+// of pooled objects show up next to GC and scheduler events when debugging
+// latency. Events are only logged while a trace is being collected.
+func SetTracing(enabled bool) {
+	tracing.Store(enabled)
+}
+
+// traceEvent logs op on an object of the given kind and size.
+func traceEvent(op, kind string, size int) {
+	if !tracing.Load() || !trace.IsEnabled() {
+		return
+	}
+	msg := make([]byte, 0, 32)
+	msg = append(msg, op...)
+	msg = append(msg, ' ')
+	msg = append(msg, kind...)
+	msg = append(msg, ' ')
+	msg = strconv.AppendInt(msg, int64(size), 10)
+	trace.Log(context.Background(), "pools", string(msg))
+}
@@ -0,0 +1,111 @@
+package pools
+
+import (
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	trimSampleRate = 16       // one in trimSampleRate Puts is sampled.
+	trimWindow     = 256      // number of recent sizes the statistics cover.
+	trimFactor     = 4        // how many times the p95 a Buffer may grow to.
+	trimMinSize    = 64 << 10 // Buffers this small are always retained.
+)
+
+// numSizeClasses is the number of buckets in a size histogram. Bucket i
+// counts sizes in [1<<(i-1), 1<<i), with bucket 0 counting empty Buffers and
+// the last bucket counting everything larger.
+const numSizeClasses = 33
+
+// sizeClass returns the histogram bucket for n.
+func sizeClass(n int) int {
+	return min(bits.Len(uint(n)), numSizeClasses-1)
+}
+
+// trim learns what size Buffers the program uses. A single huge payload would
+// otherwise leave a huge Buffer in the pool forever, and every caller that
+// later gets it from the pool would pin that memory.
+//
+// A sample of Puts records the Buffer's size. Every trimWindow samples, the
+// recorded sizes are summarized into a histogram, the retention cap, and the
+// Grow hint. Sampled Puts of Buffers whose capacity exceeds the retention cap
+// are dropped so the GC can reclaim them, and new Buffers are created with
+// the Grow hint's capacity.
+var trim struct {
+	samples atomic.Uint64
+	sizes   [trimWindow]atomic.Uint32
+
+	p95       atomic.Int64
+	retainCap atomic.Int64 // 0 until enough sizes have been seen.
+	growHint  atomic.Int64 // median size.
+	hist      [numSizeClasses]atomic.Uint32
+
+	maxSize atomic.Int64 // see SetMaxBufferSize.
+}
+
+// SetMaxBufferSize causes Buffers whose capacity exceeds n to always be
+// dropped instead of returned to the pool, in addition to those dropped
+// because they're much larger than recent Buffers. A n <= 0 removes the cap,
+// which is the default.
+func SetMaxBufferSize(n int) {
+	trim.maxSize.Store(int64(max(n, 0)))
+}
+
+// retain reports whether b should go back into the pool.
+func retain(b *Buffer) bool {
+	if m := trim.maxSize.Load(); m > 0 && int64(b.Cap()) > m {
+		return false
+	}
+	if rand.Uint32()%trimSampleRate != 0 {
+		return true
+	}
+
+	// Bytes written since the last Reset, including any already read.
+	size := b.Cap() - b.Available()
+	n := trim.samples.Add(1)
+	trim.sizes[n%trimWindow].Store(uint32(min(uint64(size), math.MaxUint32)))
+	if n%trimWindow == 0 {
+		learnSizes()
+	}
+
+	limit := retainCap()
+	if limit == 0 {
+		return true
+	}
+	return int64(b.Cap()) <= limit
+}
+
+// learnSizes recomputes the statistics derived from the recorded sizes.
+func learnSizes() {
+	var sizes [trimWindow]uint32
+	for i := range sizes {
+		sizes[i] = trim.sizes[i].Load()
+	}
+	slices.Sort(sizes[:])
+
+	var hist [numSizeClasses]uint32
+	for _, s := range sizes {
+		hist[sizeClass(int(s))]++
+	}
+	for i := range hist {
+		trim.hist[i].Store(hist[i])
+	}
+
+	p95 := int64(sizes[trimWindow*95/100])
+	trim.p95.Store(p95)
+	trim.retainCap.Store(max(p95*trimFactor, trimMinSize))
+	trim.growHint.Store(int64(sizes[trimWindow/2]))
+}
+
+// newBuffer allocates a Buffer with the learned Grow hint's capacity.
+func newBuffer() *Buffer {
+	b := &Buffer{created: time.Now(), epoch: bufferEpoch.Load()}
+	if n := growHint(); n > 0 {
+		b.Grow(int(n))
+	}
+	return b
+}
@@ -0,0 +1,272 @@
+package pools
+
+import (
+	rtdebug "runtime/debug"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetain(t *testing.T) {
+	defer trim.retainCap.Store(0)
+	defer trim.growHint.Store(0)
+
+	small := GetBufferSize(1024)
+	defer PutBuffer(small)
+	small.Write(make([]byte, 1024))
+	for i := 0; i < trimWindow*trimSampleRate*4; i++ {
+		retain(small)
+	}
+	if p := trim.p95.Load(); p != 1024 {
+		t.Fatalf("want p95 1024, got %d", p)
+	}
+
+	var s Stats
+	ReadStats(&s)
+	expect(t, trimMinSize, s.RetainCap)
+	expect(t, 1024, s.GrowHint)
+	expect(t, uint64(trimWindow), s.SizeHistogram[sizeClass(1024)])
+
+	big := GetBufferSize(1 << 20)
+	var dropped bool
+	for i := 0; i < trimSampleRate*100 && !dropped; i++ {
+		dropped = !retain(big)
+	}
+	if !dropped {
+		t.Fatal("oversized Buffer was never dropped")
+	}
+
+	// Small buffers are always kept.
+	for i := 0; i < trimSampleRate*100; i++ {
+		if !retain(small) {
+			t.Fatal("small Buffer was dropped")
+		}
+	}
+
+	if b := newBuffer(); b.Cap() < 1024 {
+		t.Fatalf("new Buffer ignored the grow hint: cap %d", b.Cap())
+	}
+}
+
+func TestReadStats(t *testing.T) {
+	var before, after Stats
+	ReadStats(&before)
+	PutBuffer(GetBuffer())
+	ReadStats(&after)
+	expect(t, before.Gets+1, after.Gets)
+	expect(t, before.Puts+1, after.Puts)
+}
+
+func TestDrain(t *testing.T) {
+	b := GetBuffer()
+	b.WriteString("drained")
+	PutBuffer(b)
+	trim.growHint.Store(1024)
+
+	Drain()
+	var s Stats
+	ReadStats(&s)
+	expect(t, Stats{
+		Outstanding:     s.Outstanding,
+		PeakOutstanding: s.PeakOutstanding,
+		MaxOutstanding:  s.MaxOutstanding,
+		MaxSize:         s.MaxSize,
+		GCScale:         1,
+	}, s)
+
+	b = GetBuffer()
+	defer PutBuffer(b)
+	if b.Cap() != 0 {
+		t.Fatalf("want a new Buffer, got cap %d", b.Cap())
+	}
+	ReadStats(&s)
+	expect(t, uint64(1), s.News)
+}
+
+func TestIdleTTL(t *testing.T) {
+	SetIdleTTL(time.Hour)
+	defer SetIdleTTL(0)
+	Drain()
+
+	fresh := newBuffer()
+	fresh.idleSince = time.Now()
+	bufferPool.Put(fresh)
+	var s Stats
+	// sync.Pool randomly drops Puts under the race detector, so try a few
+	// times.
+	for i := 0; i < 100 && s.Discards == 0; i++ {
+		stale := newBuffer()
+		stale.idleSince = time.Now().Add(-2 * time.Hour)
+		bufferPool.Put(stale)
+		sweepIdle()
+		ReadStats(&s)
+	}
+	expect(t, uint64(1), s.Discards)
+}
+
+func TestShrink(t *testing.T) {
+	Drain()
+	shrink()
+	// Other tests may not have returned every Buffer.
+	base := int(max(bufferStats.outstanding.Load(), 0))
+
+	bs := make([]*Buffer, 4)
+	for i := range bs {
+		bs[i] = GetBuffer()
+	}
+	for _, b := range bs {
+		PutBuffer(b)
+	}
+
+	// Four Buffers were in use at once, so none are released.
+	if n := shrink(); n > base+4 {
+		t.Fatalf("want at most %d kept, got %d", base+4, n)
+	}
+	// Nothing has been used since.
+	if n := shrink(); n > base {
+		t.Fatalf("want at most %d kept, got %d", base, n)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	j := StartJanitor(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	j.Stop()
+	j.Stop()
+}
+
+func TestHighWaterMarks(t *testing.T) {
+	ResetStats()
+	base := max(int(bufferStats.outstanding.Load()), 0)
+
+	a, b := GetBuffer(), GetBuffer()
+	a.Write(make([]byte, 100))
+	PutBuffer(a)
+	PutBuffer(b)
+
+	var s Stats
+	ReadStats(&s)
+	expect(t, base+2, s.PeakOutstanding)
+	expect(t, 100, s.PeakSize)
+	if s.MaxOutstanding < s.PeakOutstanding || s.MaxSize < s.PeakSize {
+		t.Fatalf("lifetime maximums below peaks: %+v", s)
+	}
+
+	ResetStats()
+	ReadStats(&s)
+	expect(t, base, s.PeakOutstanding)
+	expect(t, 0, s.PeakSize)
+}
+
+func TestParseEnv(t *testing.T) {
+	defer SetMaxBufferSize(0)
+	defer SetMaxOutstanding(0)
+	defer TrackLeaks(false)
+
+	parseEnv("maxsize=1MB, maxoutstanding=10,leakcheck=1,bogus=1,idlettl=x")
+	expect(t, int64(1<<20), trim.maxSize.Load())
+	expect(t, int64(10), maxOutstanding.Load())
+	expect(t, true, leaks.enabled.Load())
+	expect(t, int64(0), idle.ttl.Load())
+
+	for s, want := range map[string]int{"0": 0, "512": 512, "4KB": 4 << 10, "1GB": 1 << 30} {
+		n, ok := parseSize(s)
+		expect(t, true, ok)
+		expect(t, want, n)
+	}
+	for _, s := range []string{"", "MB", "-1", "1TB"} {
+		if _, ok := parseSize(s); ok {
+			t.Fatalf("want %q rejected", s)
+		}
+	}
+}
+
+func TestMaxBufferSize(t *testing.T) {
+	defer SetMaxBufferSize(0)
+	SetMaxBufferSize(1024)
+	small, big := GetBufferSize(512), GetBufferSize(4096)
+	defer PutBuffer(small)
+	defer PutBuffer(big)
+	expect(t, true, retain(small))
+	expect(t, false, retain(big))
+}
+
+func TestInvalidate(t *testing.T) {
+	var evicted atomic.Int64
+	OnEvict(func(e Eviction) {
+		if e.Reason == EvictInvalidated {
+			evicted.Add(1)
+		}
+	})
+
+	b := GetBuffer()
+	Invalidate()
+	PutBuffer(b)
+	expect(t, int64(1), evicted.Load())
+	c := GetBuffer()
+	defer PutBuffer(c)
+	if c == b {
+		t.Fatal("want a new Buffer after Invalidate")
+	}
+}
+
+func TestGCScale(t *testing.T) {
+	defer trim.retainCap.Store(0)
+	defer trim.growHint.Store(0)
+	defer gcScale.Store(gcScaleOne)
+	trim.retainCap.Store(1 << 20)
+	trim.growHint.Store(1 << 10)
+
+	adaptGCScale(true)
+	expect(t, int64(1<<19), retainCap())
+	expect(t, int64(1<<9), growHint())
+	for range 10 {
+		adaptGCScale(true)
+	}
+	expect(t, int64(trimMinSize), retainCap()) // 1<<20 / 16
+	var s Stats
+	ReadStats(&s)
+	expect(t, 1.0/16, s.GCScale)
+
+	trim.retainCap.Store(100)
+	expect(t, int64(100), retainCap()) // small caps aren't scaled.
+	for range 4 {
+		adaptGCScale(false)
+	}
+	expect(t, int64(gcScaleOne), gcScale.Load())
+
+	m := newGCMonitor()
+	m.pressure()
+	limit := rtdebug.SetMemoryLimit(1 << 20)
+	_, mem := m.pressure()
+	rtdebug.SetMemoryLimit(limit)
+	expect(t, true, mem)
+	_, mem = m.pressure()
+	expect(t, false, mem)
+
+	gcScale.Store(gcScaleMin)
+	j := StartGCMonitor(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	j.Stop()
+	expect(t, int64(gcScaleOne), gcScale.Load())
+}
+
+func TestMemoryPressure(t *testing.T) {
+	var called atomic.Int64
+	OnMemoryPressure(func() { called.Add(1) })
+	before := memoryPressure.Load()
+
+	b := GetBuffer()
+	b.WriteString("idle")
+	PutBuffer(b)
+	relieveMemory()
+	expect(t, int64(1), called.Load())
+	var s Stats
+	ReadStats(&s)
+	expect(t, before+1, s.MemoryPressure)
+	if b2 := GetBuffer(); b2 == b {
+		t.Fatal("want idle Buffers released")
+	} else {
+		PutBuffer(b2)
+	}
+}
@@ -0,0 +1,17 @@
+package pools
+
+import "database/sql/driver"
+
+// Value implements driver.Valuer, so a Buffer can be bound directly as a
+// bytea or blob argument. The returned slice aliases w, so w must not be
+// modified or returned to the pool until the statement using it returns.
+// An empty Buffer is an empty value, not NULL.
+func (w *Buffer) Value() (driver.Value, error) {
+	p := w.Bytes()
+	if p == nil {
+		p = []byte{}
+	}
+	return p, nil
+}
+
+var _ driver.Valuer = (*Buffer)(nil)
@@ -0,0 +1,87 @@
+package pools
+
+import (
+	"io"
+	"net"
+	"sync"
+)
+
+var segmentsPool = sync.Pool{
+	New: func() interface{} {
+		return new(Segments)
+	},
+}
+
+// Segments collects the pieces of a message, such as a frame header and its
+// body, and writes them with a single vectored write (writev) when the
+// destination is a net.Conn that supports it. Pieces are either referenced
+// in place with Append or held in pooled slices from GetBytes, which are
+// recycled once the message is written.
+type Segments struct {
+	bufs  net.Buffers
+	owned [][]byte // the slices from GetBytes.
+	n     int
+	tmp   net.Buffers // consumed by WriteTo.
+}
+
+func GetSegments() *Segments {
+	return segmentsPool.Get().(*Segments)
+}
+
+// PutSegments recycles s's pooled slices and returns s to the pool.
+func PutSegments(s *Segments) {
+	s.Reset()
+	segmentsPool.Put(s)
+}
+
+// Append adds p as the next segment without copying it. p must not be
+// modified until s is written or reset.
+func (s *Segments) Append(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	s.bufs = append(s.bufs, p)
+	s.n += len(p)
+}
+
+// Alloc adds a pooled segment of n bytes for the caller to fill, for
+// example with a frame header, and returns it.
+func (s *Segments) Alloc(n int) []byte {
+	p := GetBytes(n)
+	s.owned = append(s.owned, p)
+	s.Append(p)
+	return p
+}
+
+// Write copies p into a pooled segment.
+func (s *Segments) Write(p []byte) (int, error) {
+	copy(s.Alloc(len(p)), p)
+	return len(p), nil
+}
+
+// Len returns the total length of the segments.
+func (s *Segments) Len() int {
+	return s.n
+}
+
+// WriteTo writes the segments to w, using a single writev if w is a
+// net.Conn that supports it, then resets s.
+func (s *Segments) WriteTo(w io.Writer) (int64, error) {
+	s.tmp = append(s.tmp[:0], s.bufs...)
+	n, err := s.tmp.WriteTo(w)
+	clear(s.tmp[:cap(s.tmp)])
+	s.Reset()
+	return n, err
+}
+
+// Reset drops the segments and recycles the pooled ones.
+func (s *Segments) Reset() {
+	for i, p := range s.owned {
+		PutBytes(p)
+		s.owned[i] = nil
+	}
+	s.owned = s.owned[:0]
+	clear(s.bufs)
+	s.bufs = s.bufs[:0]
+	s.n = 0
+}
@@ -0,0 +1,32 @@
+package pools
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	body := []byte("hello, world")
+	s := GetSegments()
+	defer PutSegments(s)
+	binary.BigEndian.PutUint32(s.Alloc(4), uint32(len(body)))
+	s.Append(body)
+	s.Write([]byte("!"))
+	expect(t, 4+len(body)+1, s.Len())
+
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+	go func() {
+		defer c1.Close()
+		if _, err := s.WriteTo(c1); err != nil {
+			t.Error(err)
+		}
+	}()
+	p, err := io.ReadAll(c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(t, "\x00\x00\x00\x0chello, world!", string(p))
+}
@@ -0,0 +1,105 @@
+package pools
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+var verifying atomic.Bool
+
+// SetVerify turns on, or off, checking the output of WriteGroups,
+// WriteInterval, and WriteIntervalStep. While on, each call parses the
+// placeholders it wrote and panics if their number, order, or parentheses
+// don't match its arguments, so fuzzers and staging environments catch a
+// regression in the generators before a malformed statement reaches the
+// database. Writes of literals (see SetLiterals) aren't checked. Checking
+// roughly doubles the cost of each call and allocates.
+//
+// It can also be turned on with POOLSDEBUG=verify=1.
+func SetVerify(enabled bool) {
+	verifying.Store(enabled)
+}
+
+// verifyGroups checks what WriteGroups wrote to w after off.
+func (w *Buffer) verifyGroups(off, offset, groupLen, groups int, prefix []int) {
+	want := make([][]int, max(groups, 1))
+	for g := range want {
+		vals := append([]int(nil), prefix...)
+		for i := range max(groupLen, 1) {
+			vals = append(vals, offset+g*groupLen+i)
+		}
+		want[g] = vals
+	}
+	w.verify("WriteGroups", off, want)
+}
+
+// verifyInterval checks what WriteInterval or WriteIntervalStep wrote to w
+// after off.
+func (w *Buffer) verifyInterval(fn string, off, from, to, step, num int) {
+	var vals []int
+	for k := range (to-from)/step + 1 {
+		vals = append(vals, from+k*step)
+	}
+	want := make([][]int, num)
+	for g := range want {
+		want[g] = vals
+	}
+	w.verify(fn, off, want)
+}
+
+func (w *Buffer) verify(fn string, off int, want [][]int) {
+	if err := checkPlaceholders(w.Bytes()[off:], w.dialect, want); err != nil {
+		panic("pools: " + fn + " wrote invalid output: " + err.Error())
+	}
+}
+
+// checkPlaceholders reports whether out is exactly the parenthesized,
+// comma-separated groups of placeholders in want, written in dialect d.
+func checkPlaceholders(out []byte, d Dialect, want [][]int) error {
+	consume := func(s string) bool {
+		if !bytes.HasPrefix(out, []byte(s)) {
+			return false
+		}
+		out = out[len(s):]
+		return true
+	}
+	for g, vals := range want {
+		if g > 0 && !consume(",") {
+			return fmt.Errorf("missing ',' before group %d in %q", g+1, out)
+		}
+		if !consume(" (") {
+			return fmt.Errorf("group %d doesn't open with \" (\": %q", g+1, out)
+		}
+		for i, v := range vals {
+			if i > 0 && !consume(", ") {
+				return fmt.Errorf("missing \", \" in group %d: %q", g+1, out)
+			}
+			if !consume(d.prefix()) {
+				return fmt.Errorf("placeholder %d of group %d doesn't start with %q: %q",
+					i+1, g+1, d.prefix(), out)
+			}
+			if !d.numbered() {
+				continue
+			}
+			n := 0
+			for n < len(out) && (out[n] == '-' && n == 0 || '0' <= out[n] && out[n] <= '9') {
+				n++
+			}
+			got, err := strconv.Atoi(string(out[:n]))
+			if err != nil || got != v {
+				return fmt.Errorf("placeholder %d of group %d is %s%s, want %s%d",
+					i+1, g+1, d.prefix(), out[:n], d.prefix(), v)
+			}
+			out = out[n:]
+		}
+		if !consume(")") {
+			return fmt.Errorf("group %d isn't closed: %q", g+1, out)
+		}
+	}
+	if len(out) > 0 {
+		return fmt.Errorf("unexpected trailing output %q", out)
+	}
+	return nil
+}
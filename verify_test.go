@@ -0,0 +1,46 @@
+package pools
+
+import "testing"
+
+func TestVerify(t *testing.T) {
+	SetVerify(true)
+	defer SetVerify(false)
+
+	for _, d := range []Dialect{Postgres, MySQL, Oracle, SQLServer} {
+		b := GetBuffer()
+		b.SetDialect(d)
+		b.WriteString("VALUES")
+		expect(t, nil, b.WriteGroups(1, 3, 4, 7))
+		expect(t, nil, b.WriteGroups(1, 3, 4)) // from the shape cache.
+		expect(t, nil, b.WriteInterval(2, 5, 3))
+		expect(t, nil, b.WriteIntervalStep(9, 1, -4, 2))
+		PutBuffer(b)
+	}
+
+	var b Buffer
+	b.WriteString(" ($1, $3)")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want a panic for invalid output")
+		}
+	}()
+	b.verifyInterval("WriteInterval", 0, 1, 2, 1, 1)
+}
+
+func TestCheckPlaceholders(t *testing.T) {
+	want := [][]int{{1, 2}, {3, 4}}
+	expect(t, nil, checkPlaceholders([]byte(" ($1, $2), ($3, $4)"), Postgres, want))
+	expect(t, nil, checkPlaceholders([]byte(" (?, ?), (?, ?)"), MySQL, want))
+	for _, s := range []string{
+		" ($1, $2), ($3, $5)",
+		" ($1, $2), ($3, $4",
+		" ($1, $2) ($3, $4)",
+		" ($1, $2), ($3, $4), ($5, $6)",
+		" ($1 $2), ($3, $4)",
+		" ($1, $2), ($3, $4))",
+	} {
+		if checkPlaceholders([]byte(s), Postgres, want) == nil {
+			t.Errorf("want %q rejected", s)
+		}
+	}
+}
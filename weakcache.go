@@ -0,0 +1,88 @@
+package pools
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// WeakCache memoizes Buffers, such as the result of marshaling a payload
+// that's marshaled again and again, by a key such as a hash of the payload's
+// content. It holds its entries weakly: an entry lives only while a caller
+// holds the *Cached for it, after which the GC collects it and its Buffer
+// reverts to the pool. That gives memoization of hot payloads without
+// pinning memory for cold ones.
+//
+// The zero WeakCache is empty and ready to use. A WeakCache is safe for
+// concurrent use.
+type WeakCache struct {
+	mu sync.Mutex
+	m  map[uint64]weak.Pointer[Cached]
+}
+
+// Cached is an entry in a WeakCache. Its contents mustn't be modified, and
+// the slice from Bytes is only valid while the *Cached is reachable.
+type Cached struct {
+	b *Buffer
+}
+
+// Bytes returns the cached contents.
+func (c *Cached) Bytes() []byte { return c.b.Bytes() }
+
+// String returns the cached contents as a string.
+func (c *Cached) String() string { return c.b.String() }
+
+// Load returns the entry for key, or nil if there's none or it has been
+// collected.
+func (c *WeakCache) Load(key uint64) *Cached {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m[key].Value()
+}
+
+// Store takes ownership of b, which should come from GetBuffer, and caches
+// it under key, replacing any entry already there. The returned entry must
+// be kept reachable for as long as it's used; once it isn't, b is returned
+// to the pool with PutBuffer.
+func (c *WeakCache) Store(key uint64, b *Buffer) *Cached {
+	e := &Cached{b: b}
+	runtime.AddCleanup(e, c.evict, cleanupArg{key: key, b: b})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.m == nil {
+		c.m = make(map[uint64]weak.Pointer[Cached])
+	}
+	c.m[key] = weak.Make(e)
+	return e
+}
+
+// LoadOrMarshal returns the entry for key or, if there's none, stores the
+// result of calling marshal with a Buffer from the pool. If marshal fails
+// the Buffer is returned to the pool and its error returned.
+func (c *WeakCache) LoadOrMarshal(key uint64, marshal func(*Buffer) error) (*Cached, error) {
+	if e := c.Load(key); e != nil {
+		return e, nil
+	}
+	b := GetBuffer()
+	if err := marshal(b); err != nil {
+		PutBuffer(b)
+		return nil, err
+	}
+	return c.Store(key, b), nil
+}
+
+type cleanupArg struct {
+	key uint64
+	b   *Buffer
+}
+
+// evict runs once an entry has been collected, returning its Buffer to the
+// pool and forgetting it unless it has already been replaced.
+func (c *WeakCache) evict(arg cleanupArg) {
+	c.mu.Lock()
+	if wp, ok := c.m[arg.key]; ok && wp.Value() == nil {
+		delete(c.m, arg.key)
+	}
+	c.mu.Unlock()
+	PutBuffer(arg.b)
+}
@@ -0,0 +1,48 @@
+package pools
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWeakCache(t *testing.T) {
+	var c WeakCache
+	calls := 0
+	marshal := func(b *Buffer) error {
+		calls++
+		b.WriteString(`{"id":1}`)
+		return nil
+	}
+	e, err := c.LoadOrMarshal(1, marshal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e2, _ := c.LoadOrMarshal(1, marshal)
+	expect(t, e, e2)
+	expect(t, 1, calls)
+	expect(t, `{"id":1}`, e2.String())
+
+	errBoom := errors.New("boom")
+	if _, err := c.LoadOrMarshal(2, func(*Buffer) error { return errBoom }); err != errBoom {
+		t.Fatalf("want errBoom, got %v", err)
+	}
+	expect(t, (*Cached)(nil), c.Load(2))
+
+	// Once nothing refers to the entry it's collected and its Buffer
+	// returned to the pool.
+	before := Snapshot()
+	runtime.KeepAlive(e)
+	e, e2 = nil, nil
+	for i := 0; c.Load(1) != nil || Snapshot().Diff(before).Puts == 0; i++ {
+		if i == 100 {
+			t.Fatal("entry was never collected")
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	c.mu.Lock()
+	expect(t, 0, len(c.m))
+	c.mu.Unlock()
+}
@@ -0,0 +1,214 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ErrWorkersClosed is returned by Submit and SubmitWait after Close.
+var ErrWorkersClosed = errors.New("pools: Workers is closed")
+
+// PanicError is a panic recovered from a task run by Workers.
+type PanicError struct {
+	Value interface{} // the value passed to panic.
+	Stack []byte      // the goroutine's stack when it panicked.
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("pools: task panicked: %v\n%s", e.Value, e.Stack)
+}
+
+// Workers is a fixed number of goroutines that run submitted tasks, for
+// batch processing where spawning a goroutine per item would be as wasteful
+// as allocating a Buffer per item. A panicking task doesn't bring down the
+// program or its worker: the panic is recovered and reported as a
+// *PanicError.
+//
+// A Workers is safe for concurrent use.
+type Workers struct {
+	jobs    chan job
+	onPanic func(*PanicError)
+	wg      sync.WaitGroup
+
+	mu      sync.RWMutex
+	closed  bool
+	closing chan struct{} // closed by Close to wake blocked submitters.
+	once    sync.Once     // closes closing.
+	stopped chan struct{} // closed once every worker has returned.
+}
+
+// NewWorkers starts n goroutines that run tasks until Close is called.
+// Panics in tasks passed to Submit are passed to onPanic, which is called on
+// the worker's goroutine, or discarded if onPanic is nil. NewWorkers panics
+// if n <= 0.
+func NewWorkers(n int, onPanic func(*PanicError)) *Workers {
+	if n <= 0 {
+		panic("pools: non-positive size for NewWorkers")
+	}
+	w := &Workers{
+		jobs:    make(chan job, n),
+		onPanic: onPanic,
+		closing: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	w.wg.Add(n)
+	for range n {
+		go w.run()
+	}
+	return w
+}
+
+func (w *Workers) run() {
+	defer w.wg.Done()
+	for j := range w.jobs {
+		if j.task != nil {
+			w.runTask(j.task, j.run)
+		} else if err := call(j.f); err != nil && w.onPanic != nil {
+			w.onPanic(err)
+		}
+	}
+}
+
+// job is a queued function, or a Task and the function to run it with.
+// Queuing these by value lets SubmitTask avoid allocating a closure.
+type job struct {
+	f    func()
+	task *Task
+	run  func(*Task) error
+}
+
+// call runs f, recovering any panic.
+func call(f func()) (err *PanicError) {
+	defer func() {
+		if v := recover(); v != nil {
+			stack := make([]byte, 4<<10)
+			err = &PanicError{Value: v, Stack: stack[:runtime.Stack(stack, false)]}
+		}
+	}()
+	f()
+	return nil
+}
+
+// Submit queues f to be run by a worker, waiting while every worker is busy
+// and the queue is full. It returns ErrWorkersClosed if Close is called
+// while it waits.
+func (w *Workers) Submit(f func()) error {
+	return w.submit(job{f: f})
+}
+
+func (w *Workers) submit(j job) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrWorkersClosed
+	}
+	// Close can't take the lock to close jobs until every submitter waiting
+	// for room in the queue gives up its read lock.
+	select {
+	case w.jobs <- j:
+		return nil
+	case <-w.closing:
+		return ErrWorkersClosed
+	}
+}
+
+// SubmitWait is like Submit but waits for f to finish. If f panics,
+// SubmitWait returns the *PanicError instead of passing it to the Workers'
+// panic handler.
+func (w *Workers) SubmitWait(f func()) error {
+	done := make(chan *PanicError, 1)
+	err := w.Submit(func() {
+		perr := call(f)
+		done <- perr
+	})
+	if err != nil {
+		return err
+	}
+	if perr := <-done; perr != nil {
+		return perr
+	}
+	return nil
+}
+
+// Close stops accepting tasks and waits for those already submitted to
+// finish, after which the workers exit. If ctx is done first Close returns
+// ctx.Err() and the workers finish in the background. It's safe to call
+// Close more than once.
+func (w *Workers) Close(ctx context.Context) error {
+	w.once.Do(func() { close(w.closing) })
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.jobs)
+		go func() {
+			w.wg.Wait()
+			close(w.stopped)
+		}()
+	}
+	w.mu.Unlock()
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WorkerGroup is a group of tasks run by Workers, like an errgroup.Group
+// whose goroutines come from a pool. See Workers.Group.
+type WorkerGroup struct {
+	w      *Workers
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+// Group returns a WorkerGroup whose tasks run on w and a Context derived
+// from ctx. The Context is canceled the first time a task returns an error
+// or panics, or when Wait returns, whichever happens first.
+func (w *Workers) Group(ctx context.Context) (*WorkerGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &WorkerGroup{w: w, cancel: cancel}, ctx
+}
+
+// Go submits f to the group's Workers, waiting while every worker is busy
+// and the queue is full. Tasks that call Go can therefore deadlock a
+// Workers too small for them. A panic in f is recovered and returned by
+// Wait as a *PanicError.
+func (g *WorkerGroup) Go(f func() error) {
+	g.wg.Add(1)
+	err := g.w.Submit(func() {
+		defer g.wg.Done()
+		var err error
+		if perr := call(func() { err = f() }); perr != nil {
+			err = perr
+		}
+		if err != nil {
+			g.fail(err)
+		}
+	})
+	if err != nil {
+		g.wg.Done()
+		g.fail(err)
+	}
+}
+
+// fail records err if it's the group's first and cancels its Context.
+func (g *WorkerGroup) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel(err)
+	})
+}
+
+// Wait waits for every task passed to Go to finish and returns the first
+// error, if any.
+func (g *WorkerGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel(g.err)
+	return g.err
+}
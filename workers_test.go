@@ -0,0 +1,100 @@
+package pools
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkers(t *testing.T) {
+	var panics atomic.Int64
+	w := NewWorkers(4, func(*PanicError) { panics.Add(1) })
+
+	var n atomic.Int64
+	for range 100 {
+		expect(t, nil, w.Submit(func() { n.Add(1) }))
+	}
+	expect(t, nil, w.Submit(func() { panic("boom") }))
+
+	err := w.SubmitWait(func() { panic("bang") })
+	perr, ok := err.(*PanicError)
+	if !ok {
+		t.Fatalf("want a *PanicError, got %v", err)
+	}
+	expect(t, "bang", perr.Value)
+	expect(t, nil, w.SubmitWait(func() { n.Add(1) }))
+
+	expect(t, nil, w.Close(context.Background()))
+	expect(t, nil, w.Close(context.Background()))
+	expect(t, int64(101), n.Load())
+	expect(t, int64(1), panics.Load())
+	expect(t, ErrWorkersClosed, w.Submit(func() {}))
+}
+
+func TestWorkerGroup(t *testing.T) {
+	w := NewWorkers(2, nil)
+	defer w.Close(context.Background())
+
+	g, ctx := w.Group(context.Background())
+	var n atomic.Int64
+	for range 10 {
+		g.Go(func() error {
+			n.Add(1)
+			return nil
+		})
+	}
+	expect(t, nil, g.Wait())
+	expect(t, int64(10), n.Load())
+	expect(t, context.Canceled, ctx.Err())
+
+	boom := errors.New("boom")
+	g, ctx = w.Group(context.Background())
+	g.Go(func() error { return boom })
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	expect(t, boom, g.Wait())
+	expect(t, boom, context.Cause(ctx))
+
+	g, _ = w.Group(context.Background())
+	g.Go(func() error { panic("bang") })
+	if _, ok := g.Wait().(*PanicError); !ok {
+		t.Fatal("want a *PanicError")
+	}
+}
+
+func TestWorkersCloseTimeout(t *testing.T) {
+	w := NewWorkers(1, nil)
+	release := make(chan struct{})
+	w.Submit(func() { <-release })
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	expect(t, context.DeadlineExceeded, w.Close(ctx))
+	close(release)
+	expect(t, nil, w.Close(context.Background()))
+}
+
+func TestWorkersCloseBlockedSubmit(t *testing.T) {
+	w := NewWorkers(1, nil)
+	release := make(chan struct{})
+	errc := make(chan error)
+	go func() {
+		// Fill the worker and its queue, then block.
+		for {
+			if err := w.Submit(func() { <-release }); err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	expect(t, context.DeadlineExceeded, w.Close(ctx))
+	expect(t, ErrWorkersClosed, <-errc)
+	close(release)
+	expect(t, nil, w.Close(context.Background()))
+}
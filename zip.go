@@ -0,0 +1,90 @@
+package pools
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/flate"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// flatePools holds a pool of flateWriters for each compression level, from
+// flate.HuffmanOnly to flate.BestCompression.
+var flatePools [flate.BestCompression - flate.HuffmanOnly + 1]sync.Pool
+
+// flateWriter is a flate.Writer that returns itself to its pool when it's
+// closed.
+type flateWriter struct {
+	*flate.Writer
+	pool *sync.Pool
+}
+
+func (w *flateWriter) Close() error {
+	err := w.Writer.Close()
+	w.Reset(nil)
+	w.pool.Put(w)
+	return err
+}
+
+// deflaters are zip.Compressors for each level that draw on flatePools.
+var deflaters [len(flatePools)]zip.Compressor
+
+func init() {
+	for i := range deflaters {
+		pool, level := &flatePools[i], i+flate.HuffmanOnly
+		deflaters[i] = func(w io.Writer) (io.WriteCloser, error) {
+			fw, _ := pool.Get().(*flateWriter)
+			if fw == nil {
+				f, err := flate.NewWriter(w, level)
+				if err != nil {
+					return nil, err
+				}
+				return &flateWriter{Writer: f, pool: pool}, nil
+			}
+			fw.Reset(w)
+			return fw, nil
+		}
+	}
+}
+
+var zipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return &ZipWriter{Writer: new(zip.Writer)}
+	},
+}
+
+// ZipWriter is a zip.Writer whose buffered writer and deflate compressors
+// are pooled.
+type ZipWriter struct {
+	*zip.Writer
+	bw *bufio.Writer
+}
+
+// GetZipWriter returns a pooled ZipWriter writing to w, which deflates
+// files with pooled compressors at the given level. Return it with
+// PutZipWriter.
+//
+// GetZipWriter panics if level is not a valid flate compression level.
+func GetZipWriter(w io.Writer, level int) *ZipWriter {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		panic(fmt.Sprintf("pools: invalid flate level %d", level))
+	}
+	zw := zipWriterPool.Get().(*ZipWriter)
+	// zip.Writer has no Reset, so overwrite the pooled one. zip.NewWriter
+	// uses a *bufio.Writer it's given as is.
+	zw.bw = GetBufioWriter(w)
+	*zw.Writer = *zip.NewWriter(zw.bw)
+	zw.RegisterCompressor(zip.Deflate, deflaters[level-flate.HuffmanOnly])
+	return zw
+}
+
+// PutZipWriter returns zw and its buffered writer to their pools. It doesn't
+// Close zw, which must be done first to write the archive's central
+// directory.
+func PutZipWriter(zw *ZipWriter) {
+	PutBufioWriter(zw.bw)
+	zw.bw = nil
+	*zw.Writer = zip.Writer{}
+	zipWriterPool.Put(zw)
+}
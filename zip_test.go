@@ -0,0 +1,46 @@
+package pools
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestZipWriter(t *testing.T) {
+	body := strings.Repeat("hello, world! ", 100)
+	for i := 0; i < 2; i++ {
+		b := GetBuffer()
+		zw := GetZipWriter(b, flate.BestSpeed)
+		for _, name := range []string{"a.txt", "b.txt"} {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.WriteString(w, name+body)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		PutZipWriter(zw)
+
+		zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect(t, 2, len(zr.File))
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, _ := io.ReadAll(rc)
+			rc.Close()
+			expect(t, f.Name+body, string(p))
+			expect(t, zip.Deflate, f.Method)
+		}
+		PutBuffer(b)
+	}
+}